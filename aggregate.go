@@ -0,0 +1,84 @@
+package twchart
+
+import (
+	"math"
+	"time"
+)
+
+// Bucket summarizes a probe's readings during a period of time
+type Bucket struct {
+	// Time is the start of the Bucket
+	Time  time.Time
+	Min   float64
+	Max   float64
+	Avg   float64
+	Count int
+}
+
+// Aggregator groups a probe's readings into Buckets. Readings are added one at a time, in order, so data can be
+// aggregated while it is streamed
+type Aggregator struct {
+	// Step is the length of each Bucket. Buckets start at multiples of Step. If it is 0, each reading is its own
+	// Bucket
+	Step time.Duration
+
+	current Bucket
+	sum     float64
+}
+
+// Add adds a reading. When the reading is after the current Bucket, the finished Bucket is returned
+func (a *Aggregator) Add(t time.Time, value float64) (Bucket, bool) {
+	start := t
+	if a.Step > 0 {
+		start = t.Truncate(a.Step)
+	}
+
+	var finished Bucket
+	var ok bool
+	if a.current.Count > 0 && !start.Equal(a.current.Time) {
+		finished, ok = a.Flush()
+	}
+
+	if a.current.Count == 0 {
+		a.current = Bucket{Time: start, Min: math.Inf(1), Max: math.Inf(-1)}
+		a.sum = 0
+	}
+	a.current.Count++
+	a.current.Min = math.Min(a.current.Min, value)
+	a.current.Max = math.Max(a.current.Max, value)
+	a.sum += value
+
+	return finished, ok
+}
+
+// Flush returns the current Bucket, if it has any readings, and starts a new one
+func (a *Aggregator) Flush() (Bucket, bool) {
+	if a.current.Count == 0 {
+		return Bucket{}, false
+	}
+
+	result := a.current
+	result.Avg = a.sum / float64(result.Count)
+	a.current = Bucket{}
+	return result, true
+}
+
+// Aggregate groups the probe's valid readings into Buckets
+func Aggregate(data []ThermoworksData, pos ProbePosition, step time.Duration) []Bucket {
+	aggregator := Aggregator{Step: step}
+
+	result := []Bucket{}
+	for _, d := range data {
+		if !d.HasProbeData(pos) {
+			continue
+		}
+		if bucket, ok := aggregator.Add(d.Time, d.GetProbeData(pos)); ok {
+			result = append(result, bucket)
+		}
+	}
+	if bucket, ok := aggregator.Flush(); ok {
+		result = append(result, bucket)
+	}
+
+	return result
+}
@@ -0,0 +1,39 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+	data := []ThermoworksData{}
+	for i := range 12 {
+		data = append(data, ThermoworksData{
+			Time:      start.Add(time.Duration(i*15) * time.Second),
+			ProbeData: []float64{100 + float64(i), missingReading},
+		})
+	}
+	// a missing reading is skipped
+	data[5].ProbeData[0] = missingReading
+
+	t.Run("Step", func(t *testing.T) {
+		assert.Equal(t, []Bucket{
+			{Time: start, Min: 100, Max: 103, Avg: 101.5, Count: 4},
+			{Time: start.Add(time.Minute), Min: 104, Max: 107, Avg: 317.0 / 3, Count: 3},
+			{Time: start.Add(2 * time.Minute), Min: 108, Max: 111, Avg: 109.5, Count: 4},
+		}, Aggregate(data, ProbePosition1, time.Minute))
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		buckets := Aggregate(data, ProbePosition1, 0)
+		assert.Len(t, buckets, 11)
+		assert.Equal(t, Bucket{Time: data[1].Time, Min: 101, Max: 101, Avg: 101, Count: 1}, buckets[1])
+	})
+
+	t.Run("NoReadings", func(t *testing.T) {
+		assert.Empty(t, Aggregate(data, ProbePosition2, time.Minute))
+	})
+}
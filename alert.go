@@ -0,0 +1,307 @@
+package twchart
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertCondition is the kind of check made by an AlertRule
+type AlertCondition string
+
+const (
+	// AlertAbove triggers when a probe is above the Threshold
+	AlertAbove AlertCondition = "above"
+	// AlertAtOrAbove triggers when a probe is at or above the Threshold
+	AlertAtOrAbove AlertCondition = "at_or_above"
+	// AlertBelow triggers when a probe is below the Threshold
+	AlertBelow AlertCondition = "below"
+	// AlertAtOrBelow triggers when a probe is at or below the Threshold
+	AlertAtOrBelow AlertCondition = "at_or_below"
+	// AlertDisconnected triggers when a probe that had readings stops reporting them
+	AlertDisconnected AlertCondition = "disconnected"
+	// AlertStageOvertime triggers when a stage in progress runs longer than planned
+	AlertStageOvertime AlertCondition = "stage_overtime"
+)
+
+const defaultDisconnectedAfter = 2 * time.Minute
+
+// alertOperators are the comparison operators for each threshold AlertCondition. The first is used to write the rule
+var alertOperators = map[AlertCondition][]string{
+	AlertAbove:     {">"},
+	AlertAtOrAbove: {">=", "≥"},
+	AlertBelow:     {"<"},
+	AlertAtOrBelow: {"<=", "≤"},
+}
+
+var (
+	alertThresholdRE    = regexp.MustCompile(`(?i)^(.+?)\s*(>=|<=|≥|≤|>|<)\s*(-?\d+(?:\.\d+)?)(?:\s+for\s+(\S+))?$`)
+	alertDisconnectedRE = regexp.MustCompile(`(?i)^(.+?)\s+disconnected(?:\s+for\s+(\S+))?$`)
+	alertStageRE        = regexp.MustCompile(`(?i)^stage(?:\s+(.+?))?\s+over\s+(\S+)$`)
+)
+
+// AlertRule describes a condition in a Session's data that someone should be notified about. Rules are written as
+// text, which is also how they are stored in JSON:
+//   - "Pork >= 203" or "Pork ≥ 203": a probe reaches a temperature. ">", "<", "<=", and "≤" also work
+//   - "Pit < 225 for 10m": a probe stays below a temperature for a duration
+//   - "Pork disconnected": a probe has no readings for 2m, or the duration after "for"
+//   - "stage Cook over 4h": a stage runs longer than planned. Leave out the name to check every stage
+type AlertRule struct {
+	Condition AlertCondition
+	// Probe is the name of the probe to check. It is not used for AlertStageOvertime
+	Probe string
+	// Threshold is the temperature for AlertAbove, AlertAtOrAbove, AlertBelow, and AlertAtOrBelow
+	Threshold float64
+	// For is how long the condition must hold before the alert is triggered
+	For time.Duration
+	// Stage and Duration are the name and planned duration for AlertStageOvertime. An empty Stage matches all stages
+	Stage    string
+	Duration time.Duration
+}
+
+// ParseAlertRule parses an AlertRule from its text format
+func ParseAlertRule(text string) (AlertRule, error) {
+	text = strings.TrimSpace(text)
+
+	var rule AlertRule
+	var forStr string
+	var err error
+	if match := alertStageRE.FindStringSubmatch(text); match != nil {
+		rule.Condition = AlertStageOvertime
+		rule.Stage = match[1]
+		rule.Duration, err = time.ParseDuration(match[2])
+		if err != nil {
+			return AlertRule{}, fmt.Errorf("error parsing stage duration %q: %w", match[2], err)
+		}
+	} else if match := alertDisconnectedRE.FindStringSubmatch(text); match != nil {
+		rule.Condition = AlertDisconnected
+		rule.Probe = match[1]
+		forStr = match[2]
+	} else if match := alertThresholdRE.FindStringSubmatch(text); match != nil {
+		for condition, operators := range alertOperators {
+			if slices.Contains(operators, match[2]) {
+				rule.Condition = condition
+			}
+		}
+		rule.Probe = match[1]
+		rule.Threshold, err = strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return AlertRule{}, fmt.Errorf("error parsing threshold %q: %w", match[3], err)
+		}
+		forStr = match[4]
+	} else {
+		return AlertRule{}, fmt.Errorf("invalid alert rule: %q", text)
+	}
+
+	if forStr != "" {
+		rule.For, err = time.ParseDuration(forStr)
+		if err != nil {
+			return AlertRule{}, fmt.Errorf("error parsing alert duration %q: %w", forStr, err)
+		}
+	}
+
+	return rule, rule.Validate()
+}
+
+// Validate checks that the rule has everything needed for its Condition
+func (r AlertRule) Validate() error {
+	switch r.Condition {
+	case AlertAbove, AlertAtOrAbove, AlertBelow, AlertAtOrBelow, AlertDisconnected:
+		if r.Probe == "" {
+			return fmt.Errorf("missing probe for %s alert", r.Condition)
+		}
+	case AlertStageOvertime:
+		if r.Duration <= 0 {
+			return fmt.Errorf("stage alert duration must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid alert condition: %q", r.Condition)
+	}
+
+	if r.For < 0 {
+		return fmt.Errorf("alert duration must not be negative")
+	}
+	return nil
+}
+
+func (r AlertRule) String() string {
+	var result string
+	switch r.Condition {
+	case AlertAbove, AlertAtOrAbove, AlertBelow, AlertAtOrBelow:
+		result = fmt.Sprintf("%s %s %s", r.Probe, alertOperators[r.Condition][0], strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+	case AlertDisconnected:
+		result = fmt.Sprintf("%s disconnected", r.Probe)
+	case AlertStageOvertime:
+		if r.Stage == "" {
+			return fmt.Sprintf("stage over %s", shortDuration(r.Duration))
+		}
+		return fmt.Sprintf("stage %s over %s", r.Stage, shortDuration(r.Duration))
+	}
+
+	if r.For > 0 {
+		result += " for " + shortDuration(r.For)
+	}
+	return result
+}
+
+func (r AlertRule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *AlertRule) UnmarshalText(text []byte) error {
+	rule, err := ParseAlertRule(string(text))
+	if err != nil {
+		return err
+	}
+	*r = rule
+	return nil
+}
+
+func (r AlertRule) AddToSession(s *Session) {
+	s.Alerts = append(s.Alerts, r)
+}
+
+// shortDuration formats durations without trailing zero units, like 10m instead of 10m0s
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// Alert is an AlertRule that is triggered by the Session's data
+type Alert struct {
+	Rule AlertRule
+	// Since is when the condition started
+	Since   time.Time
+	Message string
+}
+
+// CheckAlerts returns the rules that are triggered by the Session's most recent data. The time of the last reading
+// is used as the current time so uploaded data is checked the same way as live readings. Rules for probes that
+// don't exist are ignored
+func (s Session) CheckAlerts(rules []AlertRule) []Alert {
+	data := s.CleanData()
+	if len(data) == 0 {
+		return nil
+	}
+	now := data[len(data)-1].Time
+
+	alerts := []Alert{}
+	for _, rule := range rules {
+		switch rule.Condition {
+		case AlertAbove, AlertAtOrAbove, AlertBelow, AlertAtOrBelow:
+			if alert, ok := s.checkThreshold(data, rule); ok {
+				alerts = append(alerts, alert)
+			}
+		case AlertDisconnected:
+			if alert, ok := s.checkDisconnected(data, rule, now); ok {
+				alerts = append(alerts, alert)
+			}
+		case AlertStageOvertime:
+			alerts = append(alerts, s.checkStageOvertime(rule, now)...)
+		}
+	}
+
+	return alerts
+}
+
+// checkThreshold finds how long the probe's latest readings have been past the threshold
+func (s Session) checkThreshold(data []ThermoworksData, rule AlertRule) (Alert, bool) {
+	probe, err := s.FindProbe(rule.Probe)
+	if err != nil {
+		return Alert{}, false
+	}
+
+	readings := validPoints(ProbeSeries(data, probe.Position))
+	if len(readings) == 0 {
+		return Alert{}, false
+	}
+
+	matches := func(value float64) bool {
+		switch rule.Condition {
+		case AlertAbove:
+			return value > rule.Threshold
+		case AlertAtOrAbove:
+			return value >= rule.Threshold
+		case AlertAtOrBelow:
+			return value <= rule.Threshold
+		}
+		return value < rule.Threshold
+	}
+
+	last := readings[len(readings)-1]
+	start := -1
+	for i := len(readings) - 1; i >= 0 && matches(readings[i].Value); i-- {
+		start = i
+	}
+	if start == -1 || last.Time.Sub(readings[start].Time) < rule.For {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Rule:    rule,
+		Since:   readings[start].Time,
+		Message: fmt.Sprintf("%s is %.1f (%s)", probe.Name, last.Value, rule),
+	}, true
+}
+
+// checkDisconnected checks the time since the probe's last reading. Probes that never had a reading are ignored
+func (s Session) checkDisconnected(data []ThermoworksData, rule AlertRule, now time.Time) (Alert, bool) {
+	probe, err := s.FindProbe(rule.Probe)
+	if err != nil {
+		return Alert{}, false
+	}
+
+	readings := validPoints(ProbeSeries(data, probe.Position))
+	if len(readings) == 0 {
+		return Alert{}, false
+	}
+
+	wait := rule.For
+	if wait <= 0 {
+		wait = defaultDisconnectedAfter
+	}
+
+	last := readings[len(readings)-1]
+	missing := now.Sub(last.Time)
+	if missing < wait {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Rule:    rule,
+		Since:   last.Time,
+		Message: fmt.Sprintf("%s has had no readings for %s", probe.Name, shortDuration(missing.Round(time.Second))),
+	}, true
+}
+
+// checkStageOvertime checks stages that are still in progress
+func (s Session) checkStageOvertime(rule AlertRule, now time.Time) []Alert {
+	var alerts []Alert
+	for _, stage := range s.Stages {
+		if !stage.End.IsZero() || (rule.Stage != "" && !strings.EqualFold(stage.Name, rule.Stage)) {
+			continue
+		}
+
+		elapsed := now.Sub(stage.Start)
+		if elapsed <= rule.Duration {
+			continue
+		}
+
+		alerts = append(alerts, Alert{
+			Rule:  rule,
+			Since: stage.Start.Add(rule.Duration),
+			Message: fmt.Sprintf("%s stage has taken %s, planned %s",
+				stage.Name, shortDuration(elapsed.Round(time.Minute)), shortDuration(rule.Duration)),
+		})
+	}
+	return alerts
+}
@@ -0,0 +1,197 @@
+package twchart
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    AlertRule
+		text        string
+		expectedErr string
+	}{
+		{"Brisket >= 203", AlertRule{Condition: AlertAtOrAbove, Probe: "Brisket", Threshold: 203}, "Brisket >= 203", ""},
+		{"Pork Butt > 195.5", AlertRule{Condition: AlertAbove, Probe: "Pork Butt", Threshold: 195.5}, "Pork Butt > 195.5", ""},
+		{"Meat probe ≥ 203", AlertRule{Condition: AlertAtOrAbove, Probe: "Meat probe", Threshold: 203}, "Meat probe >= 203", ""},
+		{"Pit ≤ 225", AlertRule{Condition: AlertAtOrBelow, Probe: "Pit", Threshold: 225}, "Pit <= 225", ""},
+		{"Pit <= 225 for 5m", AlertRule{Condition: AlertAtOrBelow, Probe: "Pit", Threshold: 225, For: 5 * time.Minute}, "Pit <= 225 for 5m", ""},
+		{"Pit<225 for 10m", AlertRule{Condition: AlertBelow, Probe: "Pit", Threshold: 225, For: 10 * time.Minute}, "Pit < 225 for 10m", ""},
+		{"Brisket disconnected", AlertRule{Condition: AlertDisconnected, Probe: "Brisket"}, "Brisket disconnected", ""},
+		{"Pit disconnected for 5m", AlertRule{Condition: AlertDisconnected, Probe: "Pit", For: 5 * time.Minute}, "Pit disconnected for 5m", ""},
+		{"stage Cook over 4h", AlertRule{Condition: AlertStageOvertime, Stage: "Cook", Duration: 4 * time.Hour}, "stage Cook over 4h", ""},
+		{"stage over 1h30m", AlertRule{Condition: AlertStageOvertime, Duration: 90 * time.Minute}, "stage over 1h30m", ""},
+		{"Pit < 225 for soon", AlertRule{}, "", `error parsing alert duration "soon": time: invalid duration "soon"`},
+		{"stage Cook over 0s", AlertRule{}, "", "stage alert duration must be positive"},
+		{"Pit is hot", AlertRule{}, "", `invalid alert rule: "Pit is hot"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := ParseAlertRule(tt.input)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rule)
+			assert.Equal(t, tt.text, rule.String())
+		})
+	}
+}
+
+func TestAlertRuleJSON(t *testing.T) {
+	rules := []AlertRule{
+		{Condition: AlertAtOrAbove, Probe: "Brisket", Threshold: 203},
+		{Condition: AlertAbove, Probe: "Brisket", Threshold: 203},
+		{Condition: AlertAtOrBelow, Probe: "Pit", Threshold: 225},
+		{Condition: AlertBelow, Probe: "Pit", Threshold: 225},
+		{Condition: AlertStageOvertime, Stage: "Rest", Duration: time.Hour},
+	}
+
+	data, err := json.Marshal(rules)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["Brisket >= 203", "Brisket > 203", "Pit <= 225", "Pit < 225", "stage Rest over 1h"]`, string(data))
+
+	var decoded []AlertRule
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, rules, decoded)
+
+	err = json.Unmarshal([]byte(`["Brisket is done"]`), &decoded)
+	assert.EqualError(t, err, `invalid alert rule: "Brisket is done"`)
+}
+
+func testAlertSession() Session {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+	s := Session{
+		Probes: []Probe{
+			{Name: "Pit", Position: ProbePosition1},
+			{Name: "Brisket", Position: ProbePosition2},
+		},
+		Stages: []Stage{{Name: "Cook", Start: start}},
+	}
+
+	// the pit drops below 225 after 1 hour and the brisket rises 1 degree per minute until it is disconnected at
+	// 1h50m
+	for i := range 120 {
+		pit := 250.0
+		if i >= 60 {
+			pit = 215
+		}
+		brisket := 100 + float64(i)
+		if i >= 110 {
+			brisket = missingReading
+		}
+		s.Data = append(s.Data, ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Minute),
+			ProbeData: []float64{pit, brisket},
+		})
+	}
+
+	return s
+}
+
+func TestCheckAlerts(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		rule     string
+		expected []Alert
+	}{
+		{
+			"Below",
+			"Pit < 225 for 10m",
+			[]Alert{{Since: start.Add(time.Hour), Message: "Pit is 215.0 (Pit < 225 for 10m)"}},
+		},
+		{
+			"BelowNotLongEnough",
+			"Pit < 225 for 2h",
+			[]Alert{},
+		},
+		{
+			"AboveWithMissingReadings",
+			"Brisket >= 200",
+			[]Alert{{Since: start.Add(100 * time.Minute), Message: "Brisket is 209.0 (Brisket >= 200)"}},
+		},
+		{
+			"AboveNotReached",
+			"Brisket >= 210",
+			[]Alert{},
+		},
+		{
+			"AtOrAboveAtThreshold",
+			"Brisket ≥ 209",
+			[]Alert{{Since: start.Add(109 * time.Minute), Message: "Brisket is 209.0 (Brisket >= 209)"}},
+		},
+		{
+			"AboveAtThreshold",
+			"Brisket > 209",
+			[]Alert{},
+		},
+		{
+			"AtOrBelowAtThreshold",
+			"Pit ≤ 215",
+			[]Alert{{Since: start.Add(time.Hour), Message: "Pit is 215.0 (Pit <= 215)"}},
+		},
+		{
+			"BelowAtThreshold",
+			"Pit < 215",
+			[]Alert{},
+		},
+		{
+			"Disconnected",
+			"Brisket disconnected",
+			[]Alert{{Since: start.Add(109 * time.Minute), Message: "Brisket has had no readings for 10m"}},
+		},
+		{
+			"NotDisconnectedYet",
+			"Brisket disconnected for 15m",
+			[]Alert{},
+		},
+		{
+			"StageOvertime",
+			"stage cook over 90m",
+			[]Alert{{Since: start.Add(90 * time.Minute), Message: "Cook stage has taken 1h59m, planned 1h30m"}},
+		},
+		{
+			"StageNotOvertime",
+			"stage over 3h",
+			[]Alert{},
+		},
+		{
+			"UnknownProbe",
+			"Pork >= 200",
+			[]Alert{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseAlertRule(tt.rule)
+			require.NoError(t, err)
+
+			for i := range tt.expected {
+				tt.expected[i].Rule = rule
+			}
+
+			assert.Equal(t, tt.expected, testAlertSession().CheckAlerts([]AlertRule{rule}))
+		})
+	}
+
+	t.Run("FinishedStage", func(t *testing.T) {
+		s := testAlertSession()
+		s.Stages[0].Finish(start.Add(time.Hour))
+
+		assert.Empty(t, s.CheckAlerts([]AlertRule{{Condition: AlertStageOvertime, Duration: time.Minute}}))
+	})
+
+	t.Run("NoData", func(t *testing.T) {
+		assert.Empty(t, Session{}.CheckAlerts([]AlertRule{{Condition: AlertStageOvertime, Duration: time.Minute}}))
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/calvinmclean/twchart/notify"
+	"github.com/go-chi/render"
+)
+
+// alertCheckTimeout limits how long checking a Session's alerts and sending its notifications can take
+const alertCheckTimeout = 30 * time.Second
+
+// alertTracker remembers the active alerts for each Session so a notification is only sent when an alert starts. It
+// also runs the checks in the background so notifications don't delay the request that added the data
+type alertTracker struct {
+	mu     sync.Mutex
+	active map[string]map[string]struct{}
+	// pending has the next Session to check for each Session with a running check, or nil if there isn't one yet
+	pending map[string]*twchart.Session
+	wg      sync.WaitGroup
+}
+
+func newAlertTracker() *alertTracker {
+	return &alertTracker{
+		active:  map[string]map[string]struct{}{},
+		pending: map[string]*twchart.Session{},
+	}
+}
+
+// check calls checkFn with the Session in the background. Only one check runs at a time for each Session so
+// notifications are sent in order, and a Session queued while a check is running replaces any older one that is
+// still waiting
+func (t *alertTracker) check(session twchart.Session, checkFn func(twchart.Session)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := session.GetID()
+	_, running := t.pending[id]
+	t.pending[id] = &session
+	if running {
+		return
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			t.mu.Lock()
+			next := t.pending[id]
+			if next == nil {
+				delete(t.pending, id)
+				t.mu.Unlock()
+				return
+			}
+			t.pending[id] = nil
+			t.mu.Unlock()
+
+			checkFn(*next)
+		}
+	}()
+}
+
+// wait blocks until all running checks are done
+func (t *alertTracker) wait() {
+	t.wg.Wait()
+}
+
+// update replaces the Session's active alerts and returns the ones that weren't already active
+func (t *alertTracker) update(id string, alerts []twchart.Alert) []twchart.Alert {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous := t.active[id]
+	current := map[string]struct{}{}
+
+	var started []twchart.Alert
+	for _, alert := range alerts {
+		key := alertKey(alert)
+		current[key] = struct{}{}
+		if _, ok := previous[key]; !ok {
+			started = append(started, alert)
+		}
+	}
+
+	t.active[id] = current
+	return started
+}
+
+// alertKey identifies an alert. The start time is included so an alert that clears and starts again is sent again
+func alertKey(alert twchart.Alert) string {
+	return fmt.Sprintf("%s@%s", alert.Rule, alert.Since.Format(time.RFC3339Nano))
+}
+
+// LoadAlertTemplates reads default AlertRules for each SessionType from a JSON file like:
+//
+//	{"bbq": ["Pit < 225 for 10m", "Pit disconnected"]}
+func (a *API) LoadAlertTemplates(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading alert templates: %w", err)
+	}
+
+	var templates map[twchart.SessionType][]twchart.AlertRule
+	err = json.Unmarshal(data, &templates)
+	if err != nil {
+		return fmt.Errorf("error parsing alert templates: %w", err)
+	}
+
+	a.AlertTemplates = templates
+	return nil
+}
+
+// alertRules returns the Session's own AlertRules and the template rules for its type
+func (a *API) alertRules(session twchart.Session) []twchart.AlertRule {
+	rules := append([]twchart.AlertRule{}, session.Alerts...)
+	return append(rules, a.AlertTemplates[session.Type]...)
+}
+
+type alertsResponse struct {
+	*babyapi.DefaultRenderer
+	Rules  []twchart.AlertRule
+	Alerts []twchart.Alert
+}
+
+// sessionAlerts responds with the Session's AlertRules and the alerts that are currently triggered
+func (a *API) sessionAlerts(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	rules := a.alertRules(sr.Session)
+	return alertsResponse{Rules: rules, Alerts: sr.Session.CheckAlerts(rules)}, nil
+}
+
+// checkAlerts sends notifications for alerts that start after new data is added to the Session. The Session is
+// checked in the background, so the request isn't delayed by reading the data or sending the notifications
+func (a *API) checkAlerts(r *http.Request, sr *SessionResource) {
+	if a.Notifier == nil || len(a.alertRules(sr.Session)) == 0 {
+		return
+	}
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+	a.alerts.check(sr.Session, func(session twchart.Session) {
+		ctx, cancel := context.WithTimeout(context.Background(), alertCheckTimeout)
+		defer cancel()
+
+		a.sendAlerts(ctx, logger, session)
+	})
+}
+
+// sendAlerts checks the Session's rules against all of its data and sends the alerts that started
+func (a *API) sendAlerts(ctx context.Context, logger *slog.Logger, session twchart.Session) {
+	// The handler only has the new rows when data is stored in the DB, so all of it is read here
+	if a.storageAdapter.Client != nil {
+		session.Data = nil
+	}
+	data, httpErr := a.thermoworksData(ctx, &SessionResource{Session: session})
+	if httpErr != nil {
+		logger.Error("error loading data for alerts", "error", httpErr.Error())
+		return
+	}
+	session.Data = data
+
+	id := session.GetID()
+	for _, alert := range a.alerts.update(id, session.CheckAlerts(a.alertRules(session))) {
+		msg := notify.Message{Title: session.Name, Body: alert.Message}
+		if a.BaseURL != "" {
+			msg.URL = fmt.Sprintf("%s/sessions/%s", strings.TrimSuffix(a.BaseURL, "/"), id)
+		}
+
+		err := a.Notifier.Notify(ctx, msg)
+		if err != nil {
+			logger.Error("error sending alert", "alert", alert.Rule.String(), "error", err)
+		}
+	}
+}
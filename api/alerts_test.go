@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/calvinmclean/twchart/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertTracker(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+	pit := twchart.Alert{
+		Rule:  twchart.AlertRule{Condition: twchart.AlertBelow, Probe: "Pit", Threshold: 225},
+		Since: start,
+	}
+	brisket := twchart.Alert{
+		Rule:  twchart.AlertRule{Condition: twchart.AlertAbove, Probe: "Brisket", Threshold: 203},
+		Since: start.Add(time.Hour),
+	}
+
+	tracker := newAlertTracker()
+	assert.Equal(t, []twchart.Alert{pit}, tracker.update("a", []twchart.Alert{pit}))
+
+	// alerts that are still active aren't sent again
+	assert.Equal(t, []twchart.Alert{brisket}, tracker.update("a", []twchart.Alert{pit, brisket}))
+	assert.Empty(t, tracker.update("a", []twchart.Alert{pit, brisket}))
+
+	// each Session is tracked separately
+	assert.Equal(t, []twchart.Alert{pit}, tracker.update("b", []twchart.Alert{pit}))
+
+	// an alert that clears and starts again is sent again
+	assert.Empty(t, tracker.update("a", []twchart.Alert{brisket}))
+	pit.Since = start.Add(2 * time.Hour)
+	assert.Equal(t, []twchart.Alert{pit}, tracker.update("a", []twchart.Alert{pit, brisket}))
+}
+
+func TestLoadAlertTemplates(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "alerts.json")
+	err := os.WriteFile(filename, []byte(`{"bbq": ["Pit < 225 for 10m", "Pit disconnected"]}`), 0o644)
+	require.NoError(t, err)
+
+	api := New()
+	require.NoError(t, api.LoadAlertTemplates(filename))
+
+	session := twchart.Session{
+		Type:   twchart.SessionTypeBBQ,
+		Alerts: []twchart.AlertRule{{Condition: twchart.AlertAbove, Probe: "Brisket", Threshold: 203}},
+	}
+	assert.Equal(t, []twchart.AlertRule{
+		{Condition: twchart.AlertAbove, Probe: "Brisket", Threshold: 203},
+		{Condition: twchart.AlertBelow, Probe: "Pit", Threshold: 225, For: 10 * time.Minute},
+		{Condition: twchart.AlertDisconnected, Probe: "Pit"},
+	}, api.alertRules(session))
+
+	session.Type = twchart.SessionTypeBread
+	assert.Len(t, api.alertRules(session), 1)
+}
+
+// blockingNotifier records Messages, but doesn't return until it is released
+type blockingNotifier struct {
+	release  chan struct{}
+	messages chan notify.Message
+}
+
+func (n blockingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	select {
+	case <-n.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	n.messages <- msg
+	return nil
+}
+
+func TestCheckAlerts(t *testing.T) {
+	notifier := blockingNotifier{release: make(chan struct{}), messages: make(chan notify.Message, 10)}
+	api := New()
+	api.Notifier = notifier
+	api.BaseURL = "http://localhost:8080/"
+
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+	sr := &SessionResource{Session: twchart.Session{
+		ID:     babyapi.NewID(),
+		Name:   "Brisket",
+		Probes: []twchart.Probe{{Name: "Pit", Position: twchart.ProbePosition1}},
+		Alerts: []twchart.AlertRule{{Condition: twchart.AlertBelow, Probe: "Pit", Threshold: 225}},
+		Data:   []twchart.ThermoworksData{{Time: start, ProbeData: []float64{200}}},
+	}}
+
+	// the requests aren't blocked by the notifier, and the second check waits for the first
+	r := httptest.NewRequest("POST", "/", nil)
+	done := make(chan struct{})
+	go func() {
+		api.checkAlerts(r, sr)
+		api.checkAlerts(r, sr)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("checkAlerts waited for the notifier")
+	}
+
+	close(notifier.release)
+	api.alerts.wait()
+	close(notifier.messages)
+
+	var messages []notify.Message
+	for msg := range notifier.messages {
+		messages = append(messages, msg)
+	}
+	assert.Equal(t, []notify.Message{{
+		Title: "Brisket",
+		Body:  sr.Session.CheckAlerts(sr.Alerts)[0].Message,
+		URL:   "http://localhost:8080/sessions/" + sr.GetID(),
+	}}, messages)
+}
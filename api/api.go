@@ -15,12 +15,14 @@ import (
 	"time"
 
 	"github.com/calvinmclean/twchart"
+	"github.com/calvinmclean/twchart/notify"
 	"github.com/calvinmclean/twchart/storage"
 	"github.com/rs/xid"
 
 	"github.com/calvinmclean/babyapi"
 	"github.com/calvinmclean/babyapi/extensions"
 	"github.com/go-chi/render"
+	"github.com/go-echarts/go-echarts/v2/opts"
 )
 
 const defaultPageSize = 10
@@ -145,7 +147,16 @@ func (s SessionResource) ParentID() string {
 var _ babyapi.HTMLer = &SessionResource{}
 
 func (s SessionResource) HTML(w http.ResponseWriter, r *http.Request) string {
-	return sessionDetail.Render(r, s)
+	// Data is needed for stage details, but it isn't loaded with the Session from the DB
+	if api := getAPIFromContext(r.Context()); api != nil {
+		httpErr := api.loadThermoworksData(r.Context(), &s)
+		if httpErr != nil {
+			logger, _ := babyapi.GetLoggerFromContext(r.Context())
+			logger.Error("error loading data", "error", httpErr.Error())
+		}
+	}
+
+	return sessionDetail.Render(r, newSessionDetailData(s))
 }
 
 func (s *SessionResource) Bind(r *http.Request) error {
@@ -157,6 +168,18 @@ func (s *SessionResource) Bind(r *http.Request) error {
 	if err != nil {
 		return err
 	}
+
+	err = s.Session.Cleaning.Validate()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range s.Session.Alerts {
+		err = rule.Validate()
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -172,10 +195,23 @@ func (s *SessionResource) Patch(newSession *SessionResource) *babyapi.ErrRespons
 type API struct {
 	*babyapi.API[*SessionResource]
 
-	// this currently won't work correctly for multiple users viewing the same session, but that's fine
-	sseChans map[string]chan *babyapi.ServerSentEvent
+	sseListeners *sseListeners
 
 	storageAdapter storageAdapter
+
+	// Notifier sends alerts when new data triggers an AlertRule. Alerts are not checked if it is nil
+	Notifier notify.Notifier
+	// AlertTemplates are AlertRules that are checked for every Session of a type, in addition to its own Alerts
+	AlertTemplates map[twchart.SessionType][]twchart.AlertRule
+	// Themes configure the chart colors for each SessionType
+	Themes twchart.Themes
+	// BaseURL is the server's external URL, which is used to link to Sessions from notifications
+	BaseURL string
+
+	alerts *alertTracker
+
+	// staticAssets serves embedded CSS and JavaScript. Pages use CDNs if it is nil
+	staticAssets *staticAssets
 }
 
 // NewSessionResource creates a sessionResource from a Session
@@ -187,11 +223,15 @@ func NewSessionResource(session twchart.Session) *SessionResource {
 
 func New() *API {
 	api := &API{
-		sseChans: map[string]chan *babyapi.ServerSentEvent{},
+		sseListeners: newSSEListeners(),
+		alerts:       newAlertTracker(),
 	}
 	api.API = babyapi.NewAPI("Sessions", "/sessions", func() *SessionResource { return &SessionResource{} })
 	api.API.AddCustomRootRoute(http.MethodGet, "/", http.RedirectHandler("/sessions", http.StatusFound))
+	api.API.AddCustomRootRoute(http.MethodGet, staticPrefix+"*", http.HandlerFunc(api.serveStatic))
+	api.API.AddCustomRootRoute(http.MethodGet, "/timeline", api.apiContextMiddleware(babyapi.Handler(api.sessionsTimeline)))
 	api.API.AddCustomRoute(http.MethodPost, "/upload-csv", babyapi.Handler(api.loadCSVToLatestSession))
+	api.API.AddCustomRoute(http.MethodGet, "/compare", babyapi.Handler(api.compareSessions))
 	api.API.AddCustomIDRoute(http.MethodPost, "/upload-csv", api.GetRequestedResourceAndDo(api.loadCSVToSession))
 
 	// Add pagination middleware to the search route
@@ -211,10 +251,30 @@ func New() *API {
 		}
 	})
 	api.API.AddCustomIDRoute(http.MethodGet, "/chart", api.GetRequestedResourceAndDo(api.renderChart))
+	api.API.AddCustomIDRoute(http.MethodGet, "/chart-data", api.GetRequestedResourceAndDo(api.chartData))
+	api.API.AddCustomIDRoute(http.MethodGet, "/replay", api.GetRequestedResourceAndDo(api.replay))
+	api.API.AddCustomIDRoute(http.MethodGet, "/timeline", api.GetRequestedResourceAndDo(api.sessionTimeline))
+	api.API.AddCustomIDRoute(http.MethodGet, "/data", babyapi.Handler(api.sessionData))
+	api.API.AddCustomIDRoute(http.MethodGet, "/chart.svg", api.chartImage("image/svg+xml", twchart.Session.WriteSVG))
+	api.API.AddCustomIDRoute(http.MethodGet, "/chart.png", api.chartImage("image/png", twchart.Session.WritePNG))
+	api.API.AddCustomIDRoute(http.MethodGet, "/report", api.GetRequestedResourceAndDo(api.sessionReport))
+	api.API.AddCustomIDRoute(http.MethodGet, "/report.pdf", babyapi.Handler(api.sessionReportPDF))
 	api.API.AddCustomIDRoute(http.MethodPost, "/add-event", api.GetRequestedResourceAndDo(sessionPartHandler[twchart.Event](api)))
+	api.API.AddCustomIDRoute(http.MethodPost, "/move-event", api.GetRequestedResourceAndDo(api.moveEvent))
 	api.API.AddCustomIDRoute(http.MethodPost, "/add-stage", api.GetRequestedResourceAndDo(sessionPartHandler[twchart.Stage](api)))
 	api.API.AddCustomIDRoute(http.MethodPost, "/done", api.GetRequestedResourceAndDo(sessionPartHandler[twchart.DoneTime](api)))
 	api.API.AddCustomIDRoute(http.MethodGet, "/updates", http.HandlerFunc(api.sseUpdateHandler))
+	api.API.AddCustomIDRoute(http.MethodGet, "/align", api.GetRequestedResourceAndDo(api.alignClock))
+	api.API.AddCustomIDRoute(http.MethodPost, "/shift", api.GetRequestedResourceAndDo(api.shiftSession))
+	api.API.AddCustomIDRoute(http.MethodGet, "/stats", api.GetRequestedResourceAndDo(api.sessionStats))
+	api.API.AddCustomIDRoute(http.MethodGet, "/bbq", api.GetRequestedResourceAndDo(api.bbqAnalysis))
+	api.API.AddCustomIDRoute(http.MethodGet, "/eta", api.GetRequestedResourceAndDo(api.eta))
+	api.API.AddCustomIDRoute(http.MethodPost, "/readings", api.GetRequestedResourceAndDo(api.addReadings))
+	api.API.AddCustomIDRoute(http.MethodGet, "/alerts", api.GetRequestedResourceAndDo(api.sessionAlerts))
+
+	api.SetResponseWrapper(func(sr *SessionResource) render.Renderer {
+		return &sessionResponse{SessionResource: sr}
+	})
 
 	// Use custom text unmarshalling/decoding for Sessions
 	render.Decode = func(r *http.Request, v any) error {
@@ -241,32 +301,6 @@ func New() *API {
 	return api
 }
 
-func (a API) sseUpdateHandler(w http.ResponseWriter, r *http.Request) {
-	id := a.API.GetIDParam(r)
-
-	sseChan := make(chan *babyapi.ServerSentEvent)
-	a.sseChans[id] = sseChan
-	defer func() {
-		close(sseChan)
-		delete(a.sseChans, id)
-	}()
-
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Content-Type", "text/event-stream")
-
-	for {
-		select {
-		case e := <-sseChan:
-			e.Write(w)
-		case <-r.Context().Done():
-			return
-		case <-a.Done():
-			return
-		}
-	}
-}
-
 func sessionPartHandler[T twchart.SessionPart](a *API) func(http.ResponseWriter, *http.Request, *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
 	return func(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
 		var sessionPart T
@@ -274,6 +308,11 @@ func sessionPartHandler[T twchart.SessionPart](a *API) func(http.ResponseWriter,
 			return nil, babyapi.ErrInvalidRequest(fmt.Errorf("error parsing SessionPart: %w", err))
 		}
 
+		// Events added from the chart use UTC, but the rest of the Session is in local time
+		if e, ok := any(&sessionPart).(*twchart.Event); ok {
+			e.Time = e.Time.Local()
+		}
+
 		sessionPart.AddToSession(&sr.Session)
 
 		err := a.Storage.Set(r.Context(), sr)
@@ -281,48 +320,50 @@ func sessionPartHandler[T twchart.SessionPart](a *API) func(http.ResponseWriter,
 			return nil, babyapi.InternalServerError(err)
 		}
 
-		logger, _ := babyapi.GetLoggerFromContext(r.Context())
-
 		// use ServerSentEvents to provide live updates to the UI
-		sseChan, ok := a.sseChans[sr.GetID()]
-		if !ok {
-			logger.Info("no listeners for server-sent event")
-			return nil, nil
-		}
-
-		event := &babyapi.ServerSentEvent{}
 		switch part := any(sessionPart).(type) {
 		case twchart.Event:
-			event.Event = "newSessionEvent"
-			// Find previous event time for duration calculation
-			var prevEventTime time.Time
-			events := sr.Session.Events
-			// The new event is already added, so the previous event is the second-to-last
-			if len(events) > 1 {
-				prevEventTime = events[len(events)-2].Time
-			}
-			event.Data = eventRow.Render(r, map[string]any{
-				"Event":            part,
-				"PrevEventTime":    prevEventTime,
-				"SessionStartTime": sr.Session.StartTime,
-			})
+			a.publishEvents(r, sr)
 		case twchart.Stage:
-			event.Event = "newSessionStage"
-			event.Data = stageRow.Render(r, sessionPart)
+			_, showRateOfRise := rateOfRiseSeries(sr.Session)
+			a.publish(r, sr.GetID(), &babyapi.ServerSentEvent{
+				Event: "newSessionStage",
+				Data:  stageRow.Render(r, stageRowData{Stage: part, ShowRateOfRise: showRateOfRise}),
+			})
 		case twchart.DoneTime:
-			return nil, nil
 			// nothing to do here since we don't append a stage and instead mark the last as ended.
 			// not worth the effort to do right now
 		}
 
-		select {
-		case sseChan <- event:
-		default:
-			logger.Info("no listeners for server-sent event")
+		return nil, nil
+	}
+}
+
+// importReportResponse is the response for CSV uploads
+type importReportResponse struct {
+	*babyapi.DefaultRenderer
+	twchart.ImportReport
+}
+
+// importOptionsFromRequest reads CSV import options from the request's query parameters
+func importOptionsFromRequest(r *http.Request) (twchart.ImportOptions, error) {
+	var opts twchart.ImportOptions
+
+	if strictParam := r.URL.Query().Get("strict"); strictParam != "" {
+		strict, err := strconv.ParseBool(strictParam)
+		if err != nil {
+			return opts, fmt.Errorf("invalid strict parameter: %w", err)
 		}
+		opts.Strict = strict
+	}
 
-		return nil, nil
+	mode, err := twchart.ParseUploadMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		return opts, err
 	}
+	opts.Mode = mode
+
+	return opts, nil
 }
 
 func (a *API) loadCSVToLatestSession(w http.ResponseWriter, r *http.Request) render.Renderer {
@@ -331,6 +372,11 @@ func (a *API) loadCSVToLatestSession(w http.ResponseWriter, r *http.Request) ren
 		return babyapi.ErrInvalidRequest(fmt.Errorf("unexpected Content-Type: %s", contentType))
 	}
 
+	opts, err := importOptionsFromRequest(r)
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
 	useSQL := a.storageAdapter.Client != nil
 
 	var session *SessionResource
@@ -358,13 +404,31 @@ func (a *API) loadCSVToLatestSession(w http.ResponseWriter, r *http.Request) ren
 		}
 	}
 
-	err := a.uploadCSVData(r.Context(), session, r.Body)
+	report, err := a.uploadCSVData(r.Context(), session, r.Body, opts)
 	if err != nil {
 		return babyapi.ErrInvalidRequest(err)
 	}
+	a.publishETA(r, session.GetID())
+	a.checkLatestSessionAlerts(r, session)
 
-	w.WriteHeader(http.StatusOK)
-	return nil
+	return importReportResponse{ImportReport: report}
+}
+
+// checkLatestSessionAlerts checks alerts after an upload to the latest Session. Only the ID is known when the data is
+// stored in the DB, so the rest of the Session is read for its AlertRules
+func (a *API) checkLatestSessionAlerts(r *http.Request, session *SessionResource) {
+	if a.Notifier == nil || a.storageAdapter.Client == nil {
+		a.checkAlerts(r, session)
+		return
+	}
+
+	sr, err := a.Storage.Get(r.Context(), session.GetID())
+	if err != nil {
+		logger, _ := babyapi.GetLoggerFromContext(r.Context())
+		logger.Error("error getting session for alerts", "error", err)
+		return
+	}
+	a.checkAlerts(r, sr)
 }
 
 func (a *API) loadCSVToSession(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
@@ -373,34 +437,43 @@ func (a *API) loadCSVToSession(w http.ResponseWriter, r *http.Request, sr *Sessi
 		return nil, babyapi.ErrInvalidRequest(fmt.Errorf("unexpected Content-Type: %s", contentType))
 	}
 
-	err := a.uploadCSVData(r.Context(), sr, r.Body)
+	opts, err := importOptionsFromRequest(r)
 	if err != nil {
 		return nil, babyapi.ErrInvalidRequest(err)
 	}
 
-	w.WriteHeader(http.StatusOK)
-	return nil, nil
+	report, err := a.uploadCSVData(r.Context(), sr, r.Body, opts)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+	a.publishETA(r, sr.GetID())
+	a.checkAlerts(r, sr)
+
+	return importReportResponse{ImportReport: report}, nil
 }
 
-func (a *API) uploadCSVData(ctx context.Context, session *SessionResource, reader io.Reader) error {
-	err := session.LoadData(reader)
+func (a *API) uploadCSVData(ctx context.Context, session *SessionResource, reader io.Reader, opts twchart.ImportOptions) (twchart.ImportReport, error) {
+	report, err := session.LoadData(reader, opts)
 	if err != nil {
-		return fmt.Errorf("error loading CSV data: %w", err)
+		return report, fmt.Errorf("error loading CSV data: %w", err)
 	}
 
 	if a.storageAdapter.Client != nil {
-		err = a.storageAdapter.storeThermoworksData(ctx, session.GetID(), session.Data)
+		// the Session's data isn't loaded from the DB, so it only has the uploaded rows and the stored data decides
+		// what changes
+		changes, err := a.storageAdapter.storeUploadedData(ctx, session.GetID(), session.Data, opts.Mode)
 		if err != nil {
-			return fmt.Errorf("error storing Thermoworks data: %w", err)
+			return report, fmt.Errorf("error storing Thermoworks data: %w", err)
 		}
+		report.RecordChanges(changes)
 	} else {
 		err = a.API.Storage.Set(ctx, session)
 		if err != nil {
-			return fmt.Errorf("error storing session: %w", err)
+			return report, fmt.Errorf("error storing session: %w", err)
 		}
 	}
 
-	return nil
+	return report, nil
 }
 
 func (a *API) Setup(storeFilename string) error {
@@ -425,31 +498,241 @@ func (a *API) Setup(storeFilename string) error {
 
 }
 
-func (a *API) renderChart(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
-	if len(sr.Data) == 0 && a.storageAdapter.Client != nil {
-		thermoworksData, err := a.storageAdapter.Client.GetThermoworksDataBySession(r.Context(), sr.GetID())
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			return nil, babyapi.InternalServerError(err)
+// loadThermoworksData reads the Session's data from the DB since it is not included when getting a Session
+func (a *API) loadThermoworksData(ctx context.Context, sr *SessionResource) *babyapi.ErrResponse {
+	data, httpErr := a.thermoworksData(ctx, sr)
+	if httpErr != nil {
+		return httpErr
+	}
+	sr.Data = data
+
+	return nil
+}
+
+// thermoworksData returns the Session's data, reading it from the DB if it isn't loaded. Unlike loadThermoworksData,
+// the SessionResource is not modified
+func (a *API) thermoworksData(ctx context.Context, sr *SessionResource) ([]twchart.ThermoworksData, *babyapi.ErrResponse) {
+	if len(sr.Data) != 0 || a.storageAdapter.Client == nil {
+		return sr.Data, nil
+	}
+
+	thermoworksData, err := a.storageAdapter.Client.GetThermoworksDataBySession(ctx, sr.GetID())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	return thermoworksDataFromDB(thermoworksData), nil
+}
+
+// chartOptionsFromRequest reads the max_points, from, to, RoR, stall, theme, and axis query parameters
+func (a *API) chartOptionsFromRequest(r *http.Request, session twchart.Session) (twchart.ChartOptions, error) {
+	var chartOpts twchart.ChartOptions
+	query := r.URL.Query()
+
+	if maxPoints := query.Get("max_points"); maxPoints != "" {
+		n, err := strconv.Atoi(maxPoints)
+		if err != nil {
+			return chartOpts, fmt.Errorf("invalid max_points parameter: %w", err)
+		}
+		chartOpts.MaxPoints = n
+	}
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		chartOpts.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return chartOpts, fmt.Errorf("invalid from parameter: %w", err)
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		chartOpts.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return chartOpts, fmt.Errorf("invalid to parameter: %w", err)
+		}
+	}
+
+	chartOpts.DerivedSeries, err = derivedSeriesFromRequest(r, session)
+	if err != nil {
+		return chartOpts, err
+	}
+
+	chartOpts.StallOptions, err = stallOptionsFromRequest(r)
+	if err != nil {
+		return chartOpts, err
+	}
+
+	chartOpts.Theme, err = a.themeFromRequest(r, session)
+	if err != nil {
+		return chartOpts, err
+	}
+
+	chartOpts.ElapsedFrom, err = elapsedFromRequest(r, session)
+	if err != nil {
+		return chartOpts, err
+	}
+
+	return chartOpts, nil
+}
+
+// elapsedFromRequest gets the time that the x-axis is measured from when the axis query parameter is "elapsed". The
+// align parameter chooses the Session's start (default) or a stage like "stage:Development"
+func elapsedFromRequest(r *http.Request, session twchart.Session) (time.Time, error) {
+	query := r.URL.Query()
+	switch axis := query.Get("axis"); axis {
+	case "", "time":
+		return time.Time{}, nil
+	case "elapsed":
+	default:
+		return time.Time{}, fmt.Errorf("invalid axis parameter: %q", axis)
+	}
+
+	align, err := twchart.ParseAlignment(query.Get("align"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return align.Origin(session)
+}
+
+// derivedSeriesFromRequest gets the Session's default DerivedSeries and configures them using the ror_window and
+// ror_smoothing query parameters
+func derivedSeriesFromRequest(r *http.Request, session twchart.Session) ([]twchart.DerivedSeries, error) {
+	query := r.URL.Query()
+
+	window, err := parseDurationParam("ror_window", query.Get("ror_window"))
+	if err != nil {
+		return nil, err
+	}
+
+	var smoothing int
+	if smoothingParam := query.Get("ror_smoothing"); smoothingParam != "" {
+		smoothing, err = strconv.Atoi(smoothingParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ror_smoothing parameter: %w", err)
+		}
+	}
+
+	derived := session.DerivedSeries()
+	for i, series := range derived {
+		ror, ok := series.(twchart.RateOfRise)
+		if !ok {
+			continue
 		}
-		sr.Data = thermoworksDataFromDB(thermoworksData)
+		ror.Window = window
+		ror.Smoothing = smoothing
+		derived[i] = ror
+	}
+
+	return derived, nil
+}
+
+func (a *API) renderChart(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
 	}
 
-	chart, err := twchart.Session(sr.Session).Chart()
+	chartOpts, err := a.chartOptionsFromRequest(r, sr.Session)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	chart, err := twchart.Session(sr.Session).Chart(chartOpts)
 	if err != nil {
 		return nil, babyapi.InternalServerError(err)
 	}
 
 	snippet := chart.RenderSnippet()
 
+	// the data URL uses the same parameters so zoomed data matches the chart
+	dataURL := fmt.Sprintf("/sessions/%s/chart-data?%s", sr.GetID(), r.URL.RawQuery)
+
+	// link to the same chart with the other theme
+	themeQuery := r.URL.Query()
+	themeQuery.Set("theme", "dark")
+	if chartOpts.Theme.Dark {
+		themeQuery.Set("theme", "light")
+	}
+
+	// link to the same chart with the other x-axis
+	axisQuery := r.URL.Query()
+	axisQuery.Set("axis", "elapsed")
+	if !chartOpts.ElapsedFrom.IsZero() {
+		axisQuery.Del("axis")
+		axisQuery.Del("align")
+	}
+
 	return chartView.Renderer(struct {
-		Element template.HTML
-		Script  template.HTML
-		Title   string
-		BackURL string
+		Element    template.HTML
+		Script     template.HTML
+		ChartID    string
+		DataURL    string
+		UpdatesURL string
+		ReplayURL  string
+		Events     []twchart.Event
+		Title      string
+		BackURL    string
+		Dark       bool
+		ThemeURL   string
+		Elapsed    bool
+		Origin     int64
+		AxisURL    string
+		ImageURL   string
 	}{
-		Element: template.HTML(snippet.Element),
-		Script:  template.HTML(snippet.Script),
-		Title:   sr.Session.Name,
-		BackURL: fmt.Sprintf("/sessions/%s", sr.GetID()),
+		Element:    template.HTML(snippet.Element),
+		Script:     template.HTML(snippet.Script),
+		ChartID:    chart.ChartID,
+		DataURL:    dataURL,
+		UpdatesURL: fmt.Sprintf("/sessions/%s/updates", sr.GetID()),
+		ReplayURL:  fmt.Sprintf("/sessions/%s/replay?%s", sr.GetID(), r.URL.RawQuery),
+		Events:     sr.Session.Events,
+		Title:      sr.Session.Name,
+		BackURL:    fmt.Sprintf("/sessions/%s", sr.GetID()),
+		Dark:       chartOpts.Theme.Dark,
+		ThemeURL:   fmt.Sprintf("/sessions/%s/chart?%s", sr.GetID(), themeQuery.Encode()),
+		Elapsed:    !chartOpts.ElapsedFrom.IsZero(),
+		Origin:     chartOpts.ElapsedFrom.UnixMilli(),
+		AxisURL:    fmt.Sprintf("/sessions/%s/chart?%s", sr.GetID(), axisQuery.Encode()),
+		ImageURL:   fmt.Sprintf("/sessions/%s/chart.png?%s", sr.GetID(), r.URL.RawQuery),
 	}), nil
 }
+
+// chartSeries is the data for a single probe's line on the chart
+type chartSeries struct {
+	Name string
+	Data []opts.LineData
+}
+
+type chartDataResponse struct {
+	*babyapi.DefaultRenderer
+	Series []chartSeries
+}
+
+// chartData responds with the chart's series data. It is used to load higher resolution data when zooming in
+func (a *API) chartData(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	chartOpts, err := a.chartOptionsFromRequest(r, sr.Session)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	resp := chartDataResponse{}
+	for i, data := range sr.Session.ChartData(chartOpts) {
+		resp.Series = append(resp.Series, chartSeries{
+			Name: sr.Session.Probes[i].Name,
+			Data: data,
+		})
+	}
+	for i, data := range sr.Session.DerivedChartData(chartOpts) {
+		resp.Series = append(resp.Series, chartSeries{
+			Name: chartOpts.DerivedSeries[i].Name(),
+			Data: data,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// AssetsCDN loads the pages' CSS and JavaScript from public CDNs
+	AssetsCDN = "cdn"
+	// AssetsEmbedded serves the pinned copies of the pages' CSS and JavaScript that are embedded in the binary
+	AssetsEmbedded = "embedded"
+
+	staticPrefix = "/static/"
+)
+
+// staticFiles holds the pinned copies of the frontend assets. They are downloaded into the static directory with
+// `go generate ./api` before building
+//
+//go:generate go run download_assets.go
+//go:embed static
+var staticFiles embed.FS
+
+// assetManifest lists the name, pinned URL, and checksum of each asset
+//
+//go:embed assets.txt
+var assetManifest string
+
+// asset is a frontend file used by the HTML templates
+type asset struct {
+	// Name is the file's name in the static directory and is used to reference it in templates
+	Name string
+	// CDN is the pinned URL the file is loaded from when embedded assets are not used
+	CDN string
+	// SHA256 is the hex checksum of the file's content. It is empty if the asset isn't pinned to a checksum, and then
+	// it can't be downloaded or embedded
+	SHA256 string
+}
+
+// assets are all of the files used by the templates
+var assets = mustParseAssets(assetManifest)
+
+// parseAssets reads the manifest. Each line has an asset's name, URL, and checksum, or "-" if there is no checksum.
+// Blank lines and lines starting with "#" are ignored
+func parseAssets(manifest string) ([]asset, error) {
+	var result []asset
+	for i, line := range strings.Split(manifest, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected name, URL, and checksum but got %d fields", i+1, len(fields))
+		}
+
+		a := asset{Name: fields[0], CDN: fields[1]}
+		if fields[2] != "-" {
+			a.SHA256 = fields[2]
+		}
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+func mustParseAssets(manifest string) []asset {
+	result, err := parseAssets(manifest)
+	if err != nil {
+		panic("invalid assets.txt: " + err.Error())
+	}
+	return result
+}
+
+// staticAssets serves the embedded assets. Each file's URL includes a hash of its content so it can be cached
+// forever and still be replaced when the file changes
+type staticAssets struct {
+	fsys   fs.FS
+	hashes map[string]string
+}
+
+// newStaticAssets reads and hashes every asset from the filesystem. All of the assets must exist and match their
+// pinned checksums
+func newStaticAssets(fsys fs.FS) (*staticAssets, error) {
+	sa := &staticAssets{fsys: fsys, hashes: map[string]string{}}
+
+	var missing []string
+	for _, a := range assets {
+		data, err := fs.ReadFile(fsys, a.Name)
+		if errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, a.Name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading asset %q: %w", a.Name, err)
+		}
+
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if a.SHA256 == "" {
+			return nil, fmt.Errorf("embedded asset %q has no checksum in assets.txt", a.Name)
+		}
+		if a.SHA256 != hash {
+			return nil, fmt.Errorf("embedded asset %q does not match its checksum: run 'go generate ./api' and rebuild", a.Name)
+		}
+		sa.hashes[a.Name] = hash[:12]
+	}
+
+	if len(missing) != 0 {
+		return nil, fmt.Errorf("missing embedded assets: %s: run 'go generate ./api' and rebuild", strings.Join(missing, ", "))
+	}
+
+	return sa, nil
+}
+
+// url returns the asset's path including its content hash
+func (sa *staticAssets) url(name string) (string, bool) {
+	hash, ok := sa.hashes[name]
+	if !ok {
+		return "", false
+	}
+	return staticPrefix + hash + "/" + name, true
+}
+
+// ServeHTTP responds with an asset from a path like /static/[hash]/[name]. Paths with an old hash are not found,
+// so a cached page never gets a mismatched file
+func (sa *staticAssets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hash, name, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, staticPrefix), "/")
+	if !ok || sa.hashes[name] != hash {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := fs.ReadFile(sa.fsys, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := `"` + hash + `"`
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+	_, _ = w.Write(data)
+}
+
+// UseAssets sets where pages load their CSS and JavaScript from: AssetsCDN or AssetsEmbedded
+func (a *API) UseAssets(mode string) error {
+	switch mode {
+	case "", AssetsCDN:
+		a.staticAssets = nil
+		return nil
+	case AssetsEmbedded:
+		fsys, err := fs.Sub(staticFiles, "static")
+		if err != nil {
+			return err
+		}
+
+		sa, err := newStaticAssets(fsys)
+		if err != nil {
+			return err
+		}
+		a.staticAssets = sa
+		return nil
+	default:
+		return fmt.Errorf("invalid assets option: %q", mode)
+	}
+}
+
+// serveStatic serves the embedded assets when they are used
+func (a *API) serveStatic(w http.ResponseWriter, r *http.Request) {
+	if a.staticAssets == nil {
+		http.NotFound(w, r)
+		return
+	}
+	a.staticAssets.ServeHTTP(w, r)
+}
+
+// assetURL returns the URL a template uses to load the named asset
+func (a *API) assetURL(name string) (string, error) {
+	if a != nil && a.staticAssets != nil {
+		if url, ok := a.staticAssets.url(name); ok {
+			return url, nil
+		}
+	}
+
+	for _, asset := range assets {
+		if asset.Name == name {
+			return asset.CDN, nil
+		}
+	}
+	return "", fmt.Errorf("unknown asset: %q", name)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAssetFS creates a file for each asset and pins the assets to their checksums until the test is done
+func testAssetFS(t *testing.T) fstest.MapFS {
+	original := assets
+	t.Cleanup(func() { assets = original })
+	assets = slices.Clone(original)
+
+	fsys := fstest.MapFS{}
+	for i, a := range assets {
+		data := []byte("/* " + a.Name + " */")
+		sum := sha256.Sum256(data)
+		assets[i].SHA256 = hex.EncodeToString(sum[:])
+		fsys[a.Name] = &fstest.MapFile{Data: data}
+	}
+	return fsys
+}
+
+func TestStaticAssets(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		fsys := testAssetFS(t)
+		delete(fsys, "echarts.min.js")
+
+		_, err := newStaticAssets(fsys)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "echarts.min.js")
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		fsys := testAssetFS(t)
+		assets[0].SHA256 = strings.Repeat("0", 64)
+
+		_, err := newStaticAssets(fsys)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match its checksum")
+	})
+
+	t.Run("NoChecksum", func(t *testing.T) {
+		fsys := testAssetFS(t)
+		assets[0].SHA256 = ""
+
+		_, err := newStaticAssets(fsys)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no checksum")
+	})
+
+	sa, err := newStaticAssets(testAssetFS(t))
+	require.NoError(t, err)
+
+	url, ok := sa.url("uikit.min.css")
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(url, "/static/"))
+	assert.True(t, strings.HasSuffix(url, "/uikit.min.css"))
+
+	t.Run("Serve", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		sa.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "/* uikit.min.css */", w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/css")
+		assert.Contains(t, w.Header().Get("Cache-Control"), "immutable")
+
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		sa.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("WrongHash", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		sa.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/abc/uikit.min.css", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("AssetURL", func(t *testing.T) {
+		a := &API{}
+		url, err := a.assetURL("htmx.min.js")
+		require.NoError(t, err)
+		assert.Equal(t, "https://unpkg.com/htmx.org@1.9.8/dist/htmx.min.js", url)
+
+		a.staticAssets = sa
+		url, err = a.assetURL("htmx.min.js")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(url, "/static/"))
+
+		_, err = a.assetURL("jquery.js")
+		assert.Error(t, err)
+	})
+}
+
+func TestUseAssets(t *testing.T) {
+	a := &API{}
+	require.NoError(t, a.UseAssets(AssetsCDN))
+	assert.Nil(t, a.staticAssets)
+
+	assert.Error(t, a.UseAssets("local"))
+}
+
+func TestParseAssets(t *testing.T) {
+	result, err := parseAssets(`# comment
+
+htmx.min.js  https://unpkg.com/htmx.org@1.9.8/dist/htmx.min.js  -
+sse.js       https://unpkg.com/htmx.org@1.9.8/dist/ext/sse.js   abc123
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []asset{
+		{Name: "htmx.min.js", CDN: "https://unpkg.com/htmx.org@1.9.8/dist/htmx.min.js"},
+		{Name: "sse.js", CDN: "https://unpkg.com/htmx.org@1.9.8/dist/ext/sse.js", SHA256: "abc123"},
+	}, result)
+
+	_, err = parseAssets("htmx.min.js https://unpkg.com/htmx.org@1.9.8/dist/htmx.min.js")
+	assert.ErrorContains(t, err, "line 1")
+
+	t.Run("Manifest", func(t *testing.T) {
+		require.Len(t, assets, 6)
+		for _, a := range assets {
+			assert.NotEmpty(t, a.CDN, a.Name)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+type bbqAnalysisResponse struct {
+	*babyapi.DefaultRenderer
+	twchart.BBQAnalysis
+}
+
+// parseFloatParam parses an optional float
+func parseFloatParam(name, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// stallOptionsFromRequest reads the stall_min, stall_max, stall_rate, stall_window, and stall_duration query parameters
+func stallOptionsFromRequest(r *http.Request) (twchart.StallOptions, error) {
+	var stallOpts twchart.StallOptions
+	query := r.URL.Query()
+
+	var err error
+	for name, target := range map[string]*float64{
+		"stall_min":  &stallOpts.MinTemp,
+		"stall_max":  &stallOpts.MaxTemp,
+		"stall_rate": &stallOpts.MaxRate,
+	} {
+		*target, err = parseFloatParam(name, query.Get(name))
+		if err != nil {
+			return stallOpts, err
+		}
+	}
+
+	stallOpts.Window, err = parseDurationParam("stall_window", query.Get("stall_window"))
+	if err != nil {
+		return stallOpts, err
+	}
+
+	stallOpts.MinDuration, err = parseDurationParam("stall_duration", query.Get("stall_duration"))
+	if err != nil {
+		return stallOpts, err
+	}
+
+	return stallOpts, nil
+}
+
+// bbqAnalysis responds with the stalls and carryover cooking for a Session
+func (a *API) bbqAnalysis(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	stallOpts, err := stallOptionsFromRequest(r)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	return bbqAnalysisResponse{BBQAnalysis: sr.Session.AnalyzeBBQ(stallOpts)}, nil
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+const (
+	shiftTargetNotes = "notes"
+	shiftTargetData  = "data"
+)
+
+// shiftRequest is used to move a Session's notes or data by an offset. If Offset is not set, Event and Probe are used
+// to automatically align the notes and data
+type shiftRequest struct {
+	Target string
+	Offset string
+
+	Event string
+	Probe string
+	Range string
+}
+
+func (sr *shiftRequest) Bind(*http.Request) error {
+	switch sr.Target {
+	case shiftTargetNotes, shiftTargetData:
+	default:
+		return fmt.Errorf("invalid Target %q: must be %q or %q", sr.Target, shiftTargetNotes, shiftTargetData)
+	}
+
+	if sr.Offset == "" && sr.Event == "" {
+		return errors.New("one of Offset or Event is required")
+	}
+
+	return nil
+}
+
+type clockAlignmentResponse struct {
+	*babyapi.DefaultRenderer
+	twchart.ClockAlignment
+}
+
+// parseDurationParam parses an optional duration
+func parseDurationParam(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return d, nil
+}
+
+// proposeClockOffset wraps Session.ProposeClockOffset to convert errors to responses
+func proposeClockOffset(session twchart.Session, event, probe, searchRange string) (twchart.ClockAlignment, *babyapi.ErrResponse) {
+	rangeDuration, err := parseDurationParam("range", searchRange)
+	if err != nil {
+		return twchart.ClockAlignment{}, babyapi.ErrInvalidRequest(err)
+	}
+
+	alignment, err := session.ProposeClockOffset(event, probe, rangeDuration)
+	switch {
+	case errors.Is(err, twchart.ErrEventNotFound), errors.Is(err, twchart.ErrProbeNotFound), errors.Is(err, twchart.ErrNoData):
+		return alignment, babyapi.ErrInvalidRequest(err)
+	case err != nil:
+		return alignment, babyapi.InternalServerError(err)
+	}
+
+	return alignment, nil
+}
+
+// alignClock proposes a clock offset by matching an Event to the largest temperature change in a Probe
+func (a *API) alignClock(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	query := r.URL.Query()
+	if query.Get("event") == "" || query.Get("probe") == "" {
+		return nil, babyapi.ErrInvalidRequest(errors.New("event and probe parameters are required"))
+	}
+
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	alignment, httpErr := proposeClockOffset(sr.Session, query.Get("event"), query.Get("probe"), query.Get("range"))
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	return clockAlignmentResponse{ClockAlignment: alignment}, nil
+}
+
+// shiftSession moves the Session's notes or data by a fixed or automatically-aligned offset
+func (a *API) shiftSession(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	var req shiftRequest
+	if err := render.Bind(r, &req); err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	offset, err := parseDurationParam("Offset", req.Offset)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	if req.Offset == "" {
+		// Data is only needed to align the notes and data automatically
+		httpErr := a.loadThermoworksData(r.Context(), sr)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+
+		alignment, httpErr := proposeClockOffset(sr.Session, req.Event, req.Probe, req.Range)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		offset = alignment.Offset
+		if req.Target == shiftTargetData {
+			offset = -offset
+		}
+	}
+
+	switch req.Target {
+	case shiftTargetNotes:
+		sr.Session.ShiftNotes(offset)
+	case shiftTargetData:
+		sr.Session.ShiftData(offset)
+	}
+
+	switch {
+	case a.storageAdapter.Client == nil:
+		// key-value storage always saves the whole Session
+		err = a.Storage.Set(r.Context(), sr)
+	case req.Target == shiftTargetData:
+		// the DB moves the stored data so readings stored after it was loaded aren't lost
+		err = a.storageAdapter.shiftThermoworksData(r.Context(), sr.GetID(), offset)
+	default:
+		// the data loaded for alignment is left out so Set doesn't write it again
+		notes := *sr
+		notes.Session.Data = nil
+		err = a.Storage.Set(r.Context(), &notes)
+	}
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	return sr, nil
+}
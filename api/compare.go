@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+type comparisonResponse struct {
+	*babyapi.DefaultRenderer
+	Probe  string
+	Align  string
+	Series []twchart.ComparisonSeries
+}
+
+// compareIDsFromRequest reads Session IDs from the ids query parameter. IDs can be comma-separated or the parameter
+// can be repeated, which is how the list page's form sends them
+func compareIDsFromRequest(r *http.Request) []string {
+	var ids []string
+	for _, param := range r.URL.Query()["ids"] {
+		for id := range strings.SplitSeq(param, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// compareSessions charts a probe from multiple Sessions on the same elapsed time axis
+func (a *API) compareSessions(w http.ResponseWriter, r *http.Request) render.Renderer {
+	query := r.URL.Query()
+
+	ids := compareIDsFromRequest(r)
+	if len(ids) == 0 {
+		return babyapi.ErrInvalidRequest(errors.New("missing ids parameter"))
+	}
+
+	align, err := twchart.ParseAlignment(query.Get("align"))
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	compareOpts := twchart.CompareOptions{Probe: query.Get("probe"), Align: align}
+	chartOpts, err := a.chartOptionsFromRequest(r, twchart.Session{})
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+	compareOpts.MaxPoints = chartOpts.MaxPoints
+
+	sessions := make([]twchart.Session, 0, len(ids))
+	for _, id := range ids {
+		sr, err := a.Storage.Get(r.Context(), id)
+		if errors.Is(err, babyapi.ErrNotFound) {
+			return babyapi.ErrNotFoundResponse
+		}
+		if err != nil {
+			return babyapi.InternalServerError(err)
+		}
+
+		httpErr := a.loadThermoworksData(r.Context(), sr)
+		if httpErr != nil {
+			return httpErr
+		}
+		sessions = append(sessions, sr.Session)
+	}
+
+	series, err := twchart.Compare(sessions, compareOpts)
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	if render.GetAcceptedContentType(r) != render.ContentTypeHTML {
+		return comparisonResponse{Probe: compareOpts.Probe, Align: align.String(), Series: series}
+	}
+
+	// all of the Sessions use the first one's Theme so the colors are consistent
+	theme := a.Themes.Get(sessions[0].Type, chartOpts.Theme.Dark)
+
+	chart := twchart.ComparisonChart(series, theme)
+	snippet := chart.RenderSnippet()
+
+	themeQuery := r.URL.Query()
+	themeQuery.Set("theme", "dark")
+	if theme.Dark {
+		themeQuery.Set("theme", "light")
+	}
+
+	return compareView.Renderer(struct {
+		Element  template.HTML
+		Script   template.HTML
+		IDs      []string
+		Probe    string
+		Align    string
+		Dark     bool
+		ThemeURL string
+	}{
+		Element:  template.HTML(snippet.Element),
+		Script:   template.HTML(snippet.Script),
+		IDs:      ids,
+		Probe:    compareOpts.Probe,
+		Align:    query.Get("align"),
+		Dark:     theme.Dark,
+		ThemeURL: fmt.Sprintf("/sessions/compare?%s", themeQuery.Encode()),
+	})
+}
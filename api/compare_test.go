@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareIDsFromRequest(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", nil},
+		{"ids=a,b,c", []string{"a", "b", "c"}},
+		{"ids=a&ids=b", []string{"a", "b"}},
+		{"ids=a,%20b,&ids=c", []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/sessions/compare?"+tt.query, nil)
+			assert.Equal(t, tt.expected, compareIDsFromRequest(r))
+		})
+	}
+}
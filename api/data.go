@@ -0,0 +1,338 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/calvinmclean/twchart/storage/db"
+	"github.com/go-chi/render"
+)
+
+const (
+	dataFormatJSON   = "json"
+	dataFormatCSV    = "csv"
+	dataFormatNDJSON = "ndjson"
+)
+
+// maxDataTime is the end of the range when reading data from the DB without a To time. It is the latest time that
+// SQLite's date functions support
+var maxDataTime = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+// dataQuery selects the data returned by the data endpoint
+type dataQuery struct {
+	From   time.Time
+	To     time.Time
+	Probes []twchart.Probe
+	// Step is the length of each Bucket. If it is 0, every reading is returned
+	Step   time.Duration
+	Format string
+}
+
+// dataQueryFromRequest reads the from, to, probe, step, and format query parameters. Probes can be comma-separated
+// or repeated and all of the Session's probes are used if none are set
+func dataQueryFromRequest(r *http.Request, session twchart.Session) (dataQuery, error) {
+	q := dataQuery{Format: dataFormatJSON}
+	query := r.URL.Query()
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		q.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return q, fmt.Errorf("invalid from parameter: %w", err)
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		q.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return q, fmt.Errorf("invalid to parameter: %w", err)
+		}
+	}
+
+	q.Step, err = parseDurationParam("step", query.Get("step"))
+	if err != nil {
+		return q, err
+	}
+	if q.Step < 0 {
+		return q, fmt.Errorf("invalid step: must be positive")
+	}
+
+	for _, param := range query["probe"] {
+		for name := range strings.SplitSeq(param, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			probe, err := session.FindProbe(name)
+			if err != nil {
+				return q, err
+			}
+			q.Probes = append(q.Probes, probe)
+		}
+	}
+	if len(q.Probes) == 0 {
+		q.Probes = session.Probes
+	}
+
+	switch format := query.Get("format"); format {
+	case "":
+	case dataFormatJSON, dataFormatCSV, dataFormatNDJSON:
+		q.Format = format
+	default:
+		return q, fmt.Errorf("invalid format parameter: %q", format)
+	}
+
+	return q, nil
+}
+
+// sessionData responds with the Session's raw readings, or min, max, and average per step. CSV and NDJSON are
+// written while the data is read from storage. JSON groups the data by probe, so it is written at the end
+func (a *API) sessionData(w http.ResponseWriter, r *http.Request) render.Renderer {
+	sr, httpErr := a.API.GetRequestedResource(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	q, err := dataQueryFromRequest(r, sr.Session)
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	out := &startedWriter{w: w}
+	buf := bufio.NewWriter(out)
+
+	var dw dataWriter
+	switch q.Format {
+	case dataFormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		dw = &csvDataWriter{w: csv.NewWriter(buf)}
+	case dataFormatNDJSON:
+		w.Header().Set("Content-Type", contentTypeNDJSON)
+		dw = &ndjsonDataWriter{enc: json.NewEncoder(buf)}
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		dw = &jsonDataWriter{w: buf, query: q, series: map[string][]twchart.Bucket{}}
+	}
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+	fail := func(err error) render.Renderer {
+		// once part of the response is sent, the status can't be changed
+		if !out.started {
+			return babyapi.InternalServerError(err)
+		}
+		logger.Error("error writing session data", "error", err)
+		return nil
+	}
+
+	err = dw.start(q.Probes)
+	if err != nil {
+		return fail(err)
+	}
+
+	aggregators := make([]twchart.Aggregator, len(q.Probes))
+	for i := range aggregators {
+		aggregators[i].Step = q.Step
+	}
+
+	for d, err := range a.streamThermoworksData(r.Context(), sr, q.From, q.To) {
+		if err != nil {
+			return fail(err)
+		}
+
+		for i, probe := range q.Probes {
+			if !d.HasProbeData(probe.Position) {
+				continue
+			}
+			if bucket, ok := aggregators[i].Add(d.Time, d.GetProbeData(probe.Position)); ok {
+				err = dw.write(probe.Name, bucket)
+				if err != nil {
+					return fail(err)
+				}
+			}
+		}
+	}
+
+	for i, probe := range q.Probes {
+		if bucket, ok := aggregators[i].Flush(); ok {
+			err = dw.write(probe.Name, bucket)
+			if err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	err = dw.finish()
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+// streamThermoworksData yields the Session's readings in order between from and to, inclusive. A zero time leaves
+// that end of the range open. Readings are read from the DB one at a time unless they are already loaded
+func (a *API) streamThermoworksData(ctx context.Context, sr *SessionResource, from, to time.Time) iter.Seq2[twchart.ThermoworksData, error] {
+	if len(sr.Data) != 0 || a.storageAdapter.Client == nil {
+		return func(yield func(twchart.ThermoworksData, error) bool) {
+			for _, d := range sr.Data {
+				if !from.IsZero() && d.Time.Before(from) {
+					continue
+				}
+				if !to.IsZero() && d.Time.After(to) {
+					return
+				}
+				if !yield(d, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	if to.IsZero() {
+		to = maxDataTime
+	}
+	params := db.GetThermoworksDataBySessionBetweenParams{SessionID: sr.GetID(), FromTime: from, ToTime: to}
+
+	return func(yield func(twchart.ThermoworksData, error) bool) {
+		for datum, err := range a.storageAdapter.Client.StreamThermoworksDataBySessionBetween(ctx, params) {
+			if err != nil {
+				yield(twchart.ThermoworksData{}, err)
+				return
+			}
+			if !yield(thermoworksDatumFromDB(datum), nil) {
+				return
+			}
+		}
+	}
+}
+
+// startedWriter records if anything has been written to the response
+type startedWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (sw *startedWriter) Write(p []byte) (int, error) {
+	sw.started = true
+	return sw.w.Write(p)
+}
+
+// dataWriter writes Buckets for each probe in one of the data formats
+type dataWriter interface {
+	start(probes []twchart.Probe) error
+	write(probe string, bucket twchart.Bucket) error
+	finish() error
+}
+
+// dataRow is a Bucket and its probe, used for the NDJSON format
+type dataRow struct {
+	Probe string
+	twchart.Bucket
+}
+
+type csvDataWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvDataWriter) start([]twchart.Probe) error {
+	return cw.w.Write([]string{"time", "probe", "min", "max", "avg", "count"})
+}
+
+func (cw *csvDataWriter) write(probe string, bucket twchart.Bucket) error {
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return cw.w.Write([]string{
+		bucket.Time.Format(time.RFC3339),
+		probe,
+		formatFloat(bucket.Min),
+		formatFloat(bucket.Max),
+		formatFloat(bucket.Avg),
+		strconv.Itoa(bucket.Count),
+	})
+}
+
+func (cw *csvDataWriter) finish() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonDataWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonDataWriter) start([]twchart.Probe) error {
+	return nil
+}
+
+func (nw *ndjsonDataWriter) write(probe string, bucket twchart.Bucket) error {
+	return nw.enc.Encode(dataRow{Probe: probe, Bucket: bucket})
+}
+
+func (nw *ndjsonDataWriter) finish() error {
+	return nil
+}
+
+// dataSeries is a probe's Buckets in the JSON format
+type dataSeries struct {
+	Probe   string
+	Buckets []twchart.Bucket
+}
+
+type jsonDataWriter struct {
+	w      io.Writer
+	query  dataQuery
+	probes []string
+	series map[string][]twchart.Bucket
+}
+
+func (jw *jsonDataWriter) start(probes []twchart.Probe) error {
+	for _, p := range probes {
+		jw.probes = append(jw.probes, p.Name)
+		jw.series[p.Name] = []twchart.Bucket{}
+	}
+	return nil
+}
+
+func (jw *jsonDataWriter) write(probe string, bucket twchart.Bucket) error {
+	jw.series[probe] = append(jw.series[probe], bucket)
+	return nil
+}
+
+func (jw *jsonDataWriter) finish() error {
+	resp := struct {
+		From   *time.Time `json:",omitempty"`
+		To     *time.Time `json:",omitempty"`
+		Step   string     `json:",omitempty"`
+		Series []dataSeries
+	}{Series: []dataSeries{}}
+
+	if !jw.query.From.IsZero() {
+		resp.From = &jw.query.From
+	}
+	if !jw.query.To.IsZero() {
+		resp.To = &jw.query.To
+	}
+	if jw.query.Step > 0 {
+		resp.Step = jw.query.Step.String()
+	}
+	for _, name := range jw.probes {
+		resp.Series = append(resp.Series, dataSeries{Probe: name, Buckets: jw.series[name]})
+	}
+
+	return json.NewEncoder(jw.w).Encode(resp)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/twchart"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataQueryFromRequest(t *testing.T) {
+	bean := twchart.Probe{Name: "Bean", Position: twchart.ProbePosition1}
+	ambient := twchart.Probe{Name: "Ambient", Position: twchart.ProbePosition2}
+	session := twchart.Session{Probes: []twchart.Probe{bean, ambient}}
+
+	tests := []struct {
+		query       string
+		expected    dataQuery
+		expectedErr string
+	}{
+		{"", dataQuery{Probes: session.Probes, Format: dataFormatJSON}, ""},
+		{
+			"from=2025-05-24T08:00:00Z&to=2025-05-24T09:00:00Z&probe=ambient,bean&step=1m&format=csv",
+			dataQuery{
+				From:   time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC),
+				To:     time.Date(2025, time.May, 24, 9, 0, 0, 0, time.UTC),
+				Probes: []twchart.Probe{ambient, bean},
+				Step:   time.Minute,
+				Format: dataFormatCSV,
+			},
+			"",
+		},
+		{"probe=Bean&probe=Ambient&format=ndjson", dataQuery{Probes: []twchart.Probe{bean, ambient}, Format: dataFormatNDJSON}, ""},
+		{"probe=Pit", dataQuery{}, `probe not found: "Pit"`},
+		{"step=-1s", dataQuery{}, "invalid step: must be positive"},
+		{"step=abc", dataQuery{}, `invalid step: time: invalid duration "abc"`},
+		{"format=xml", dataQuery{}, `invalid format parameter: "xml"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/sessions/abc/data?"+tt.query, nil)
+			q, err := dataQueryFromRequest(r, session)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, q)
+		})
+	}
+}
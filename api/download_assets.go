@@ -0,0 +1,92 @@
+//go:build ignore
+
+// download_assets downloads the frontend assets listed in assets.txt into the static directory so they are embedded
+// in the server. It is run by `go generate ./api`. Files that already exist with the pinned checksum are skipped.
+// Assets without a checksum and downloads that don't match their checksum are an error, so nothing unverified is
+// embedded
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	manifestFile = "assets.txt"
+	staticDir    = "static"
+)
+
+func main() {
+	manifest, err := os.ReadFile(manifestFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	for i, line := range strings.Split(string(manifest), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 3 {
+			log.Fatalf("%s line %d: expected name, URL, and checksum but got %d fields", manifestFile, i+1, len(fields))
+		}
+		name, url, checksum := fields[0], fields[1], fields[2]
+		if checksum == "-" {
+			log.Fatalf("%s line %d: %q has no checksum: pin it before downloading", manifestFile, i+1, name)
+		}
+
+		err := downloadAsset(client, name, url, checksum)
+		if err != nil {
+			log.Fatalf("error downloading %q: %v", name, err)
+		}
+	}
+}
+
+// downloadAsset saves the asset in the static directory. An existing file is kept if it matches the checksum
+func downloadAsset(client *http.Client, name, url, checksum string) error {
+	filename := filepath.Join(staticDir, name)
+
+	existing, err := os.ReadFile(filename)
+	switch {
+	case err == nil && sha256Hex(existing) == checksum:
+		return nil
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256Hex(data)
+	if sum != checksum {
+		return fmt.Errorf("checksum %s does not match the pinned %s", sum, checksum)
+	}
+
+	return os.WriteFile(filename, data, 0o644)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+type etaResponse struct {
+	*babyapi.DefaultRenderer
+	ETAs []twchart.ETA
+}
+
+// etaOptionsFromRequest reads the window query parameter
+func etaOptionsFromRequest(r *http.Request) (twchart.ETAOptions, error) {
+	window, err := parseDurationParam("window", r.URL.Query().Get("window"))
+	if err != nil {
+		return twchart.ETAOptions{}, err
+	}
+	return twchart.ETAOptions{Window: window}, nil
+}
+
+// eta responds with the estimated time that each probe will reach its target
+func (a *API) eta(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	etaOpts, err := etaOptionsFromRequest(r)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	return etaResponse{ETAs: sr.Session.ETAs(etaOpts)}, nil
+}
+
+// publishETA sends updated ETAs to anyone viewing the Session after new data is added. The Session is read from
+// storage so the estimate uses all of its data
+func (a *API) publishETA(r *http.Request, id string) {
+	if !a.sseListeners.has(id) {
+		return
+	}
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	sr, err := a.Storage.Get(r.Context(), id)
+	if err != nil {
+		logger.Error("error getting session for ETA", "error", err)
+		return
+	}
+
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		logger.Error("error loading data for ETA", "error", httpErr.Error())
+		return
+	}
+
+	a.publish(r, id, &babyapi.ServerSentEvent{
+		Event: "eta",
+		Data:  etaList.Render(r, sr.Session.ETAs(twchart.ETAOptions{})),
+	})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+// moveEventRequest changes the time of the Event with the Note and From time, like when it is dragged on the chart
+type moveEventRequest struct {
+	Note string
+	From time.Time
+	To   time.Time
+}
+
+func (mr *moveEventRequest) Bind(*http.Request) error {
+	if mr.From.IsZero() || mr.To.IsZero() {
+		return errors.New("From and To are required")
+	}
+	return nil
+}
+
+// moveEvent changes the time of an Event and sends the updated Events to everyone viewing the Session
+func (a *API) moveEvent(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	var req moveEventRequest
+	if err := render.Bind(r, &req); err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	to := req.To.Local()
+	err := sr.Session.MoveEvent(req.Note, req.From, to)
+	if errors.Is(err, twchart.ErrEventNotFound) {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	// Only the Event is changed in the DB, but key-value storage always saves the whole Session
+	if a.storageAdapter.Client != nil {
+		err = a.storageAdapter.moveEvent(r.Context(), sr.GetID(), req.Note, req.From, to)
+	} else {
+		err = a.Storage.Set(r.Context(), sr)
+	}
+	if errors.Is(err, twchart.ErrEventNotFound) {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	a.publishEvents(r, sr)
+
+	return sr, nil
+}
+
+// publishEvents sends the Session's Events to everyone viewing it after one is added or moved. The Session's page
+// gets its list of notes and the chart gets the Events as JSON for its mark lines
+func (a *API) publishEvents(r *http.Request, sr *SessionResource) {
+	if !a.sseListeners.has(sr.GetID()) {
+		return
+	}
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	// Data is needed for the readings at each Event, but it isn't loaded with the Session from the DB
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		logger.Error("error loading data", "error", httpErr.Error())
+		return
+	}
+
+	a.publish(r, sr.GetID(), &babyapi.ServerSentEvent{
+		Event: "sessionEvents",
+		Data:  eventList.Render(r, sr.Session),
+	})
+
+	data, err := json.Marshal(sr.Session.EventReadings())
+	if err != nil {
+		logger.Error("error encoding events", "error", err)
+		return
+	}
+	a.publish(r, sr.GetID(), &babyapi.ServerSentEvent{Event: "events", Data: string(data)})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/calvinmclean/babyapi"
 	"github.com/calvinmclean/babyapi/html"
+	"github.com/calvinmclean/twchart"
 )
 
 // getUniqueTypes extracts unique non-empty type values from sessions
@@ -36,10 +37,10 @@ const (
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1">
     <title>Sessions</title>
-    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/css/uikit.min.css" />
-    <script src="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/js/uikit.min.js"></script>
-    <script src="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/js/uikit-icons.min.js"></script>
-    <script src="https://unpkg.com/htmx.org@1.9.8"></script>
+    <link rel="stylesheet" href="{{ asset "uikit.min.css" }}" />
+    <script src="{{ asset "uikit.min.js" }}"></script>
+    <script src="{{ asset "uikit-icons.min.js" }}"></script>
+    <script src="{{ asset "htmx.min.js" }}"></script>
 </head>
 <body class="uk-background-muted uk-padding">
     <div class="uk-container uk-container-small" id="sessions-container">
@@ -50,6 +51,7 @@ const (
 
 		<div class="uk-flex uk-flex-between uk-flex-middle">
             <h1 class="uk-heading-line"><span>Sessions</span></h1>
+            <a href="/timeline" class="uk-button uk-button-default uk-button-small">Timeline</a>
         </div>
 
 	<!-- Type Filter -->
@@ -66,9 +68,12 @@ const (
         </div>
 
         {{ if .Sessions }}
+        <form id="compare-form" action="/sessions/compare" method="get">
         <ul id="sessions-list" class="uk-list uk-list-divider uk-margin">
             {{ range .Sessions }}
             <li class="uk-flex uk-flex-between uk-flex-middle session-item">
+                <div class="uk-flex uk-flex-middle">
+                <input class="uk-checkbox uk-margin-small-right" type="checkbox" name="ids" value="{{ .Session.ID }}" aria-label="Compare {{ .Session.Name }}">
                 <div>
                     <h3 class="uk-margin-remove">
                         <a class="uk-link-heading" href="/sessions/{{ .Session.ID }}">{{ .Session.Name }}</a>
@@ -78,6 +83,7 @@ const (
                         {{ .Session.Date.Format "Monday, Jan 2, 2006" }}
                     </p>
                 </div>
+                </div>
                 <div>
                     <a href="/sessions/{{ .Session.ID }}/chart" class="uk-button uk-button-default uk-button-small">Chart</a>
                 </div>
@@ -85,6 +91,14 @@ const (
             {{ end }}
         </ul>
 
+        <!-- Compare selected sessions -->
+        <div class="uk-grid-small uk-flex-middle" uk-grid>
+            <div><input class="uk-input uk-form-small" type="text" name="probe" placeholder="Probe"></div>
+            <div><input class="uk-input uk-form-small" type="text" name="align" placeholder="start or stage:[name]"></div>
+            <div><button class="uk-button uk-button-default uk-button-small" type="submit">Compare Selected</button></div>
+        </div>
+        </form>
+
         {{ template "pagination" . }}
 
         {{ else }}
@@ -165,11 +179,28 @@ const (
     <td>{{ .Start.Format "3:04PM" }}</td>
     <td>{{ if not .End.IsZero }}{{ .End.Format "3:04PM" }}{{ else }}–{{ end }}</td>
     <td>{{ if .Duration }}{{ .Duration }}{{ else }}–{{ end }}</td>
-</tr>`
+    {{ if .ShowRateOfRise }}<td>{{ if .HasRateOfRise }}{{ printf "%.1f" .RateOfRise }}{{ else }}–{{ end }}</td>{{ end }}
+</tr>
+{{ if .Stats }}<tr class="uk-text-small uk-text-muted">
+    <td></td>
+    <td colspan="{{ if .ShowRateOfRise }}4{{ else }}3{{ end }}">
+        {{ range .Stats }}
+        <div>
+            {{ .Probe.Name }}: {{ printf "%.1f" .Start }} → {{ printf "%.1f" .End }} (Δ {{ printf "%.1f" .Delta }})
+            | min {{ printf "%.1f" .Min }} | max {{ printf "%.1f" .Max }} | mean {{ printf "%.1f" .Mean }}
+        </div>
+        {{ end }}
+    </td>
+</tr>{{ end }}`
 
 	eventRow         = html.Template("eventRow")
 	eventRowTemplate = `<li class="uk-flex uk-flex-between">
-    <span>{{ .Event.Note }}</span>
+    <span>
+        {{ .Event.Note }}
+        {{ with .Readings }}
+        <span class="uk-text-meta">{{ range $i, $r := . }}{{ if $i }}, {{ end }}{{ printf "%.0f" $r.Value }}{{ $r.Unit }} {{ $r.Probe }}{{ end }}</span>
+        {{ end }}
+    </span>
     <span class="uk-text-meta">
         {{ .Event.Time.Format "3:04PM" }}
         {{ $sinceStart := .Event.Time.Sub .SessionStartTime }}
@@ -182,6 +213,36 @@ const (
 </li>
 `
 
+	// eventList is every Event in the Session. It is sent to the Session's page when one is added or moved
+	eventList         = html.Template("eventList")
+	eventListTemplate = `{{ range $i, $e := .EventReadings }}
+    {{ $prevTime := zeroTime }}
+    {{ if gt $i 0 }}
+        {{ $prev := index $.Events (sub $i 1) }}
+        {{ $prevTime = $prev.Time }}
+    {{ end }}
+    {{ template "eventRow" dict "Event" $e.Event "Readings" $e.Readings "PrevEventTime" $prevTime "SessionStartTime" $.StartTime }}
+{{ end }}`
+
+	etaList         = html.Template("etaList")
+	etaListTemplate = `<ul class="uk-list uk-list-striped">
+    {{ range . }}
+    <li class="uk-flex uk-flex-between">
+        <span>{{ .Probe.Name }} → {{ printf "%.0f" .Probe.Target }}</span>
+        <span class="uk-text-meta">
+            {{ if .Reached }}
+            reached at {{ .Time.Format "3:04PM" }}
+            {{ else }}
+            {{ .Time.Format "3:04PM" }}
+            <span class="uk-text-muted">({{ formatDuration .Remaining }} left, {{ printf "%.1f" .Current }} now, {{ .Method }})</span>
+            {{ end }}
+        </span>
+    </li>
+    {{ else }}
+    <li class="uk-text-muted">Not enough data to estimate</li>
+    {{ end }}
+</ul>`
+
 	sessionDetail         = html.Template("sessionDetail")
 	sessionDetailTemplate = `{{ define "sessionDetail" }}
 <!DOCTYPE html>
@@ -190,12 +251,12 @@ const (
    <meta charset="UTF-8">
    <meta name="viewport" content="width=device-width, initial-scale=1">
    <title>{{ .Session.Name }}</title>
-   <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/css/uikit.min.css" />
-   <script src="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/js/uikit.min.js"></script>
-   <script src="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/js/uikit-icons.min.js"></script>
+   <link rel="stylesheet" href="{{ asset "uikit.min.css" }}" />
+   <script src="{{ asset "uikit.min.js" }}"></script>
+   <script src="{{ asset "uikit-icons.min.js" }}"></script>
 
-   <script src="https://unpkg.com/htmx.org@1.9.8"></script>
-   <script src="https://unpkg.com/htmx.org/dist/ext/sse.js"></script>
+   <script src="{{ asset "htmx.min.js" }}"></script>
+   <script src="{{ asset "sse.js" }}"></script>
 </head>
 <body class="uk-background-muted uk-padding" hx-ext="sse" sse-connect="/sessions/{{ .Session.ID }}/updates">
    <div class="uk-container uk-container-small">
@@ -207,7 +268,11 @@ const (
        <!-- Header -->
        <div class="uk-flex uk-flex-between uk-flex-middle">
            <h1 class="uk-heading-line"><span>{{ .Session.Name }}</span></h1>
-           <a href="/sessions/{{ .Session.ID }}/chart" class="uk-button uk-button-default uk-button-small">Chart</a>
+           <div>
+               <a href="/sessions/{{ .Session.ID }}/report" class="uk-button uk-button-default uk-button-small">Report</a>
+               <a href="/sessions/{{ .Session.ID }}/timeline" class="uk-button uk-button-default uk-button-small">Timeline</a>
+               <a href="/sessions/{{ .Session.ID }}/chart" class="uk-button uk-button-default uk-button-small">Chart</a>
+           </div>
        </div>
        <p class="uk-text-meta">{{ .Session.Date.Format "Monday, Jan 2, 2006" }}</p>
 
@@ -222,10 +287,11 @@ const (
 	                        <th>Start</th>
 	                        <th>End</th>
 	                        <th>Duration</th>
+	                        {{ if .ShowRateOfRise }}<th>Avg RoR ({{ .RateOfRiseUnit }})</th>{{ end }}
 	                    </tr>
 	                </thead>
 	                <tbody sse-swap="newSessionStage" hx-swap="beforeend">
-	                {{ range .Session.Stages }}
+	                {{ range .Stages }}
 						{{ template "stageRow" . }}
 	                {{ end }}
 	                </tbody>
@@ -233,18 +299,45 @@ const (
            </div>
        </div>
 
+       {{ if .ShowETA }}
+       <!-- ETA -->
+       <div class="uk-card uk-card-default uk-card-body uk-margin">
+           <h3 class="uk-card-title">ETA</h3>
+           <div sse-swap="eta">
+               {{ template "etaList" .ETAs }}
+           </div>
+       </div>
+       {{ end }}
+
+       {{ with .BBQ }}
+       <!-- BBQ Analysis -->
+       <div class="uk-card uk-card-default uk-card-body uk-margin">
+           <h3 class="uk-card-title">Stall + Rest</h3>
+           <ul class="uk-list uk-list-striped">
+               {{ range $stall := .Stalls }}
+               <li>
+                   {{ $stall.Probe.Name }} stalled for {{ formatDuration $stall.Duration }} at {{ printf "%.0f" $stall.StartTemp }}–{{ printf "%.0f" $stall.EndTemp }}
+                   <span class="uk-text-meta">({{ $stall.Start.Format "3:04PM" }} – {{ $stall.End.Format "3:04PM" }})</span>
+                   {{ with $stall.Wrap }}<span class="uk-text-muted">| wrapped {{ formatDuration $stall.WrapAfter }} in</span>{{ end }}
+               </li>
+               {{ else }}
+               <li class="uk-text-muted">No stall detected</li>
+               {{ end }}
+               {{ range .Carryover }}
+               <li>
+                   {{ .Probe.Name }} rose {{ printf "%.1f" .Rise }} to {{ printf "%.1f" .PeakTemp }} during {{ .Stage.Name }}
+                   <span class="uk-text-meta">(peak at {{ .PeakTime.Format "3:04PM" }})</span>
+               </li>
+               {{ end }}
+           </ul>
+       </div>
+       {{ end }}
+
         <!-- Events -->
         <div class="uk-card uk-card-default uk-card-body uk-margin">
             <h3 class="uk-card-title">Notes</h3>
-            <ul class="uk-list uk-list-striped" sse-swap="newSessionEvent" hx-swap="beforeend">
-                {{ range $i, $e := .Session.Events }}
-                    {{ $prevTime := zeroTime }}
-                    {{ if gt $i 0 }}
-                        {{ $prev := index $.Session.Events (sub $i 1) }}
-                        {{ $prevTime = $prev.Time }}
-                    {{ end }}
-                    {{ template "eventRow" dict "Event" $e "PrevEventTime" $prevTime "SessionStartTime" $.Session.StartTime }}
-                {{ end }}
+            <ul class="uk-list uk-list-striped" sse-swap="sessionEvents" hx-swap="innerHTML">
+                {{ template "eventList" .Session }}
             </ul>
         </div>
 
@@ -254,7 +347,7 @@ const (
            <h3 class="uk-card-title">Probes</h3>
            <ul class="uk-subnav uk-subnav-divider">
                {{ range .Session.Probes }}
-               <li><strong>{{ .Name }}</strong>: {{ .Position }}</li>
+               <li><strong>{{ .Name }}</strong>: {{ .Position }}{{ if .Measurement.Kind }} <span class="uk-text-muted">{{ .Measurement.Label }}</span>{{ end }}</li>
                {{ end }}
            </ul>
        </div>
@@ -274,14 +367,14 @@ const (
     <title>{{ .Title }} - Chart</title>
 
     <!-- UIkit -->
-    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/css/uikit.min.css" />
-    <script src="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/js/uikit.min.js"></script>
-    <script src="https://cdn.jsdelivr.net/npm/uikit@3.19.2/dist/js/uikit-icons.min.js"></script>
+    <link rel="stylesheet" href="{{ asset "uikit.min.css" }}" />
+    <script src="{{ asset "uikit.min.js" }}"></script>
+    <script src="{{ asset "uikit-icons.min.js" }}"></script>
 
     <!-- Apache ECharts -->
-    <script src="https://go-echarts.github.io/go-echarts-assets/assets/echarts.min.js"></script>
+    <script src="{{ asset "echarts.min.js" }}"></script>
 </head>
-<body class="uk-background-muted uk-padding">
+<body class="{{ if .Dark }}uk-background-secondary uk-light{{ else }}uk-background-muted{{ end }} uk-padding">
     <div class="uk-container uk-container-small">
 	    <ul class="uk-breadcrumb uk-margin-small-top">
 		    <li><a href="/sessions">Sessions</a></li>
@@ -293,8 +386,459 @@ const (
 
             <div class="uk-text-center uk-margin">
                 <a href="{{ .BackURL }}" class="uk-button uk-button-default uk-button-small">← Back</a>
+                <a href="{{ .ImageURL }}" class="uk-button uk-button-default uk-button-small" download>PNG</a>
+                <a href="{{ .AxisURL }}" class="uk-button uk-button-default uk-button-small">{{ if .Elapsed }}Clock Time{{ else }}Elapsed Time{{ end }}</a>
+                <a href="{{ .ThemeURL }}" class="uk-button uk-button-default uk-button-small">{{ if .Dark }}Light{{ else }}Dark{{ end }}</a>
+            </div>
+        </div>
+
+        <div class="uk-flex uk-flex-middle uk-margin-small">
+            <button id="replay-play" class="uk-button uk-button-primary uk-button-small">Play</button>
+            <select id="replay-speed" class="uk-select uk-form-small uk-form-width-xsmall uk-margin-small-left" aria-label="Speed">
+                <option value="1">1x</option>
+                <option value="2">2x</option>
+                <option value="5">5x</option>
+                <option value="10" selected>10x</option>
+                <option value="30">30x</option>
+                <option value="60">60x</option>
+            </select>
+            <input id="replay-progress" class="uk-range uk-margin-small-left" type="range" min="0" max="1000" value="0" aria-label="Progress">
+            <span id="replay-time" class="uk-text-small uk-text-nowrap uk-margin-small-left"></span>
+            <button id="replay-stop" class="uk-button uk-button-default uk-button-small uk-margin-small-left" hidden>Stop</button>
+        </div>
+        <p class="uk-text-meta uk-margin-remove">Click the chart to add a note, or drag a note's line to move it</p>
+    </div>
+
+    <div id="event-modal" uk-modal>
+        <div class="uk-modal-dialog uk-modal-body">
+            <h3 class="uk-modal-title">Add Note</h3>
+            <form id="event-form" class="uk-form-stacked">
+                <label class="uk-form-label" for="event-time">Time</label>
+                <input id="event-time" class="uk-input" type="time" step="1" required>
+                <label class="uk-form-label uk-margin-small-top" for="event-note">Note</label>
+                <input id="event-note" class="uk-input" type="text" required>
+                <p class="uk-text-right">
+                    <button class="uk-button uk-button-default uk-modal-close" type="button">Cancel</button>
+                    <button class="uk-button uk-button-primary" type="submit">Add</button>
+                </p>
+            </form>
+        </div>
+    </div>
+    <div class="uk-container">
+       	{{ .Element }}
+        {{ .Script }}
+    </div>
+    <script>
+        // Load higher resolution data for the zoomed range and splice it into the downsampled series
+        (function() {
+            const chart = echarts.getInstanceByDom(document.getElementById("{{ .ChartID }}"));
+            const dataURL = new URL("{{ .DataURL }}", window.location.origin);
+            const fullData = chart.getOption().series.map(s => s.data);
+
+            // With an elapsed x-axis, values are seconds since the origin instead of timestamps
+            const elapsed = {{ .Elapsed }};
+            const origin = {{ .Origin }};
+            const toTime = x => elapsed ? origin + x * 1000 : x;
+            const pointTime = p => {
+                const x = (p.value || p)[0];
+                return elapsed ? toTime(x) : Date.parse(x);
+            };
+            if (elapsed) {
+                chart.setOption({ xAxis: { axisPointer: { label: { formatter: p => formatElapsed(p.value) } } } });
+            }
+
+            function formatElapsed(seconds) {
+                const sign = seconds < 0 ? "-" : "";
+                seconds = Math.round(Math.abs(seconds));
+                return sign + Math.floor(seconds / 60) + ":" + String(seconds % 60).padStart(2, "0");
+            }
+
+            let timer;
+            chart.on("datazoom", () => {
+                clearTimeout(timer);
+                timer = setTimeout(loadZoomedData, 300);
+            });
+
+            function loadZoomedData() {
+                if (replaying) {
+                    return;
+                }
+
+                const zoom = chart.getOption().dataZoom[0];
+                if (zoom.start === 0 && zoom.end === 100) {
+                    chart.setOption({ series: fullData.map(data => ({ data: data })) });
+                    return;
+                }
+
+                const from = Math.floor(toTime(zoom.startValue) / 1000) * 1000;
+                const to = Math.ceil(toTime(zoom.endValue) / 1000) * 1000;
+                dataURL.searchParams.set("from", new Date(from).toISOString().replace(".000", ""));
+                dataURL.searchParams.set("to", new Date(to).toISOString().replace(".000", ""));
+
+                fetch(dataURL, { headers: { "Accept": "application/json" } })
+                    .then(resp => resp.json())
+                    .then(resp => {
+                        chart.setOption({
+                            series: resp.Series.map((series, i) => ({
+                                data: fullData[i].filter(p => pointTime(p) < from)
+                                    .concat(series.Data)
+                                    .concat(fullData[i].filter(p => pointTime(p) > to)),
+                            })),
+                        });
+                    });
+            }
+
+            // Extend the series with live readings while the session is in progress
+            const byTime = (a, b) => pointTime(a) - pointTime(b);
+            const updates = new EventSource("{{ .UpdatesURL }}");
+            updates.addEventListener("readings", e => {
+                const series = chart.getOption().series;
+                JSON.parse(e.data).Series.forEach(update => {
+                    const i = series.findIndex(s => s.name === update.Name);
+                    if (i === -1) {
+                        return;
+                    }
+                    if (elapsed) {
+                        update.Data = update.Data.map(p => ({ ...p, value: [(Date.parse(p.value[0]) - origin) / 1000, p.value[1]] }));
+                    }
+                    fullData[i] = fullData[i].concat(update.Data).sort(byTime);
+                    series[i].data = series[i].data.concat(update.Data).sort(byTime);
+                });
+                // the readings are shown after the replay stops
+                if (replaying) {
+                    return;
+                }
+                chart.setOption({ series: series.map(s => ({ data: s.data })) });
+                placeHandles();
+            });
+
+            // Play the session back from its start, revealing readings, notes, and stages as their time passes
+            const controls = {
+                play: document.getElementById("replay-play"),
+                stop: document.getElementById("replay-stop"),
+                speed: document.getElementById("replay-speed"),
+                progress: document.getElementById("replay-progress"),
+                time: document.getElementById("replay-time"),
+            };
+            const seriesNames = chart.getOption().series.map(s => s.name);
+            const marks = chart.getOption().series[seriesNames.length - 1];
+            const xValue = t => elapsed ? (t - origin) / 1000 : t;
+            const tickMs = 100;
+
+            let replay;
+            let replaying = false;
+            let replayTime;
+            let replayTimer;
+            let state;
+
+            function loadReplay() {
+                if (replay) {
+                    return Promise.resolve(replay);
+                }
+                return fetch("{{ .ReplayURL }}", { headers: { "Accept": "application/json" } })
+                    .then(resp => resp.json())
+                    .then(resp => {
+                        resp.Start = Date.parse(resp.Start);
+                        resp.End = Date.parse(resp.End);
+                        resp.Frames.forEach(f => f.Time = Date.parse(f.Time));
+                        // frames before the start, like early notes, are shown right away
+                        resp.First = resp.Frames.length > 0 ? Math.min(resp.Start, resp.Frames[0].Time) : resp.Start;
+                        replay = resp;
+                        return replay;
+                    });
+            }
+
+            // advance applies the frames up to the time. Notes only pop up while playing, not when seeking
+            function advance(t, notify) {
+                while (state.index < replay.Frames.length && replay.Frames[state.index].Time <= t) {
+                    const frame = replay.Frames[state.index++];
+                    switch (frame.Kind) {
+                    case "reading":
+                        Object.entries(frame.Readings).forEach(([name, value]) => {
+                            const i = seriesNames.indexOf(name);
+                            if (i !== -1) {
+                                state.series[i].push({ value: [xValue(frame.Time), value] });
+                            }
+                        });
+                        break;
+                    case "event":
+                        state.events.push({ name: frame.Note, xAxis: xValue(frame.Time) });
+                        if (notify) {
+                            showNote(frame);
+                        }
+                        break;
+                    case "stage_start":
+                        state.stages.push({ name: frame.Stage, color: frame.Color, start: frame.Time, end: null });
+                        break;
+                    case "stage_end": {
+                        const stage = state.stages.findLast(s => s.name === frame.Stage && s.end === null);
+                        if (stage) {
+                            stage.end = frame.Time;
+                        }
+                        break;
+                    }
+                    }
+                }
+            }
+
+            function showNote(frame) {
+                // use the text of an element so notes aren't parsed as HTML
+                const message = document.createElement("span");
+                message.textContent = new Date(frame.Time).toLocaleTimeString() + ": " + frame.Note;
+                UIkit.notification({ message: message.outerHTML, pos: "top-center", timeout: 4000 });
+            }
+
+            function renderReplay() {
+                const series = state.series.map(data => ({ data: data }));
+                // unfinished stages grow until the current time
+                series[series.length - 1] = {
+                    data: [],
+                    markLine: { data: state.events },
+                    markArea: {
+                        data: state.stages.map(s => [
+                            { name: s.name, xAxis: xValue(s.start), itemStyle: { color: s.color }, label: { show: true } },
+                            { xAxis: xValue(s.end === null ? replayTime : s.end) },
+                        ]),
+                    },
+                };
+                chart.setOption({ series: series });
+
+                const duration = replay.End - replay.First;
+                controls.progress.value = duration > 0 ? Math.round((replayTime - replay.First) / duration * 1000) : 1000;
+                controls.time.textContent = formatElapsed((replayTime - replay.Start) / 1000) + " / " + formatElapsed((replay.End - replay.Start) / 1000);
+            }
+
+            // seek shows the session as it was at the time
+            function seek(t) {
+                if (!replaying) {
+                    replaying = true;
+                    controls.stop.hidden = false;
+                    // keep the axis fixed so it doesn't move as data is revealed
+                    chart.setOption({
+                        xAxis: { min: xValue(replay.First), max: xValue(replay.End) },
+                        dataZoom: [{ start: 0, end: 100 }, { start: 0, end: 100 }],
+                    });
+                    placeHandles();
+                }
+                state = { index: 0, series: seriesNames.map(() => []), events: [], stages: [] };
+                replayTime = t;
+                advance(t, false);
+                renderReplay();
+            }
+
+            function tick() {
+                replayTime = Math.min(replayTime + tickMs * Number(controls.speed.value), replay.End);
+                advance(replayTime, true);
+                renderReplay();
+                if (replayTime >= replay.End) {
+                    pause();
+                }
+            }
+
+            function play() {
+                loadReplay().then(() => {
+                    // start just before the first frame so its note pops up too
+                    if (!replaying || replayTime >= replay.End) {
+                        seek(replay.First - 1);
+                    }
+                    replayTimer = setInterval(tick, tickMs);
+                    controls.play.textContent = "Pause";
+                });
+            }
+
+            function pause() {
+                clearInterval(replayTimer);
+                replayTimer = null;
+                controls.play.textContent = "Play";
+            }
+
+            function stop() {
+                pause();
+                replaying = false;
+                controls.stop.hidden = true;
+                controls.progress.value = 0;
+                controls.time.textContent = "";
+
+                const series = fullData.map(data => ({ data: data }));
+                series[series.length - 1] = { data: [], markLine: marks.markLine, markArea: marks.markArea };
+                chart.setOption({ xAxis: { min: null, max: null }, series: series });
+                placeHandles();
+            }
+
+            controls.play.addEventListener("click", () => replayTimer ? pause() : play());
+            controls.stop.addEventListener("click", stop);
+            controls.progress.addEventListener("input", () => {
+                const value = Number(controls.progress.value);
+                loadReplay().then(() => seek(replay.First + (replay.End - replay.First) * value / 1000));
+            });
+
+            // Click the chart to add a note at that time, and drag a note's line to move it. Changes are sent to
+            // everyone viewing the session, including this chart
+            const sessionURL = "{{ .BackURL }}";
+            const eventModal = document.getElementById("event-modal");
+            const eventForm = document.getElementById("event-form");
+            const eventTime = document.getElementById("event-time");
+            const eventNote = document.getElementById("event-note");
+            const handleWidth = 8;
+            const pixelTime = x => Math.round(toTime(chart.convertFromPixel({ xAxisIndex: 0 }, x)) / 1000) * 1000;
+
+            let events = {{ .Events }} || [];
+            let eventDate;
+            let lastDrag = 0;
+
+            function postJSON(url, body) {
+                return fetch(url, {
+                    method: "POST",
+                    headers: { "Content-Type": "application/json", "Accept": "application/json" },
+                    body: JSON.stringify(body),
+                })
+                    .then(resp => resp.text().then(text => {
+                        if (!resp.ok) {
+                            throw new Error(text || resp.statusText);
+                        }
+                        return text ? JSON.parse(text) : null;
+                    }));
+            }
+
+            function showError(err) {
+                const message = document.createElement("span");
+                message.textContent = err.message;
+                UIkit.notification({ message: message.outerHTML, status: "danger", pos: "top-center" });
+            }
+
+            function showEvents(list) {
+                events = list || [];
+                marks.markLine.data = events.map(e => ({ name: e.Note, xAxis: xValue(Date.parse(e.Time)) }));
+                if (!replaying) {
+                    const series = seriesNames.map(() => ({}));
+                    series[series.length - 1] = { markLine: { data: marks.markLine.data } };
+                    chart.setOption({ series: series });
+                }
+                placeHandles();
+            }
+
+            // placeHandles puts an invisible, draggable handle over each note's line
+            function placeHandles() {
+                const grid = chart.getModel().getComponent("grid").coordinateSystem.getRect();
+                chart.setOption({
+                    graphic: events.map((e, i) => {
+                        const x = chart.convertToPixel({ xAxisIndex: 0 }, xValue(Date.parse(e.Time)));
+                        return {
+                            id: "event-" + i,
+                            type: "rect",
+                            x: x - handleWidth / 2,
+                            y: grid.y,
+                            shape: { width: handleWidth, height: grid.height },
+                            style: { fill: "rgba(0, 0, 0, 0)" },
+                            cursor: "ew-resize",
+                            draggable: true,
+                            z: 100,
+                            // notes can't be moved during a replay or while they're zoomed out of view
+                            ignore: replaying || x < grid.x || x > grid.x + grid.width,
+                            ondrag: function () {
+                                this.y = grid.y;
+                            },
+                            ondragend: function () {
+                                lastDrag = Date.now();
+                                moveEvent(e, this.x + handleWidth / 2);
+                            },
+                        };
+                    }),
+                }, { replaceMerge: ["graphic"] });
+            }
+
+            function moveEvent(e, x) {
+                const to = pixelTime(x);
+                if (to === Date.parse(e.Time)) {
+                    placeHandles();
+                    return;
+                }
+                postJSON(sessionURL + "/move-event", { Note: e.Note, From: e.Time, To: new Date(to).toISOString() })
+                    .then(resp => showEvents(resp.Events))
+                    .catch(err => {
+                        showError(err);
+                        placeHandles();
+                    });
+            }
+
+            chart.getZr().on("click", e => {
+                // ignore the click at the end of dragging a note
+                if (replaying || Date.now() - lastDrag < 300 || !chart.containPixel("grid", [e.offsetX, e.offsetY])) {
+                    return;
+                }
+                eventDate = new Date(pixelTime(e.offsetX));
+                eventTime.value = eventDate.toTimeString().slice(0, 8);
+                eventNote.value = "";
+                UIkit.modal(eventModal).show();
+            });
+
+            eventForm.addEventListener("submit", e => {
+                e.preventDefault();
+                const [hours, minutes, seconds] = eventTime.value.split(":").map(Number);
+                const time = new Date(eventDate);
+                time.setHours(hours, minutes, seconds || 0, 0);
+                postJSON(sessionURL + "/add-event", { Note: eventNote.value, Time: time.toISOString() })
+                    .then(() => {
+                        UIkit.modal(eventModal).hide();
+                        return fetch(sessionURL, { headers: { "Accept": "application/json" } });
+                    })
+                    .then(resp => resp.json())
+                    .then(session => showEvents(session.Events))
+                    .catch(showError);
+            });
+
+            updates.addEventListener("events", e => showEvents(JSON.parse(e.data)));
+            chart.on("datazoom", placeHandles);
+            window.addEventListener("resize", () => setTimeout(placeHandles, 100));
+            placeHandles();
+        })();
+    </script>
+</body>
+</html>
+{{ end }}`
+
+	timelineView         = html.Template("timelineView")
+	timelineViewTemplate = `{{ define "timelineView" }}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{ .Title }} - Timeline</title>
+
+    <!-- UIkit -->
+    <link rel="stylesheet" href="{{ asset "uikit.min.css" }}" />
+    <script src="{{ asset "uikit.min.js" }}"></script>
+    <script src="{{ asset "uikit-icons.min.js" }}"></script>
+
+    <!-- Apache ECharts -->
+    <script src="{{ asset "echarts.min.js" }}"></script>
+</head>
+<body class="{{ if .Dark }}uk-background-secondary uk-light{{ else }}uk-background-muted{{ end }} uk-padding">
+    <div class="uk-container uk-container-small">
+	    <ul class="uk-breadcrumb uk-margin-small-top">
+		    <li><a href="/sessions">Sessions</a></li>
+		    {{ if not .DateRange }}<li><a href="{{ .BackURL }}">{{ .Title }}</a></li>{{ end }}
+		    <li><span>Timeline</span></li>
+		</ul>
+		<div class="uk-flex uk-flex-between uk-flex-middle">
+            <h1 class="uk-heading-line"><span>{{ .Title }}</span></h1>
+
+            <div class="uk-text-center uk-margin">
+                <a href="{{ .BackURL }}" class="uk-button uk-button-default uk-button-small">← Back</a>
+                <a href="{{ .ThemeURL }}" class="uk-button uk-button-default uk-button-small">{{ if .Dark }}Light{{ else }}Dark{{ end }}</a>
             </div>
         </div>
+
+        {{ if .DateRange }}
+        <form class="uk-grid-small uk-flex-middle" action="/timeline" method="get" uk-grid>
+            {{ if .Dark }}<input type="hidden" name="theme" value="dark">{{ end }}
+            <div><input class="uk-input uk-form-small" type="date" name="from" value="{{ .From }}" aria-label="From"></div>
+            <div><input class="uk-input uk-form-small" type="date" name="to" value="{{ .To }}" aria-label="To"></div>
+            <div><button class="uk-button uk-button-primary uk-button-small" type="submit">Update</button></div>
+        </form>
+        {{ if .Empty }}<p class="uk-text-muted">No sessions in this range</p>{{ end }}
+        {{ end }}
     </div>
     <div class="uk-container">
        	{{ .Element }}
@@ -302,6 +846,154 @@ const (
     </div>
 </body>
 </html>
+{{ end }}`
+
+	compareView         = html.Template("compareView")
+	compareViewTemplate = `{{ define "compareView" }}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Compare Sessions</title>
+
+    <!-- UIkit -->
+    <link rel="stylesheet" href="{{ asset "uikit.min.css" }}" />
+    <script src="{{ asset "uikit.min.js" }}"></script>
+    <script src="{{ asset "uikit-icons.min.js" }}"></script>
+
+    <!-- Apache ECharts -->
+    <script src="{{ asset "echarts.min.js" }}"></script>
+</head>
+<body class="{{ if .Dark }}uk-background-secondary uk-light{{ else }}uk-background-muted{{ end }} uk-padding">
+    <div class="uk-container uk-container-small">
+	    <ul class="uk-breadcrumb uk-margin-small-top">
+		    <li><a href="/sessions">Sessions</a></li>
+		    <li><span>Compare</span></li>
+		</ul>
+		<div class="uk-flex uk-flex-between uk-flex-middle">
+            <h1 class="uk-heading-line"><span>Compare</span></h1>
+
+            <div class="uk-text-center uk-margin">
+                <a href="{{ .ThemeURL }}" class="uk-button uk-button-default uk-button-small">{{ if .Dark }}Light{{ else }}Dark{{ end }}</a>
+            </div>
+        </div>
+
+        <form class="uk-grid-small uk-flex-middle" action="/sessions/compare" method="get" uk-grid>
+            {{ range .IDs }}<input type="hidden" name="ids" value="{{ . }}">{{ end }}
+            {{ if .Dark }}<input type="hidden" name="theme" value="dark">{{ end }}
+            <div><input class="uk-input uk-form-small" type="text" name="probe" value="{{ .Probe }}" placeholder="Probe"></div>
+            <div><input class="uk-input uk-form-small" type="text" name="align" value="{{ .Align }}" placeholder="start or stage:[name]"></div>
+            <div><button class="uk-button uk-button-primary uk-button-small" type="submit">Update</button></div>
+        </form>
+    </div>
+    <div class="uk-container">
+       	{{ .Element }}
+        {{ .Script }}
+    </div>
+</body>
+</html>
+{{ end }}`
+
+	reportView         = html.Template("reportView")
+	reportViewTemplate = `{{ define "reportView" }}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{ .Name }} - Report</title>
+    <link rel="stylesheet" href="{{ asset "uikit.min.css" }}" />
+    <style>
+        .report { max-width: 8in; }
+        .report img { width: 100%; height: auto; }
+        .report .uk-table td, .report .uk-table th { padding: 4px 8px; }
+        @page { size: letter; margin: 0.5in; }
+        @media print {
+            body { padding: 0 !important; background: #fff !important; font-size: 11px; }
+            .no-print { display: none !important; }
+            .report { max-width: none; }
+            .report h1 { margin-top: 0; }
+            .report table, .report img { break-inside: avoid; }
+        }
+    </style>
+</head>
+<body class="uk-padding">
+    <div class="uk-container report">
+        <div class="uk-flex uk-flex-between uk-flex-middle no-print">
+            <ul class="uk-breadcrumb uk-margin-remove">
+                <li><a href="/sessions">Sessions</a></li>
+                <li><a href="/sessions/{{ .ID }}">{{ .Name }}</a></li>
+                <li><span>Report</span></li>
+            </ul>
+            <div>
+                <button class="uk-button uk-button-default uk-button-small" onclick="window.print()">Print</button>
+                <a href="/sessions/{{ .ID }}/report.pdf" class="uk-button uk-button-default uk-button-small">PDF</a>
+            </div>
+        </div>
+
+        <h1 class="uk-h2 uk-margin-small-bottom">{{ .Name }}</h1>
+        <p class="uk-text-meta uk-margin-remove">
+            {{ with .Type }}{{ . }} · {{ end }}{{ if not (isZeroTime .Date) }}{{ .Date.Format "Monday, Jan 2, 2006" }}{{ end }}
+            {{ if not (isZeroTime .Start) }}· started {{ .Start.Format "3:04PM" }}{{ end }}
+            {{ if .Duration }}· {{ formatDuration .Duration }}{{ end }}
+        </p>
+        {{ with .Probes }}
+        <p class="uk-text-meta uk-margin-remove">
+            Probes: {{ range $i, $p := . }}{{ if $i }}, {{ end }}{{ $p.Name }}{{ if $p.Target }} (target {{ $p.Target }}){{ end }}{{ end }}
+        </p>
+        {{ end }}
+
+        <img class="uk-margin-top" src="{{ .ChartURL }}" alt="{{ .Name }} chart">
+
+        {{ with .Stages }}
+        <h3 class="uk-margin-small">Stages</h3>
+        <table class="uk-table uk-table-divider uk-table-small uk-margin-remove">
+            <thead>
+                <tr><th>Stage</th><th>Start</th><th>End</th><th>Duration</th></tr>
+            </thead>
+            <tbody>
+            {{ range . }}
+                <tr>
+                    <td>{{ .Stage.Name }}</td>
+                    <td>{{ .Stage.Start.Format "3:04PM" }}</td>
+                    <td>{{ if not (isZeroTime .Stage.End) }}{{ .Stage.End.Format "3:04PM" }}{{ else }}–{{ end }}</td>
+                    <td>{{ if not (isZeroTime .Stage.End) }}{{ .Stage.End.Sub .Stage.Start | formatDuration }}{{ else }}–{{ end }}</td>
+                </tr>
+                {{ with .Probes }}<tr class="uk-text-small uk-text-muted">
+                    <td></td>
+                    <td colspan="3">
+                        {{ range . }}
+                        <div>
+                            {{ .Probe.Name }}: {{ printf "%.1f" .Start }} → {{ printf "%.1f" .End }} (Δ {{ printf "%.1f" .Delta }})
+                            | min {{ printf "%.1f" .Min }} | max {{ printf "%.1f" .Max }} | mean {{ printf "%.1f" .Mean }}
+                        </div>
+                        {{ end }}
+                    </td>
+                </tr>{{ end }}
+            {{ end }}
+            </tbody>
+        </table>
+        {{ end }}
+
+        {{ with .Events }}
+        <h3 class="uk-margin-small">Notes</h3>
+        <table class="uk-table uk-table-divider uk-table-small uk-margin-remove">
+            <tbody>
+            {{ range . }}
+                <tr>
+                    <td class="uk-table-shrink uk-text-nowrap">{{ .Time.Format "3:04PM" }}</td>
+                    <td class="uk-table-shrink uk-text-nowrap uk-text-muted">{{ if and (not (isZeroTime $.Start)) (not (.Time.Before $.Start)) }}+{{ .Time.Sub $.Start | formatDuration }}{{ end }}</td>
+                    <td>{{ .Note }}</td>
+                    <td class="uk-text-muted">{{ range $i, $r := .Readings }}{{ if $i }}, {{ end }}{{ printf "%.0f" $r.Value }}{{ $r.Unit }} {{ $r.Probe }}{{ end }}</td>
+                </tr>
+            {{ end }}
+            </tbody>
+        </table>
+        {{ end }}
+    </div>
+</body>
+</html>
 {{ end }}`
 )
 
@@ -341,13 +1033,20 @@ func init() {
 		string(sessionDetail): sessionDetailTemplate,
 		string(listSessions):  listSessionsTemplate,
 		string(chartView):     chartViewTemplate,
+		string(compareView):   compareViewTemplate,
+		string(timelineView):  timelineViewTemplate,
+		string(reportView):    reportViewTemplate,
 		string(stageRow):      stageRowTemplate,
 		string(eventRow):      eventRowTemplate,
+		string(eventList):     eventListTemplate,
+		string(etaList):       etaListTemplate,
 		string(pagination):    paginationTemplate,
 	})
 
 	html.SetFuncs(func(r *http.Request) map[string]any {
+		api := getAPIFromContext(r.Context())
 		return map[string]any{
+			"asset":          api.assetURL,
 			"getUniqueTypes": getUniqueTypes,
 			"getPageRange":   getPageRange,
 			"sub": func(a, b int) int {
@@ -419,6 +1118,72 @@ func getPageRange(currentPage, totalPages int64) []int64 {
 	return pages
 }
 
+// sessionDetailData holds the data for rendering the sessionDetail template
+type sessionDetailData struct {
+	SessionResource
+	Stages         []stageRowData
+	ShowRateOfRise bool
+	RateOfRiseUnit string
+	BBQ            *twchart.BBQAnalysis
+	ShowETA        bool
+	ETAs           []twchart.ETA
+}
+
+// stageRowData holds the data for rendering the stageRow template
+type stageRowData struct {
+	twchart.Stage
+	ShowRateOfRise bool
+	HasRateOfRise  bool
+	RateOfRise     float64
+	Stats          []twchart.ProbeStats
+}
+
+// rateOfRiseSeries returns the first RateOfRise DerivedSeries for the Session, if it has one
+func rateOfRiseSeries(session twchart.Session) (twchart.DerivedSeries, bool) {
+	for _, series := range session.DerivedSeries() {
+		if _, ok := series.(twchart.RateOfRise); ok {
+			return series, true
+		}
+	}
+	return nil, false
+}
+
+func newSessionDetailData(sr SessionResource) sessionDetailData {
+	data := sessionDetailData{SessionResource: sr}
+
+	ror, ok := rateOfRiseSeries(sr.Session)
+	var rorPoints []twchart.Point
+	if ok {
+		data.ShowRateOfRise = true
+		data.RateOfRiseUnit = ror.Measurement().Unit
+		rorPoints = ror.Compute(sr.Session)
+	}
+
+	for _, stats := range sr.Session.StageStats() {
+		stage := stats.Stage
+		row := stageRowData{Stage: stage, ShowRateOfRise: data.ShowRateOfRise, Stats: stats.Probes}
+		if ok {
+			row.RateOfRise, row.HasRateOfRise = twchart.StageAverage(rorPoints, stage)
+		}
+		data.Stages = append(data.Stages, row)
+	}
+
+	for _, p := range sr.Session.Probes {
+		if p.Target != 0 {
+			data.ShowETA = true
+			data.ETAs = sr.Session.ETAs(twchart.ETAOptions{})
+			break
+		}
+	}
+
+	if sr.Session.Type == twchart.SessionTypeBBQ {
+		analysis := sr.Session.AnalyzeBBQ(twchart.StallOptions{})
+		data.BBQ = &analysis
+	}
+
+	return data
+}
+
 // SessionsListData holds the data for rendering the sessions list template
 type SessionsListData struct {
 	Sessions   []*SessionResource
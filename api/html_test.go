@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -82,4 +83,93 @@ func TestEventRow(t *testing.T) {
 		assert.Contains(t, result, "(+2m)")
 		assert.NotContains(t, result, "elapsed")
 	})
+
+	t.Run("WithReadings", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		data := map[string]any{
+			"Event": twchart.Event{Note: "Shaped dough", Time: event},
+			"Readings": []twchart.ProbeReading{
+				{Probe: "Dough", Value: 76.2, Unit: "°F"},
+				{Probe: "Ambient", Value: 71, Unit: "°F"},
+			},
+			"PrevEventTime":    prev,
+			"SessionStartTime": start,
+		}
+
+		result := eventRow.Render(r, data)
+
+		assert.Contains(t, result, "76°F Dough, 71°F Ambient")
+	})
+}
+
+func TestStageRow(t *testing.T) {
+	stage := twchart.Stage{
+		Name:     "Development",
+		Start:    time.Date(2025, time.May, 24, 20, 7, 0, 0, time.Local),
+		End:      time.Date(2025, time.May, 24, 20, 8, 30, 0, time.Local),
+		Duration: 90 * time.Second,
+	}
+
+	t.Run("WithRateOfRise", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		result := stageRow.Render(r, stageRowData{Stage: stage, ShowRateOfRise: true, HasRateOfRise: true, RateOfRise: 12.345})
+
+		assert.Contains(t, result, "8:07PM")
+		assert.Contains(t, result, "<td>12.3</td>")
+	})
+
+	t.Run("WithoutRateOfRise", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		result := stageRow.Render(r, stageRowData{Stage: stage})
+
+		assert.Contains(t, result, "1m30s")
+		assert.Equal(t, 4, strings.Count(result, "<td>"))
+	})
+
+	t.Run("WithStats", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		result := stageRow.Render(r, stageRowData{Stage: stage, Stats: []twchart.ProbeStats{{
+			Probe:   twchart.Probe{Name: "Bean"},
+			Samples: 90,
+			Min:     300, Max: 392.25, Mean: 350,
+			Start: 300, End: 392.25, Delta: 92.25,
+		}}})
+
+		assert.Contains(t, result, `colspan="3"`)
+		assert.Contains(t, result, "Bean: 300.0 → 392.2 (Δ 92.2)")
+		assert.Contains(t, result, "mean 350.0")
+	})
+}
+
+func TestETAList(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	probe := twchart.Probe{Name: "Pork", Position: twchart.ProbePosition2, Target: 195}
+
+	t.Run("Estimate", func(t *testing.T) {
+		result := etaList.Render(r, []twchart.ETA{{
+			Probe:     probe,
+			Current:   186.14,
+			Time:      time.Date(2025, time.May, 24, 10, 13, 0, 0, time.Local),
+			Remaining: 15 * time.Minute,
+			Method:    twchart.ETAMethodExponential,
+		}})
+
+		assert.Contains(t, result, "Pork → 195")
+		assert.Contains(t, result, "10:13AM")
+		assert.Contains(t, result, "(15m left, 186.1 now, exponential)")
+	})
+
+	t.Run("Reached", func(t *testing.T) {
+		result := etaList.Render(r, []twchart.ETA{{
+			Probe:   probe,
+			Reached: true,
+			Time:    time.Date(2025, time.May, 24, 10, 13, 0, 0, time.Local),
+		}})
+
+		assert.Contains(t, result, "reached at 10:13AM")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Contains(t, etaList.Render(r, []twchart.ETA{}), "Not enough data to estimate")
+	})
 }
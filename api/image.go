@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+// maxImageSize limits the width and height of chart images
+const maxImageSize = 4000
+
+// chartImage responds with a static image of the chart. It uses the same query parameters as the chart, plus width
+// and height
+func (a *API) chartImage(contentType string, write func(twchart.Session, io.Writer, twchart.ChartOptions, twchart.ImageOptions) error) http.HandlerFunc {
+	return babyapi.Handler(func(w http.ResponseWriter, r *http.Request) render.Renderer {
+		sr, httpErr := a.API.GetRequestedResource(r)
+		if httpErr != nil {
+			return httpErr
+		}
+
+		httpErr = a.loadThermoworksData(r.Context(), sr)
+		if httpErr != nil {
+			return httpErr
+		}
+
+		chartOpts, err := a.chartOptionsFromRequest(r, sr.Session)
+		if err != nil {
+			return babyapi.ErrInvalidRequest(err)
+		}
+
+		imgOpts, err := imageOptionsFromRequest(r)
+		if err != nil {
+			return babyapi.ErrInvalidRequest(err)
+		}
+
+		var buf bytes.Buffer
+		err = write(sr.Session, &buf, chartOpts, imgOpts)
+		if errors.Is(err, twchart.ErrNoData) {
+			return babyapi.ErrInvalidRequest(err)
+		}
+		if err != nil {
+			return babyapi.InternalServerError(err)
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		_, _ = buf.WriteTo(w)
+		return nil
+	})
+}
+
+// imageOptionsFromRequest reads the width and height query parameters
+func imageOptionsFromRequest(r *http.Request) (twchart.ImageOptions, error) {
+	var imgOpts twchart.ImageOptions
+	query := r.URL.Query()
+
+	for _, param := range []struct {
+		name  string
+		value *int
+		min   int
+	}{{"width", &imgOpts.Width, twchart.MinImageWidth}, {"height", &imgOpts.Height, twchart.MinImageHeight}} {
+		s := query.Get(param.name)
+		if s == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil || n < param.min || n > maxImageSize {
+			return imgOpts, fmt.Errorf("invalid %s parameter: must be between %d and %d", param.name, param.min, maxImageSize)
+		}
+		*param.value = n
+	}
+
+	return imgOpts, nil
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/calvinmclean/twchart"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageOptionsFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/sessions/abc/chart.png?width=640&height=320", nil)
+	imgOpts, err := imageOptionsFromRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, twchart.ImageOptions{Width: 640, Height: 320}, imgOpts)
+
+	r = httptest.NewRequest("GET", "/sessions/abc/chart.png", nil)
+	imgOpts, err = imageOptionsFromRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, twchart.ImageOptions{}, imgOpts)
+
+	for _, query := range []string{"width=0", "height=-1", "width=abc", "height=5000", "width=199", "height=149"} {
+		r = httptest.NewRequest("GET", "/sessions/abc/chart.png?"+query, nil)
+		_, err = imageOptionsFromRequest(r)
+		assert.Error(t, err, query)
+	}
+
+	r = httptest.NewRequest("GET", "/sessions/abc/chart.png?width=10", nil)
+	_, err = imageOptionsFromRequest(r)
+	assert.EqualError(t, err, "invalid width parameter: must be between 200 and 4000")
+}
@@ -54,7 +54,7 @@ func loadSessionFromFile(filename string) (twchart.Session, error) {
 	}
 
 	dataFilename := strings.TrimSuffix(filename, ".txt") + ".csv"
-	err = s.LoadDataFromFile(dataFilename)
+	_, err = s.LoadDataFromFile(dataFilename, twchart.ImportOptions{})
 	if err != nil {
 		return s, fmt.Errorf("error loading Thermoworks data: %w", err)
 	}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+type readingsResponse struct {
+	*babyapi.DefaultRenderer
+	Readings int
+	Rows     int
+}
+
+// decodeReadings reads a single JSON reading, a JSON array of readings, or newline-delimited JSON readings
+func decodeReadings(r *http.Request) ([]twchart.Reading, error) {
+	if r.Header.Get("Content-Type") == contentTypeNDJSON {
+		var readings []twchart.Reading
+		scanner := bufio.NewScanner(r.Body)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+
+			var reading twchart.Reading
+			err := json.Unmarshal(scanner.Bytes(), &reading)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing line %d: %w", line, err)
+			}
+			readings = append(readings, reading)
+		}
+		return readings, scanner.Err()
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errors.New("missing request body")
+	}
+
+	if body[0] != '[' {
+		var reading twchart.Reading
+		err = json.Unmarshal(body, &reading)
+		return []twchart.Reading{reading}, err
+	}
+
+	var readings []twchart.Reading
+	err = json.Unmarshal(body, &readings)
+	return readings, err
+}
+
+// addReadings stores live probe readings and publishes them to anyone viewing the Session
+func (a *API) addReadings(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	readings, err := decodeReadings(r)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(fmt.Errorf("error parsing readings: %w", err))
+	}
+
+	// Readings can have any time zone, but the rest of the Session is in local time
+	for i := range readings {
+		readings[i].Time = readings[i].Time.Local()
+	}
+
+	rows, err := sr.Session.AddReadings(readings)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	// Only the new rows are stored in the DB, but key-value storage always saves the whole Session
+	if a.storageAdapter.Client != nil {
+		err = a.storageAdapter.storeThermoworksData(r.Context(), sr.GetID(), rows)
+	} else {
+		err = a.Storage.Set(r.Context(), sr)
+	}
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	a.publishReadings(r, sr, rows)
+	a.publishETA(r, sr.GetID())
+	a.checkAlerts(r, sr)
+
+	return readingsResponse{Readings: len(readings), Rows: len(rows)}, nil
+}
+
+// publishReadings sends the new rows as chart data so the chart can be extended live
+func (a *API) publishReadings(r *http.Request, sr *SessionResource, rows []twchart.ThermoworksData) {
+	if !a.sseListeners.has(sr.GetID()) || len(rows) == 0 {
+		return
+	}
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	// The chart data only includes the new rows since the range is limited to them
+	session := sr.Session
+	session.Data = rows
+	chartOpts := twchart.ChartOptions{
+		MaxPoints: -1,
+		From:      rows[0].Time,
+		To:        rows[len(rows)-1].Time,
+	}
+
+	update := chartDataResponse{}
+	for i, data := range session.ChartData(chartOpts) {
+		update.Series = append(update.Series, chartSeries{
+			Name: session.Probes[i].Name,
+			Data: data,
+		})
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		logger.Error("error encoding readings", "error", err)
+		return
+	}
+
+	a.publish(r, sr.GetID(), &babyapi.ServerSentEvent{Event: "readings", Data: string(data)})
+}
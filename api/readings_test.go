@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/twchart"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeReadings(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    []twchart.Reading
+		expectedErr string
+	}{
+		{
+			"Single",
+			"application/json",
+			`{"time":"2025-05-24T08:00:00Z","probe":1,"value":250}`,
+			[]twchart.Reading{{Time: start, Probe: twchart.ProbePosition1, Value: 250}},
+			"",
+		},
+		{
+			"Array",
+			"application/json",
+			`[{"time":"2025-05-24T08:00:00Z","probe":1,"value":250},{"time":"2025-05-24T08:00:00Z","probe":2,"value":100}]`,
+			[]twchart.Reading{
+				{Time: start, Probe: twchart.ProbePosition1, Value: 250},
+				{Time: start, Probe: twchart.ProbePosition2, Value: 100},
+			},
+			"",
+		},
+		{
+			"NDJSON",
+			contentTypeNDJSON,
+			"{\"time\":\"2025-05-24T08:00:00Z\",\"probe\":1,\"value\":250}\n\n{\"time\":\"2025-05-24T08:00:01Z\",\"probe\":1,\"value\":251}\n",
+			[]twchart.Reading{
+				{Time: start, Probe: twchart.ProbePosition1, Value: 250},
+				{Time: start.Add(time.Second), Probe: twchart.ProbePosition1, Value: 251},
+			},
+			"",
+		},
+		{
+			"InvalidNDJSON",
+			contentTypeNDJSON,
+			"{\"time\":\"2025-05-24T08:00:00Z\",\"probe\":1,\"value\":250}\n{\"probe\":7}\n",
+			nil,
+			"error parsing line 2: invalid ProbePosition: 7",
+		},
+		{
+			"Empty",
+			"application/json",
+			" ",
+			nil,
+			"missing request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			readings, err := decodeReadings(r)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, readings)
+		})
+	}
+}
+
+func TestAddReadings(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+	adapter, _ := newTestStorage(t)
+	api := New()
+	api.storageAdapter = adapter
+
+	sr := newTestSessionResource(start, 0)
+	require.NoError(t, adapter.Set(ctx, sr))
+
+	// the same instant with two offsets is one row
+	r := httptest.NewRequest("POST", "/", strings.NewReader(
+		`[{"time":"2025-05-24T08:00:00Z","probe":1,"value":250},{"time":"2025-05-24T01:00:00-07:00","probe":2,"value":100}]`,
+	))
+	r.Header.Set("Content-Type", "application/json")
+	resp, httpErr := api.addReadings(httptest.NewRecorder(), r, sr)
+	require.Nil(t, httpErr)
+	assert.Equal(t, 1, resp.(readingsResponse).Rows)
+
+	require.Len(t, sr.Session.Data, 1)
+	assert.Equal(t, time.Local, sr.Session.Data[0].Time.Location())
+
+	rows, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.True(t, start.Equal(rows[0].Timestamp))
+	assert.Equal(t, []float64{250, 100}, thermoworksDataFromDB(rows)[0].ProbeData[:2])
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+type replayResponse struct {
+	*babyapi.DefaultRenderer
+	twchart.Replay
+}
+
+// replay responds with the Session's readings, Events, and Stages in time order for playing it back on the chart. It
+// uses the same query parameters as the chart so the readings match it
+func (a *API) replay(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	chartOpts, err := a.chartOptionsFromRequest(r, sr.Session)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	return replayResponse{Replay: sr.Session.Replay(chartOpts)}, nil
+}
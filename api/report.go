@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+type reportResponse struct {
+	*babyapi.DefaultRenderer
+	twchart.Report
+}
+
+// reportViewData holds the data for rendering the reportView template
+type reportViewData struct {
+	twchart.Report
+	ID string
+	// ChartURL is the chart image with the report's query parameters so it matches its chart options
+	ChartURL string
+}
+
+// sessionReport shows a printable summary of the Session with its chart
+func (a *API) sessionReport(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	if render.GetAcceptedContentType(r) != render.ContentTypeHTML {
+		return reportResponse{Report: sr.Session.Report()}, nil
+	}
+
+	// the report is printed, so the chart is always light
+	query := r.URL.Query()
+	query.Del("theme")
+	query.Set("width", "1000")
+	query.Set("height", "470")
+
+	return reportView.Renderer(reportViewData{
+		Report:   sr.Session.Report(),
+		ID:       sr.GetID(),
+		ChartURL: fmt.Sprintf("/sessions/%s/chart.svg?%s", sr.GetID(), query.Encode()),
+	}), nil
+}
+
+// sessionReportPDF responds with the Session's report as a one-page PDF. It uses the same query parameters as the
+// chart, except for the theme
+func (a *API) sessionReportPDF(w http.ResponseWriter, r *http.Request) render.Renderer {
+	sr, httpErr := a.API.GetRequestedResource(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	httpErr = a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	chartOpts, err := a.chartOptionsFromRequest(r, sr.Session)
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	// the report is printed, so it uses the light variant of the configured Theme
+	light := a.Themes.Get(sr.Session.Type, false)
+	chartOpts.Theme = &light
+
+	var buf bytes.Buffer
+	err = sr.Session.WriteReportPDF(&buf, chartOpts)
+	if err != nil {
+		return babyapi.InternalServerError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="report.pdf"`)
+	_, _ = buf.WriteTo(w)
+	return nil
+}
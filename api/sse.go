@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/calvinmclean/babyapi"
+)
+
+// sseListeners are the channels of everyone viewing each Session, so updates reach all of them
+type sseListeners struct {
+	mu    sync.Mutex
+	chans map[string]map[chan *babyapi.ServerSentEvent]struct{}
+}
+
+func newSSEListeners() *sseListeners {
+	return &sseListeners{chans: map[string]map[chan *babyapi.ServerSentEvent]struct{}{}}
+}
+
+// add creates a channel for a new viewer of the Session
+func (l *sseListeners) add(id string) chan *babyapi.ServerSentEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// buffer events so ones sent together, like new readings and the updated ETA, aren't dropped
+	c := make(chan *babyapi.ServerSentEvent, 10)
+	if l.chans[id] == nil {
+		l.chans[id] = map[chan *babyapi.ServerSentEvent]struct{}{}
+	}
+	l.chans[id][c] = struct{}{}
+	return c
+}
+
+// remove closes the viewer's channel
+func (l *sseListeners) remove(id string, c chan *babyapi.ServerSentEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.chans[id], c)
+	if len(l.chans[id]) == 0 {
+		delete(l.chans, id)
+	}
+	close(c)
+}
+
+// has returns true if anyone is viewing the Session, so updates that are expensive to create can be skipped
+func (l *sseListeners) has(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.chans[id]) > 0
+}
+
+// publish sends the event to everyone viewing the Session. Viewers that are too far behind miss it. It returns the
+// number of viewers that the event was sent to
+func (l *sseListeners) publish(id string, event *babyapi.ServerSentEvent) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sent := 0
+	for c := range l.chans[id] {
+		select {
+		case c <- event:
+			sent++
+		default:
+		}
+	}
+	return sent
+}
+
+func (a *API) sseUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	id := a.API.GetIDParam(r)
+
+	sseChan := a.sseListeners.add(id)
+	defer a.sseListeners.remove(id, sseChan)
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Content-Type", "text/event-stream")
+
+	for {
+		select {
+		case e := <-sseChan:
+			e.Write(w)
+		case <-r.Context().Done():
+			return
+		case <-a.Done():
+			return
+		}
+	}
+}
+
+// publish sends the event to everyone viewing the Session
+func (a *API) publish(r *http.Request, id string, event *babyapi.ServerSentEvent) {
+	if a.sseListeners.publish(id, event) == 0 {
+		logger, _ := babyapi.GetLoggerFromContext(r.Context())
+		logger.Info("no listeners for server-sent event", "event", event.Event)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEListeners(t *testing.T) {
+	l := newSSEListeners()
+	event := &babyapi.ServerSentEvent{Event: "events"}
+
+	assert.False(t, l.has("a"))
+	assert.Equal(t, 0, l.publish("a", event))
+
+	first := l.add("a")
+	second := l.add("a")
+	other := l.add("b")
+	assert.True(t, l.has("a"))
+
+	assert.Equal(t, 2, l.publish("a", event))
+	assert.Equal(t, event, <-first)
+	assert.Equal(t, event, <-second)
+	assert.Empty(t, other)
+
+	l.remove("a", first)
+	assert.Equal(t, 1, l.publish("a", event))
+
+	l.remove("a", second)
+	assert.False(t, l.has("a"))
+	assert.True(t, l.has("b"))
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+)
+
+// sessionResponse adds the per-stage stats and the probe readings at each Event to a Session's JSON response
+type sessionResponse struct {
+	*SessionResource
+	StageStats []twchart.StageStats
+	// Events replaces the Session's Events so they include the readings
+	Events []twchart.EventReadings
+}
+
+func (sr *sessionResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	// the HTML page calculates its own stats
+	if render.GetAcceptedContentType(r) == render.ContentTypeHTML {
+		return nil
+	}
+
+	session := sr.Session
+	if api := getAPIFromContext(r.Context()); api != nil {
+		data, httpErr := api.thermoworksData(r.Context(), sr.SessionResource)
+		if httpErr != nil {
+			return httpErr
+		}
+		// Data is only set on the copy so it isn't included in the response
+		session.Data = data
+	}
+
+	sr.StageStats = session.StageStats()
+	sr.Events = session.EventReadings()
+	return nil
+}
+
+// sessionStatsResponse has the per-stage stats with enough details about the Session to compare it to others
+type sessionStatsResponse struct {
+	*babyapi.DefaultRenderer
+
+	ID     string
+	Name   string
+	Type   twchart.SessionType
+	Date   time.Time
+	Stages []twchart.StageStats
+}
+
+func (a *API) sessionStats(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	httpErr := a.loadThermoworksData(r.Context(), sr)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	return sessionStatsResponse{
+		ID:     sr.GetID(),
+		Name:   sr.Session.Name,
+		Type:   sr.Session.Type,
+		Date:   sr.Session.Date,
+		Stages: sr.Session.StageStats(),
+	}, nil
+}
@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"iter"
+	"math"
 	"net/url"
 	"time"
 
@@ -45,11 +47,30 @@ func (c storageAdapter) dbSessionToAPIResource(
 	}
 	resource.Session.ID.ID = xidID
 
+	if session.Cleaning.Valid {
+		err = json.Unmarshal([]byte(session.Cleaning.String), &resource.Session.Cleaning)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing cleaning options: %w", err)
+		}
+	}
+
+	if session.Alerts.Valid {
+		err = json.Unmarshal([]byte(session.Alerts.String), &resource.Session.Alerts)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing alerts: %w", err)
+		}
+	}
+
 	// Convert probes
 	for _, probe := range probes {
 		resource.Session.Probes = append(resource.Session.Probes, twchart.Probe{
 			Name:     probe.Name,
 			Position: twchart.ProbePosition(probe.Position),
+			Target:   probe.Target.Float64,
+			Measurement: twchart.Measurement{
+				Kind: twchart.MeasurementKind(probe.Kind.String),
+				Unit: probe.Unit.String,
+			},
 		})
 	}
 
@@ -82,34 +103,23 @@ func (c storageAdapter) dbSessionToAPIResource(
 func thermoworksDataFromDB(thermoworksData []db.ThermoworksDatum) []twchart.ThermoworksData {
 	out := []twchart.ThermoworksData{}
 	for _, data := range thermoworksData {
-		td := twchart.ThermoworksData{
-			Time: data.Timestamp,
-		}
+		out = append(out, thermoworksDatumFromDB(data))
+	}
+	return out
+}
 
-		probeData := make([]float64, 6)
-		if data.Probe1Temp.Valid {
-			probeData[0] = data.Probe1Temp.Float64
-		}
-		if data.Probe2Temp.Valid {
-			probeData[1] = data.Probe2Temp.Float64
-		}
-		if data.Probe3Temp.Valid {
-			probeData[2] = data.Probe3Temp.Float64
-		}
-		if data.Probe4Temp.Valid {
-			probeData[3] = data.Probe4Temp.Float64
-		}
-		if data.Probe5Temp.Valid {
-			probeData[4] = data.Probe5Temp.Float64
-		}
-		if data.Probe6Temp.Valid {
-			probeData[5] = data.Probe6Temp.Float64
+func thermoworksDatumFromDB(data db.ThermoworksDatum) twchart.ThermoworksData {
+	probeData := make([]float64, 6)
+	for i, temp := range []sql.NullFloat64{
+		data.Probe1Temp, data.Probe2Temp, data.Probe3Temp, data.Probe4Temp, data.Probe5Temp, data.Probe6Temp,
+	} {
+		probeData[i] = math.NaN()
+		if temp.Valid {
+			probeData[i] = temp.Float64
 		}
-		td.ProbeData = probeData
-
-		out = append(out, td)
 	}
-	return out
+
+	return twchart.ThermoworksData{Time: data.Timestamp, ProbeData: probeData}
 }
 
 func (c storageAdapter) Get(ctx context.Context, id string) (*SessionResource, error) {
@@ -188,6 +198,24 @@ func (c storageAdapter) Search(ctx context.Context, parentID string, query url.V
 	}
 }
 
+// SearchByDate returns the Sessions with a Date between the YYYY-MM-DD dates, inclusive
+func (c storageAdapter) SearchByDate(ctx context.Context, from, to string) ([]*SessionResource, error) {
+	sessions, err := c.Queries.ListSessionsByDate(ctx, db.ListSessionsByDateParams{FromDate: from, ToDate: to})
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+
+	result := make([]*SessionResource, 0, len(sessions))
+	for _, session := range sessions {
+		sessionResource, err := c.Get(ctx, session.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting session: %w", err)
+		}
+		result = append(result, sessionResource)
+	}
+	return result, nil
+}
+
 // GetTotalCount returns the total number of sessions (optionally filtered by type)
 func (c storageAdapter) GetTotalCount(ctx context.Context, sessionType string) (int64, error) {
 	if sessionType != "" {
@@ -196,131 +224,307 @@ func (c storageAdapter) GetTotalCount(ctx context.Context, sessionType string) (
 	return c.Queries.CountSessions(ctx)
 }
 
+// Set stores the Session and replaces its probes, stages, and events in a transaction, so a failure doesn't leave it
+// partially written. Data isn't loaded by Get, so it is only written when the Session has it and it is different from
+// the stored data. This keeps readings that are stored while the Session is changed
 func (c storageAdapter) Set(ctx context.Context, sessionResource *SessionResource) error {
 	sessionID := string(sessionResource.GetID())
 
-	// Check if session exists
-	_, err := c.Queries.GetSession(ctx, sessionID)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("error checking existing session: %w", err)
-	}
-
-	if err == sql.ErrNoRows {
-		// Create new session
-		_, err = c.Queries.CreateSession(ctx, db.CreateSessionParams{
-			ID:         sessionID,
-			Name:       sessionResource.Session.Name,
-			Type:       string(sessionResource.Session.Type),
-			Date:       sessionResource.Session.Date,
-			StartTime:  sql.NullTime{Time: sessionResource.Session.StartTime, Valid: !sessionResource.Session.StartTime.IsZero()},
-			UploadedAt: sessionResource.Session.UploadedAt,
-		})
-		if err != nil {
-			return fmt.Errorf("error creating session: %w", err)
+	cleaning, err := cleaningOptionsToDB(sessionResource.Session.Cleaning)
+	if err != nil {
+		return err
+	}
+
+	alerts, err := alertRulesToDB(sessionResource.Session.Alerts)
+	if err != nil {
+		return err
+	}
+
+	return c.InTx(ctx, func(tx storage.Tx) error {
+		// Check if session exists
+		_, err := tx.GetSession(ctx, sessionID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error checking existing session: %w", err)
 		}
-	} else {
-		// Update existing session
-		_, err = c.Queries.UpdateSession(ctx, db.UpdateSessionParams{
-			Name:      sessionResource.Session.Name,
-			Type:      string(sessionResource.Session.Type),
-			Date:      sessionResource.Session.Date,
-			StartTime: sql.NullTime{Time: sessionResource.Session.StartTime, Valid: !sessionResource.Session.StartTime.IsZero()},
-			ID:        sessionID,
-		})
-		if err != nil {
-			return fmt.Errorf("error updating session: %w", err)
+		exists := err == nil
+
+		if !exists {
+			// Create new session
+			_, err = tx.CreateSession(ctx, db.CreateSessionParams{
+				ID:         sessionID,
+				Name:       sessionResource.Session.Name,
+				Type:       string(sessionResource.Session.Type),
+				Date:       sessionResource.Session.Date,
+				StartTime:  sql.NullTime{Time: sessionResource.Session.StartTime, Valid: !sessionResource.Session.StartTime.IsZero()},
+				UploadedAt: sessionResource.Session.UploadedAt,
+				Cleaning:   cleaning,
+				Alerts:     alerts,
+			})
+			if err != nil {
+				return fmt.Errorf("error creating session: %w", err)
+			}
+		} else {
+			// Update existing session
+			_, err = tx.UpdateSession(ctx, db.UpdateSessionParams{
+				Name:      sessionResource.Session.Name,
+				Type:      string(sessionResource.Session.Type),
+				Date:      sessionResource.Session.Date,
+				StartTime: sql.NullTime{Time: sessionResource.Session.StartTime, Valid: !sessionResource.Session.StartTime.IsZero()},
+				Cleaning:  cleaning,
+				Alerts:    alerts,
+				ID:        sessionID,
+			})
+			if err != nil {
+				return fmt.Errorf("error updating session: %w", err)
+			}
+
+			// Delete existing related data
+			err = tx.DeleteProbesBySession(ctx, sessionID)
+			if err != nil {
+				return fmt.Errorf("error deleting existing probes: %w", err)
+			}
+			err = tx.DeleteStagesBySession(ctx, sessionID)
+			if err != nil {
+				return fmt.Errorf("error deleting existing stages: %w", err)
+			}
+			err = tx.DeleteEventsBySession(ctx, sessionID)
+			if err != nil {
+				return fmt.Errorf("error deleting existing events: %w", err)
+			}
 		}
 
-		// Delete existing related data
-		err = c.Queries.DeleteProbesBySession(ctx, sessionID)
-		if err != nil {
-			return fmt.Errorf("error deleting existing probes: %w", err)
+		// Insert probes
+		for _, probe := range sessionResource.Session.Probes {
+			_, err = tx.CreateProbe(ctx, db.CreateProbeParams{
+				SessionID: sessionID,
+				Name:      probe.Name,
+				Position:  int64(probe.Position),
+				Target:    sql.NullFloat64{Float64: probe.Target, Valid: probe.Target != 0},
+				Kind:      sql.NullString{String: string(probe.Measurement.Kind), Valid: probe.Measurement.Kind != ""},
+				Unit:      sql.NullString{String: probe.Measurement.Unit, Valid: probe.Measurement.Unit != ""},
+			})
+			if err != nil {
+				return fmt.Errorf("error creating probe: %w", err)
+			}
 		}
-		err = c.Queries.DeleteStagesBySession(ctx, sessionID)
-		if err != nil {
-			return fmt.Errorf("error deleting existing stages: %w", err)
+
+		// Insert stages
+		for _, stage := range sessionResource.Session.Stages {
+			_, err = tx.CreateStage(ctx, db.CreateStageParams{
+				SessionID: sessionID,
+				Name:      stage.Name,
+				Start:     stage.Start,
+				End:       sql.NullTime{Time: stage.End, Valid: !stage.End.IsZero()},
+				Duration:  sql.NullInt64{Int64: int64(stage.Duration), Valid: stage.Duration != 0},
+			})
+			if err != nil {
+				return fmt.Errorf("error creating stage: %w", err)
+			}
 		}
-		err = c.Queries.DeleteEventsBySession(ctx, sessionID)
-		if err != nil {
-			return fmt.Errorf("error deleting existing events: %w", err)
+
+		// Insert events
+		for _, event := range sessionResource.Session.Events {
+			_, err = tx.CreateEvent(ctx, db.CreateEventParams{
+				SessionID: sessionID,
+				Note:      event.Note,
+				Time:      event.Time,
+			})
+			if err != nil {
+				return fmt.Errorf("error creating event: %w", err)
+			}
 		}
-		err = c.Queries.DeleteThermoworksDataBySession(ctx, sessionID)
-		if err != nil {
-			return fmt.Errorf("error deleting existing thermoworks data: %w", err)
+
+		if len(sessionResource.Session.Data) == 0 {
+			return nil
 		}
+		if !exists {
+			return insertThermoworksData(ctx, tx, sessionID, sessionResource.Session.Data)
+		}
+		return replaceThermoworksData(ctx, tx, sessionID, sessionResource.Session.Data)
+	})
+}
+
+// replaceThermoworksData replaces the Session's stored data if it is different from the data
+func replaceThermoworksData(ctx context.Context, tx storage.Tx, sessionID string, data []twchart.ThermoworksData) error {
+	stored, err := tx.GetThermoworksDataBySession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("error getting thermoworks data: %w", err)
 	}
 
-	// Insert probes
-	for _, probe := range sessionResource.Session.Probes {
-		_, err = c.Queries.CreateProbe(ctx, db.CreateProbeParams{
-			SessionID: sessionID,
-			Name:      probe.Name,
-			Position:  int64(probe.Position),
-		})
-		if err != nil {
-			return fmt.Errorf("error creating probe: %w", err)
-		}
+	rows := thermoworksDataToDB(sessionID, data)
+	if sameThermoworksData(stored, rows) {
+		return nil
 	}
 
-	// Insert stages
-	for _, stage := range sessionResource.Session.Stages {
-		_, err = c.Queries.CreateStage(ctx, db.CreateStageParams{
-			SessionID: sessionID,
-			Name:      stage.Name,
-			Start:     stage.Start,
-			End:       sql.NullTime{Time: stage.End, Valid: !stage.End.IsZero()},
-			Duration:  sql.NullInt64{Int64: int64(stage.Duration), Valid: stage.Duration != 0},
-		})
-		if err != nil {
-			return fmt.Errorf("error creating stage: %w", err)
+	err = tx.DeleteThermoworksDataBySession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("error deleting existing thermoworks data: %w", err)
+	}
+
+	err = tx.CreateThermoworksDataBatch(ctx, rows)
+	if err != nil {
+		return fmt.Errorf("error creating thermoworks data: %w", err)
+	}
+	return nil
+}
+
+// sameThermoworksData checks if the rows have the same times and readings as the stored rows
+func sameThermoworksData(stored []db.ThermoworksDatum, rows []db.CreateThermoworksDataParams) bool {
+	if len(stored) != len(rows) {
+		return false
+	}
+
+	for i, row := range rows {
+		s := stored[i]
+		if !s.Timestamp.Equal(row.Timestamp) ||
+			s.Probe1Temp != row.Probe1Temp ||
+			s.Probe2Temp != row.Probe2Temp ||
+			s.Probe3Temp != row.Probe3Temp ||
+			s.Probe4Temp != row.Probe4Temp ||
+			s.Probe5Temp != row.Probe5Temp ||
+			s.Probe6Temp != row.Probe6Temp {
+			return false
 		}
 	}
+	return true
+}
 
-	// Insert events
-	for _, event := range sessionResource.Session.Events {
-		_, err = c.Queries.CreateEvent(ctx, db.CreateEventParams{
-			SessionID: sessionID,
-			Note:      event.Note,
-			Time:      event.Time,
-		})
+// shiftThermoworksData moves the Session's stored data by the offset in a transaction
+func (c storageAdapter) shiftThermoworksData(ctx context.Context, sessionID string, offset time.Duration) error {
+	return c.InTx(ctx, func(tx storage.Tx) error {
+		err := tx.ShiftThermoworksData(ctx, sessionID, offset)
 		if err != nil {
-			return fmt.Errorf("error creating event: %w", err)
+			return fmt.Errorf("error shifting thermoworks data: %w", err)
 		}
+		return nil
+	})
+}
+
+// cleaningOptionsToDB stores CleaningOptions as JSON, or NULL if they are not set
+func cleaningOptionsToDB(opts twchart.CleaningOptions) (sql.NullString, error) {
+	if opts == (twchart.CleaningOptions{}) {
+		return sql.NullString{}, nil
 	}
 
-	// Insert thermoworks data
-	err = c.storeThermoworksData(ctx, sessionID, sessionResource.Session.Data)
+	data, err := json.Marshal(opts)
 	if err != nil {
-		return err
+		return sql.NullString{}, fmt.Errorf("error encoding cleaning options: %w", err)
 	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
 
-	return nil
+// alertRulesToDB stores AlertRules as a JSON list of rule text, or NULL if there are none
+func alertRulesToDB(rules []twchart.AlertRule) (sql.NullString, error) {
+	if len(rules) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("error encoding alerts: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
 }
 
+// storeThermoworksData adds the data to the Session's existing data in a transaction. Rows at existing timestamps are
+// merged into them, like live readings for different probes at the same time
 func (c storageAdapter) storeThermoworksData(ctx context.Context, sessionID string, data []twchart.ThermoworksData) error {
-	for _, data := range data {
-		probeData := make([]sql.NullFloat64, 6)
-		for i, temp := range data.ProbeData {
-			if i < len(probeData) && temp > 0 {
-				probeData[i] = sql.NullFloat64{Float64: temp, Valid: true}
+	return c.InTx(ctx, func(tx storage.Tx) error {
+		err := tx.UpsertThermoworksDataBatch(ctx, thermoworksDataToDB(sessionID, data))
+		if err != nil {
+			return fmt.Errorf("error storing thermoworks data: %w", err)
+		}
+		return nil
+	})
+}
+
+// storeUploadedData combines uploaded data with the Session's stored data using the UploadMode in a transaction
+func (c storageAdapter) storeUploadedData(ctx context.Context, sessionID string, data []twchart.ThermoworksData, mode twchart.UploadMode) (twchart.DataChanges, error) {
+	var changes twchart.DataChanges
+	err := c.InTx(ctx, func(tx storage.Tx) error {
+		var existing []twchart.ThermoworksData
+		if mode != twchart.UploadModeReplace {
+			rows, err := tx.GetThermoworksDataBySession(ctx, sessionID)
+			if err != nil {
+				return fmt.Errorf("error getting thermoworks data: %w", err)
 			}
+			existing = thermoworksDataFromDB(rows)
 		}
 
-		_, err := c.Queries.CreateThermoworksData(ctx, db.CreateThermoworksDataParams{
-			SessionID:  sessionID,
-			Timestamp:  data.Time,
-			Probe1Temp: probeData[0],
-			Probe2Temp: probeData[1],
-			Probe3Temp: probeData[2],
-			Probe4Temp: probeData[3],
-			Probe5Temp: probeData[4],
-			Probe6Temp: probeData[5],
-		})
+		changes = twchart.PlanDataChanges(existing, data, mode)
+		if changes.Mode == twchart.UploadModeReplace {
+			err := tx.DeleteThermoworksDataBySession(ctx, sessionID)
+			if err != nil {
+				return fmt.Errorf("error deleting existing thermoworks data: %w", err)
+			}
+		}
+
+		err := insertThermoworksData(ctx, tx, sessionID, changes.Insert)
 		if err != nil {
-			return fmt.Errorf("error creating thermoworks data: %w", err)
+			return err
+		}
+
+		err = tx.UpsertThermoworksDataBatch(ctx, thermoworksDataToDB(sessionID, changes.Update))
+		if err != nil {
+			return fmt.Errorf("error updating thermoworks data: %w", err)
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// insertThermoworksData inserts the data in batches as part of the transaction
+func insertThermoworksData(ctx context.Context, tx storage.Tx, sessionID string, data []twchart.ThermoworksData) error {
+	err := tx.CreateThermoworksDataBatch(ctx, thermoworksDataToDB(sessionID, data))
+	if err != nil {
+		return fmt.Errorf("error creating thermoworks data: %w", err)
+	}
+	return nil
+}
+
+func thermoworksDataToDB(sessionID string, data []twchart.ThermoworksData) []db.CreateThermoworksDataParams {
+	rows := make([]db.CreateThermoworksDataParams, 0, len(data))
+	for _, d := range data {
+		rows = append(rows, thermoworksDatumToDB(sessionID, d))
+	}
+	return rows
+}
+
+func thermoworksDatumToDB(sessionID string, data twchart.ThermoworksData) db.CreateThermoworksDataParams {
+	probeData := make([]sql.NullFloat64, 6)
+	for i, temp := range data.ProbeData {
+		if i < len(probeData) && !math.IsNaN(temp) {
+			probeData[i] = sql.NullFloat64{Float64: temp, Valid: true}
 		}
 	}
 
+	return db.CreateThermoworksDataParams{
+		SessionID:  sessionID,
+		Timestamp:  data.Time,
+		Probe1Temp: probeData[0],
+		Probe2Temp: probeData[1],
+		Probe3Temp: probeData[2],
+		Probe4Temp: probeData[3],
+		Probe5Temp: probeData[4],
+		Probe6Temp: probeData[5],
+	}
+}
+
+// moveEvent changes the time of the Session's stored Event with the note and time. Nothing else is written, so it
+// doesn't need the rest of the Session
+func (c storageAdapter) moveEvent(ctx context.Context, sessionID, note string, from, to time.Time) error {
+	moved, err := c.Queries.UpdateEventTime(ctx, db.UpdateEventTimeParams{
+		ToTime:    to,
+		SessionID: sessionID,
+		Note:      note,
+		FromTime:  from,
+	})
+	if err != nil {
+		return fmt.Errorf("error moving event: %w", err)
+	}
+	if moved == 0 {
+		return fmt.Errorf("%w: %q at %s", twchart.ErrEventNotFound, note, from.Format(time.Kitchen))
+	}
 	return nil
 }
 
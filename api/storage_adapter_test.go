@@ -0,0 +1,424 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/calvinmclean/twchart/storage"
+	"github.com/calvinmclean/twchart/storage/db"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage creates a migrated SQLite DB in a temporary directory
+func newTestStorage(tb testing.TB) (storageAdapter, string) {
+	tb.Helper()
+
+	filename := filepath.Join(tb.TempDir(), "twchart.db")
+	m, err := migrate.New("file://../migrations", "sqlite3://"+filename)
+	require.NoError(tb, err)
+	require.NoError(tb, m.Up())
+	_, _ = m.Close()
+
+	client, err := storage.New(filename)
+	require.NoError(tb, err)
+	tb.Cleanup(client.Close)
+
+	return storageAdapter{client}, filename
+}
+
+func testThermoworksData(start time.Time, n int) []twchart.ThermoworksData {
+	data := make([]twchart.ThermoworksData, 0, n)
+	for i := range n {
+		data = append(data, twchart.ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Second),
+			ProbeData: []float64{70 + float64(i)/100, 200, 0, 0, 0, 0},
+		})
+	}
+	return data
+}
+
+func newTestSessionResource(start time.Time, dataRows int) *SessionResource {
+	return &SessionResource{Session: twchart.Session{
+		ID:         babyapi.ID{ID: xid.New()},
+		Name:       "Brisket",
+		Date:       start.Truncate(24 * time.Hour),
+		StartTime:  start,
+		UploadedAt: start,
+		Probes:     []twchart.Probe{{Name: "Meat", Position: twchart.ProbePosition1}, {Name: "Pit", Position: twchart.ProbePosition2}},
+		Stages:     []twchart.Stage{{Name: "Cook", Start: start}},
+		Events:     []twchart.Event{{Note: "Wrapped", Time: start.Add(time.Hour)}},
+		Data:       testThermoworksData(start, dataRows),
+	}}
+}
+
+func TestStorageAdapterSet(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	adapter, filename := newTestStorage(t)
+
+	sr := newTestSessionResource(start, 250)
+	require.NoError(t, adapter.Set(ctx, sr))
+
+	data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+	require.NoError(t, err)
+	require.Len(t, data, 250)
+	assert.Equal(t, sr.Session.Data, thermoworksDataFromDB(data))
+
+	t.Run("Replace", func(t *testing.T) {
+		sr.Session.Data = testThermoworksData(start, 3)
+		sr.Session.Events = append(sr.Session.Events, twchart.Event{Note: "Rested", Time: start.Add(2 * time.Hour)})
+		require.NoError(t, adapter.Set(ctx, sr))
+
+		got, err := adapter.Get(ctx, sr.GetID())
+		require.NoError(t, err)
+		assert.Len(t, got.Session.Events, 2)
+
+		data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+		require.NoError(t, err)
+		assert.Len(t, data, 3)
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		database, err := sql.Open("sqlite3", filename)
+		require.NoError(t, err)
+		defer database.Close()
+		_, err = database.Exec(`CREATE TRIGGER fail_events BEFORE INSERT ON events BEGIN SELECT RAISE(ABORT, 'no events'); END`)
+		require.NoError(t, err)
+		defer func() {
+			_, err = database.Exec(`DROP TRIGGER fail_events`)
+			require.NoError(t, err)
+		}()
+
+		updated := *sr
+		updated.Session.Name = "Renamed"
+		updated.Session.Data = testThermoworksData(start, 10)
+		err = adapter.Set(ctx, &updated)
+		require.ErrorContains(t, err, "no events")
+
+		// nothing was changed or deleted
+		got, err := adapter.Get(ctx, sr.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, "Brisket", got.Session.Name)
+		assert.Len(t, got.Session.Probes, 2)
+		assert.Len(t, got.Session.Events, 2)
+
+		data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+		require.NoError(t, err)
+		assert.Len(t, data, 3)
+	})
+
+	storedIDs := func(t *testing.T) []int64 {
+		data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+		require.NoError(t, err)
+		var ids []int64
+		for _, d := range data {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	t.Run("UnchangedData", func(t *testing.T) {
+		ids := storedIDs(t)
+		require.Len(t, ids, 3)
+
+		sr.Session.Data = testThermoworksData(start, 3)
+		require.NoError(t, adapter.Set(ctx, sr))
+		assert.Equal(t, ids, storedIDs(t))
+	})
+
+	t.Run("WithoutData", func(t *testing.T) {
+		// a reading stored after the Session was read is kept when the Session is stored without its data
+		got, err := adapter.Get(ctx, sr.GetID())
+		require.NoError(t, err)
+		require.NoError(t, adapter.storeThermoworksData(ctx, sr.GetID(), testThermoworksData(start.Add(time.Minute), 1)))
+
+		got.Session.Name = "Renamed"
+		require.NoError(t, adapter.Set(ctx, got))
+		assert.Len(t, storedIDs(t), 4)
+
+		got, err = adapter.Get(ctx, sr.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Session.Name)
+	})
+}
+
+func TestStorageAdapterShiftThermoworksData(t *testing.T) {
+	ctx := context.Background()
+	mst := time.FixedZone("MST", -7*60*60)
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, mst)
+	adapter, _ := newTestStorage(t)
+
+	sr := newTestSessionResource(start, 10)
+	sr.Session.Data[3].Time = sr.Session.Data[3].Time.Add(500 * time.Millisecond)
+	require.NoError(t, adapter.Set(ctx, sr))
+
+	storedTimes := func(t *testing.T) []time.Time {
+		data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+		require.NoError(t, err)
+		var times []time.Time
+		for _, d := range data {
+			times = append(times, d.Timestamp)
+		}
+		return times
+	}
+
+	for _, offset := range []time.Duration{time.Second, -2 * time.Second, time.Hour} {
+		t.Run(offset.String(), func(t *testing.T) {
+			// rows move onto each other's times, which doesn't conflict
+			require.NoError(t, adapter.shiftThermoworksData(ctx, sr.GetID(), offset))
+			sr.Session.ShiftData(offset)
+
+			times := storedTimes(t)
+			require.Len(t, times, 10)
+			for i, d := range sr.Session.Data {
+				assert.Truef(t, d.Time.Equal(times[i]), "expected %s, got %s", d.Time, times[i])
+
+				_, zoneOffset := times[i].Zone()
+				assert.Equal(t, -7*60*60, zoneOffset)
+			}
+		})
+	}
+}
+
+func TestStorageAdapterMoveEvent(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.FixedZone("MST", -7*60*60))
+	adapter, _ := newTestStorage(t)
+
+	sr := newTestSessionResource(start, 10)
+	sr.Session.Events = append(sr.Session.Events, twchart.Event{Note: "Rested", Time: start.Add(2 * time.Hour)})
+	require.NoError(t, adapter.Set(ctx, sr))
+
+	data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+	require.NoError(t, err)
+
+	// the Event is found by its time even when it is from another time zone
+	err = adapter.moveEvent(ctx, sr.GetID(), "Wrapped", start.Add(time.Hour).UTC(), start.Add(3*time.Hour))
+	require.NoError(t, err)
+
+	got, err := adapter.Get(ctx, sr.GetID())
+	require.NoError(t, err)
+	require.Len(t, got.Session.Events, 2)
+	assert.Equal(t, "Rested", got.Session.Events[0].Note)
+	assert.Equal(t, "Wrapped", got.Session.Events[1].Note)
+	assert.True(t, start.Add(3*time.Hour).Equal(got.Session.Events[1].Time))
+
+	// data isn't written again
+	after, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+
+	t.Run("NotFound", func(t *testing.T) {
+		err := adapter.moveEvent(ctx, sr.GetID(), "Wrapped", start.Add(time.Hour), start)
+		require.ErrorIs(t, err, twchart.ErrEventNotFound)
+	})
+}
+
+func TestStorageAdapterStoreUploadedData(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	adapter, _ := newTestStorage(t)
+
+	sr := newTestSessionResource(start, 0)
+	require.NoError(t, adapter.Set(ctx, sr))
+
+	storedData := func(t *testing.T) []twchart.ThermoworksData {
+		data, err := adapter.GetThermoworksDataBySession(ctx, sr.GetID())
+		require.NoError(t, err)
+		return thermoworksDataFromDB(data)
+	}
+
+	upload := testThermoworksData(start, 150)
+	changes, err := adapter.storeUploadedData(ctx, sr.GetID(), upload, twchart.UploadModeAppend)
+	require.NoError(t, err)
+	assert.Len(t, changes.Insert, 150)
+
+	t.Run("AppendAgain", func(t *testing.T) {
+		changes, err := adapter.storeUploadedData(ctx, sr.GetID(), upload, twchart.UploadModeAppend)
+		require.NoError(t, err)
+		assert.Empty(t, changes.Insert)
+		assert.Equal(t, 150, changes.Unchanged)
+		assert.Len(t, storedData(t), 150)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		// the first 50 rows overlap the existing data, but only the first one changes a reading
+		upload := testThermoworksData(start, 200)[100:]
+		upload[0].ProbeData = []float64{99, math.NaN()}
+
+		changes, err := adapter.storeUploadedData(ctx, sr.GetID(), upload, twchart.UploadModeMerge)
+		require.NoError(t, err)
+		assert.Len(t, changes.Insert, 50)
+		assert.Len(t, changes.Update, 1)
+		assert.Equal(t, 49, changes.Unchanged)
+
+		data := storedData(t)
+		require.Len(t, data, 200)
+		assert.Equal(t, []float64{99, 200, 0, 0, 0, 0}, data[100].ProbeData)
+	})
+
+	t.Run("RepeatedTimestamp", func(t *testing.T) {
+		upload := testThermoworksData(start.Add(time.Hour), 3)
+		upload = append(upload, twchart.ThermoworksData{Time: upload[0].Time, ProbeData: []float64{math.NaN(), math.NaN(), 150}})
+
+		changes, err := adapter.storeUploadedData(ctx, sr.GetID(), upload, twchart.UploadModeMerge)
+		require.NoError(t, err)
+		assert.Len(t, changes.Insert, 3)
+		assert.Equal(t, 1, changes.Unchanged)
+
+		data := storedData(t)
+		require.Len(t, data, 203)
+		assert.Equal(t, []float64{70, 200, 150, 0, 0, 0}, data[200].ProbeData)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		changes, err := adapter.storeUploadedData(ctx, sr.GetID(), testThermoworksData(start, 5), twchart.UploadModeReplace)
+		require.NoError(t, err)
+		assert.Len(t, changes.Insert, 5)
+		assert.Len(t, storedData(t), 5)
+	})
+
+	t.Run("UniqueTimestamp", func(t *testing.T) {
+		err := adapter.InTx(ctx, func(tx storage.Tx) error {
+			return insertThermoworksData(ctx, tx, sr.GetID(), testThermoworksData(start, 1))
+		})
+		require.ErrorContains(t, err, "UNIQUE constraint failed")
+		assert.Len(t, storedData(t), 5)
+	})
+
+	t.Run("LiveReadings", func(t *testing.T) {
+		// readings for another probe at an existing time are merged into its row
+		err := adapter.storeThermoworksData(ctx, sr.GetID(), []twchart.ThermoworksData{{Time: start, ProbeData: []float64{math.NaN(), math.NaN(), 150}}})
+		require.NoError(t, err)
+
+		data := storedData(t)
+		require.Len(t, data, 5)
+		assert.Equal(t, []float64{70, 200, 150, 0, 0, 0}, data[0].ProbeData)
+
+		// the same instant from another time zone is merged too
+		mst := start.In(time.FixedZone("MST", -7*60*60))
+		err = adapter.storeThermoworksData(ctx, sr.GetID(), []twchart.ThermoworksData{{Time: mst, ProbeData: []float64{math.NaN(), math.NaN(), math.NaN(), 40}}})
+		require.NoError(t, err)
+
+		data = storedData(t)
+		require.Len(t, data, 5)
+		assert.Equal(t, []float64{70, 200, 150, 40, 0, 0}, data[0].ProbeData)
+	})
+}
+
+func TestStreamThermoworksDataBySessionBetween(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	adapter, _ := newTestStorage(t)
+
+	data := testThermoworksData(start, 10)
+	// the same instant in another time zone is compared by time instead of as text
+	data[5].Time = data[5].Time.In(time.FixedZone("MST", -7*60*60))
+	require.NoError(t, adapter.storeThermoworksData(ctx, "session", data))
+
+	streamTimes := func(from, to time.Time) []time.Time {
+		var result []time.Time
+		params := db.GetThermoworksDataBySessionBetweenParams{SessionID: "session", FromTime: from, ToTime: to}
+		for datum, err := range adapter.StreamThermoworksDataBySessionBetween(ctx, params) {
+			require.NoError(t, err)
+			result = append(result, datum.Timestamp.UTC())
+		}
+		return result
+	}
+
+	assert.Equal(t, []time.Time{start.Add(4 * time.Second), start.Add(5 * time.Second), start.Add(6 * time.Second)},
+		streamTimes(start.Add(4*time.Second), start.Add(6*time.Second)))
+	assert.Len(t, streamTimes(time.Time{}, maxDataTime), 10)
+	assert.Empty(t, streamTimes(start.Add(time.Hour), maxDataTime))
+}
+
+func TestMigrateUniqueThermoworksDataTimestamp(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	filename := filepath.Join(t.TempDir(), "twchart.db")
+
+	m, err := migrate.New("file://../migrations", "sqlite3://"+filename)
+	require.NoError(t, err)
+	defer m.Close()
+	require.NoError(t, m.Migrate(6))
+
+	database, err := sql.Open("sqlite3", filename)
+	require.NoError(t, err)
+	defer database.Close()
+
+	_, err = database.Exec(`INSERT INTO sessions (id, name, date, uploaded_at) VALUES ('session', 'Brisket', ?, ?)`, start, start)
+	require.NoError(t, err)
+
+	// live readings for each probe used to be stored in their own rows
+	for _, row := range []struct {
+		time   time.Time
+		probes [3]any
+	}{
+		{start, [3]any{70.0, nil, nil}},
+		{start, [3]any{nil, 225.0, nil}},
+		{start, [3]any{71.0, nil, nil}},
+		// the same instant written from another time zone
+		{start.In(time.FixedZone("MST", -7*60*60)), [3]any{nil, nil, 40.0}},
+		{start.Add(time.Second), [3]any{72.0, 226.0, nil}},
+	} {
+		_, err = database.Exec(
+			`INSERT INTO thermoworks_data (session_id, timestamp, probe1_temp, probe2_temp, probe3_temp) VALUES ('session', ?, ?, ?, ?)`,
+			row.time, row.probes[0], row.probes[1], row.probes[2],
+		)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, m.Up())
+
+	client, err := storage.New(filename)
+	require.NoError(t, err)
+	defer client.Close()
+
+	data, err := client.GetThermoworksDataBySession(ctx, "session")
+	require.NoError(t, err)
+	// missing readings are NaN, so they are compared as JSON
+	out, err := json.Marshal(thermoworksDataFromDB(data))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"Time": "2025-05-21T08:00:00Z", "ProbeData": [70, 225, 40, null, null, null]},
+		{"Time": "2025-05-21T08:00:01Z", "ProbeData": [72, 226, null, null, null, null]}
+	]`, string(out))
+}
+
+func BenchmarkStoreThermoworksData(b *testing.B) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	data := testThermoworksData(start, 10_000)
+
+	b.Run("Batched", func(b *testing.B) {
+		adapter, _ := newTestStorage(b)
+		for b.Loop() {
+			err := adapter.storeThermoworksData(ctx, xid.New().String(), data)
+			require.NoError(b, err)
+		}
+	})
+
+	// OneRowAtATime is how data was stored before it was batched
+	b.Run("OneRowAtATime", func(b *testing.B) {
+		adapter, _ := newTestStorage(b)
+		for b.Loop() {
+			sessionID := xid.New().String()
+			for _, d := range data {
+				_, err := adapter.CreateThermoworksData(ctx, thermoworksDatumToDB(sessionID, d))
+				require.NoError(b, err)
+			}
+		}
+	})
+}
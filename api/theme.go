@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/calvinmclean/twchart"
+)
+
+// LoadThemes reads the chart Theme for each SessionType from a JSON file like:
+//
+//	{"bread": {"StageColorsByName": {"Bake": "rgba(255, 165, 0, 0.4)"}}}
+func (a *API) LoadThemes(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading themes: %w", err)
+	}
+
+	var themes twchart.Themes
+	err = json.Unmarshal(data, &themes)
+	if err != nil {
+		return fmt.Errorf("error parsing themes: %w", err)
+	}
+
+	a.Themes = themes
+	return nil
+}
+
+// themeFromRequest gets the Session's Theme using the theme query parameter to choose the light or dark variant
+func (a *API) themeFromRequest(r *http.Request, session twchart.Session) (*twchart.Theme, error) {
+	var dark bool
+	switch mode := r.URL.Query().Get("theme"); mode {
+	case "", "light":
+	case "dark":
+		dark = true
+	default:
+		return nil, fmt.Errorf("invalid theme parameter: %q", mode)
+	}
+
+	theme := a.Themes.Get(session.Type, dark)
+	return &theme, nil
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/twchart"
+	"github.com/go-chi/render"
+	"github.com/go-echarts/go-echarts/v2/charts"
+)
+
+// maxTimelineDays limits how many days a timeline can include
+const maxTimelineDays = 31
+
+// timelineSession is a Session's Stages and Events in the JSON timeline response
+type timelineSession struct {
+	ID     string
+	Name   string
+	Type   twchart.SessionType
+	Stages []twchart.Stage
+	Events []twchart.Event
+}
+
+type timelineResponse struct {
+	*babyapi.DefaultRenderer
+	From     string `json:",omitempty"`
+	To       string `json:",omitempty"`
+	Sessions []timelineSession
+}
+
+func newTimelineResponse(sessions []twchart.Session) timelineResponse {
+	resp := timelineResponse{Sessions: []timelineSession{}}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, timelineSession{
+			ID:     s.ID.String(),
+			Name:   s.Name,
+			Type:   s.Type,
+			Stages: s.Stages,
+			Events: s.Events,
+		})
+	}
+	return resp
+}
+
+// timelineDatesFromRequest reads the from and to dates (YYYY-MM-DD). If only from is set, the timeline is for that
+// date. If neither is set, it is for today
+func timelineDatesFromRequest(r *http.Request, now time.Time) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+
+	parse := func(name string) (time.Time, error) {
+		date, err := time.ParseInLocation(time.DateOnly, query.Get(name), time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s parameter: %w", name, err)
+		}
+		return date, nil
+	}
+
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var err error
+	if query.Get("from") != "" {
+		from, err = parse("from")
+		if err != nil {
+			return from, from, err
+		}
+	}
+
+	to := from
+	if query.Get("to") != "" {
+		to, err = parse("to")
+		if err != nil {
+			return from, to, err
+		}
+	}
+
+	if to.Before(from) {
+		return from, to, errors.New("invalid to parameter: before from")
+	}
+	if to.Sub(from) > maxTimelineDays*24*time.Hour {
+		return from, to, fmt.Errorf("invalid date range: more than %d days", maxTimelineDays)
+	}
+
+	return from, to, nil
+}
+
+// sessionsByDate gets the Sessions with a Date from the first date to the last, inclusive
+func (a *API) sessionsByDate(ctx context.Context, from, to time.Time) ([]twchart.Session, error) {
+	fromDate, toDate := from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	var sessions []twchart.Session
+	if a.storageAdapter.Client != nil {
+		resources, err := a.storageAdapter.SearchByDate(ctx, fromDate, toDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, sr := range resources {
+			sessions = append(sessions, sr.Session)
+		}
+		return sessions, nil
+	}
+
+	for sr, err := range a.API.Storage.Search(ctx, "", nil) {
+		if err != nil {
+			return nil, err
+		}
+		date := sr.Session.Date.Format(time.DateOnly)
+		if date >= fromDate && date <= toDate {
+			sessions = append(sessions, sr.Session)
+		}
+	}
+	return sessions, nil
+}
+
+// lastReadings gets the time of the last reading for each Session with an unfinished Stage. Sessions read from the DB
+// don't have their Data loaded, so only the time of the last row is read instead
+func (a *API) lastReadings(ctx context.Context, sessions []twchart.Session) (map[string]time.Time, error) {
+	if a.storageAdapter.Client == nil {
+		return nil, nil
+	}
+
+	lastReadings := map[string]time.Time{}
+	for _, s := range sessions {
+		unfinished := slices.ContainsFunc(s.Stages, func(stage twchart.Stage) bool {
+			return stage.End.IsZero()
+		})
+		if !unfinished || len(s.Data) > 0 {
+			continue
+		}
+
+		last, err := a.storageAdapter.Client.GetLatestThermoworksDataTime(ctx, s.ID.String())
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error getting last reading: %w", err)
+		}
+		lastReadings[s.ID.String()] = last
+	}
+	return lastReadings, nil
+}
+
+// sessionTimeline shows the Session's Stages as a Gantt chart
+func (a *API) sessionTimeline(w http.ResponseWriter, r *http.Request, sr *SessionResource) (render.Renderer, *babyapi.ErrResponse) {
+	if render.GetAcceptedContentType(r) != render.ContentTypeHTML {
+		return newTimelineResponse([]twchart.Session{sr.Session}), nil
+	}
+
+	theme, err := a.themeFromRequest(r, sr.Session)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(err)
+	}
+
+	lastReadings, err := a.lastReadings(r.Context(), []twchart.Session{sr.Session})
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	chart := sr.Session.Timeline(twchart.TimelineOptions{Themes: a.Themes, Dark: theme.Dark, LastReadings: lastReadings})
+	return renderTimeline(r, chart, timelineViewData{
+		Title:   sr.Session.Name,
+		BackURL: fmt.Sprintf("/sessions/%s", sr.GetID()),
+		Dark:    theme.Dark,
+	}), nil
+}
+
+// sessionsTimeline shows the Stages of every Session in a range of dates on one timeline
+func (a *API) sessionsTimeline(w http.ResponseWriter, r *http.Request) render.Renderer {
+	from, to, err := timelineDatesFromRequest(r, time.Now())
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	sessions, err := a.sessionsByDate(r.Context(), from, to)
+	if err != nil {
+		return babyapi.InternalServerError(err)
+	}
+
+	if render.GetAcceptedContentType(r) != render.ContentTypeHTML {
+		resp := newTimelineResponse(sessions)
+		resp.From = from.Format(time.DateOnly)
+		resp.To = to.Format(time.DateOnly)
+		return resp
+	}
+
+	theme, err := a.themeFromRequest(r, twchart.Session{})
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	lastReadings, err := a.lastReadings(r.Context(), sessions)
+	if err != nil {
+		return babyapi.InternalServerError(err)
+	}
+
+	chart := twchart.SessionsTimeline(sessions, twchart.TimelineOptions{Themes: a.Themes, Dark: theme.Dark, LastReadings: lastReadings})
+	return renderTimeline(r, chart, timelineViewData{
+		Title:     "Timeline",
+		BackURL:   "/sessions",
+		Dark:      theme.Dark,
+		DateRange: true,
+		From:      from.Format(time.DateOnly),
+		To:        to.Format(time.DateOnly),
+		Empty:     len(sessions) == 0,
+	})
+}
+
+// timelineViewData holds the data for rendering the timelineView template
+type timelineViewData struct {
+	Title    string
+	BackURL  string
+	Element  template.HTML
+	Script   template.HTML
+	Dark     bool
+	ThemeURL string
+	// DateRange shows the form to choose the dates of a timeline with multiple Sessions
+	DateRange bool
+	From      string
+	To        string
+	Empty     bool
+}
+
+func renderTimeline(r *http.Request, chart *charts.Custom, data timelineViewData) render.Renderer {
+	snippet := chart.RenderSnippet()
+	data.Element = template.HTML(snippet.Element)
+	data.Script = template.HTML(snippet.Script)
+
+	themeQuery := r.URL.Query()
+	themeQuery.Set("theme", "dark")
+	if data.Dark {
+		themeQuery.Set("theme", "light")
+	}
+	data.ThemeURL = (&url.URL{Path: r.URL.Path, RawQuery: themeQuery.Encode()}).String()
+
+	return timelineView.Renderer(data)
+}
+
+// apiContextMiddleware adds the API to the request context for routes outside of /sessions, so their templates can
+// use it
+func (a *API) apiContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKey, a)))
+	})
+}
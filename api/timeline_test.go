@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/twchart"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimelineDatesFromRequest(t *testing.T) {
+	now := time.Date(2025, time.May, 21, 15, 4, 5, 0, time.Local)
+
+	tests := []struct {
+		query        string
+		expectedFrom string
+		expectedTo   string
+		expectedErr  string
+	}{
+		{"", "2025-05-21", "2025-05-21", ""},
+		{"from=2025-05-01", "2025-05-01", "2025-05-01", ""},
+		{"from=2025-05-01&to=2025-05-07", "2025-05-01", "2025-05-07", ""},
+		{"to=2025-05-28", "2025-05-21", "2025-05-28", ""},
+		{"from=May", "", "", "invalid from parameter"},
+		{"from=2025-05-07&to=2025-05-01", "", "", "invalid to parameter: before from"},
+		{"from=2025-01-01&to=2025-05-01", "", "", "invalid date range: more than 31 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/timeline?"+tt.query, nil)
+			from, to, err := timelineDatesFromRequest(r, now)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFrom, from.Format(time.DateOnly))
+			assert.Equal(t, tt.expectedTo, to.Format(time.DateOnly))
+		})
+	}
+}
+
+func TestLastReadings(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	adapter, _ := newTestStorage(t)
+	api := New()
+	api.storageAdapter = adapter
+
+	open := newTestSessionResource(start, 250)
+	require.NoError(t, adapter.Set(ctx, open))
+	finished := newTestSessionResource(start, 10)
+	finished.Session.Stages[0].End = start.Add(time.Hour)
+	require.NoError(t, adapter.Set(ctx, finished))
+	noData := newTestSessionResource(start, 0)
+	require.NoError(t, adapter.Set(ctx, noData))
+
+	var sessions []twchart.Session
+	for _, sr := range []*SessionResource{open, finished, noData} {
+		got, err := adapter.Get(ctx, sr.GetID())
+		require.NoError(t, err)
+		require.Empty(t, got.Session.Data)
+		sessions = append(sessions, got.Session)
+	}
+
+	// only the Session with an unfinished Stage and data has a last reading
+	lastReadings, err := api.lastReadings(ctx, sessions)
+	require.NoError(t, err)
+	require.Len(t, lastReadings, 1)
+	assert.True(t, start.Add(249*time.Second).Equal(lastReadings[open.GetID()]))
+}
@@ -0,0 +1,254 @@
+package twchart
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStallMinTemp     = 145
+	defaultStallMaxTemp     = 180
+	defaultStallMaxRate     = 3
+	defaultStallWindow      = 15 * time.Minute
+	defaultStallMinDuration = 30 * time.Minute
+
+	stallStageName = "Stall"
+)
+
+// ambientProbeNames are used to identify probes that measure the cooker instead of the meat
+var ambientProbeNames = []string{"ambient", "pit", "smoker", "grill", "oven"}
+
+// IsAmbient is true if the Probe's name shows that it measures the cooker's temperature instead of the food
+func (p Probe) IsAmbient() bool {
+	name := strings.ToLower(p.Name)
+	for _, ambient := range ambientProbeNames {
+		if strings.Contains(name, ambient) {
+			return true
+		}
+	}
+	return false
+}
+
+// StallOptions configures how a stall is detected. Zero values use the defaults
+type StallOptions struct {
+	// MinTemp and MaxTemp are the temperature window where a stall can happen. Defaults to 145-180
+	MinTemp float64
+	MaxTemp float64
+
+	// MaxRate is the highest rate of change, in degrees per hour, that is still considered a stall. Defaults to 3
+	MaxRate float64
+	// Window is the time around each reading used to calculate the rate of change. Defaults to 15m
+	Window time.Duration
+	// MinDuration is how long the rate has to stay low to be a stall. Defaults to 30m
+	MinDuration time.Duration
+}
+
+func (o StallOptions) withDefaults() StallOptions {
+	if o.MinTemp == 0 {
+		o.MinTemp = defaultStallMinTemp
+	}
+	if o.MaxTemp == 0 {
+		o.MaxTemp = defaultStallMaxTemp
+	}
+	if o.MaxRate == 0 {
+		o.MaxRate = defaultStallMaxRate
+	}
+	if o.Window == 0 {
+		o.Window = defaultStallWindow
+	}
+	if o.MinDuration == 0 {
+		o.MinDuration = defaultStallMinDuration
+	}
+	return o
+}
+
+// Stall is a plateau in a meat probe's temperature
+type Stall struct {
+	Probe    Probe
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+
+	StartTemp float64
+	EndTemp   float64
+
+	// Wrap is the first Event with "wrap" in its note during the stall or shortly after it ends. WrapAfter is the time
+	// from the start of the stall to the Wrap
+	Wrap      *Event
+	WrapAfter time.Duration
+}
+
+// Stage creates a synthetic Stage for the stall so it can be shown on the chart
+func (st Stall) Stage() Stage {
+	return Stage{
+		Name:     stallStageName,
+		Start:    st.Start,
+		End:      st.End,
+		Duration: st.Duration,
+	}
+}
+
+// Carryover is the temperature rise of a meat probe during a rest
+type Carryover struct {
+	Probe Probe
+	Stage Stage
+
+	StartTemp float64
+	PeakTemp  float64
+	PeakTime  time.Time
+	Rise      float64
+}
+
+// BBQAnalysis has the stalls and carryover cooking for a BBQ Session
+type BBQAnalysis struct {
+	Stalls    []Stall
+	Carryover []Carryover
+}
+
+// AnalyzeBBQ finds the stalls and carryover cooking in the Session's meat probes
+func (s Session) AnalyzeBBQ(stallOpts StallOptions) BBQAnalysis {
+	return BBQAnalysis{
+		Stalls:    s.DetectStalls(stallOpts),
+		Carryover: s.Carryover(),
+	}
+}
+
+// MeatProbes returns all of the Session's probes that aren't measuring the cooker's temperature
+func (s Session) MeatProbes() []Probe {
+	var result []Probe
+	for _, p := range s.Probes {
+		if !p.IsAmbient() {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// DetectStalls finds periods where a meat probe's temperature is within the StallOptions temperature window and its
+// rate of change stays below MaxRate for at least MinDuration. Missing readings end a stall
+func (s Session) DetectStalls(stallOpts StallOptions) []Stall {
+	stallOpts = stallOpts.withDefaults()
+	data := s.CleanData()
+
+	stalls := []Stall{}
+	for _, probe := range s.MeatProbes() {
+		for _, stall := range detectProbeStalls(ProbeSeries(data, probe.Position), stallOpts) {
+			stall.Probe = probe
+			stall.Wrap, stall.WrapAfter = s.findWrap(stall, stallOpts.Window)
+			stalls = append(stalls, stall)
+		}
+	}
+
+	return stalls
+}
+
+func detectProbeStalls(readings []Point, stallOpts StallOptions) []Stall {
+	var stalls []Stall
+	var current *Stall
+
+	finish := func() {
+		if current != nil && current.Duration >= stallOpts.MinDuration {
+			stalls = append(stalls, *current)
+		}
+		current = nil
+	}
+
+	// The rate is calculated over a window centered on each reading so the stall's start and end don't lag
+	halfWindow := stallOpts.Window / 2
+	prev, next := 0, 0
+	for i, r := range readings {
+		if !r.Valid() || r.Value < stallOpts.MinTemp || r.Value > stallOpts.MaxTemp {
+			finish()
+			continue
+		}
+
+		// find the earliest and latest valid readings within the window
+		for prev < i && (!readings[prev].Valid() || r.Time.Sub(readings[prev].Time) > halfWindow) {
+			prev++
+		}
+		next = max(next, i)
+		for next < len(readings)-1 && readings[next+1].Valid() && readings[next+1].Time.Sub(r.Time) <= halfWindow {
+			next++
+		}
+
+		elapsed := readings[next].Time.Sub(readings[prev].Time)
+		if elapsed < halfWindow {
+			continue
+		}
+
+		rate := (readings[next].Value - readings[prev].Value) / elapsed.Hours()
+		if math.Abs(rate) > stallOpts.MaxRate {
+			finish()
+			continue
+		}
+
+		if current == nil {
+			current = &Stall{Start: readings[prev].Time, StartTemp: readings[prev].Value}
+		}
+		current.End = readings[next].Time
+		current.EndTemp = readings[next].Value
+		current.Duration = current.End.Sub(current.Start)
+	}
+	finish()
+
+	return stalls
+}
+
+// findWrap returns the first "wrap" Event between the start of the stall and the window after it ends
+func (s Session) findWrap(stall Stall, window time.Duration) (*Event, time.Duration) {
+	for _, e := range s.Events {
+		if !strings.Contains(strings.ToLower(e.Note), "wrap") {
+			continue
+		}
+		if e.Time.Before(stall.Start) || e.Time.After(stall.End.Add(window)) {
+			continue
+		}
+		return &e, e.Time.Sub(stall.Start)
+	}
+	return nil, 0
+}
+
+// Carryover calculates how much each meat probe's temperature rises during any Stage with "rest" in its name
+func (s Session) Carryover() []Carryover {
+	data := s.CleanData()
+
+	result := []Carryover{}
+	for _, stage := range s.Stages {
+		if !strings.Contains(strings.ToLower(stage.Name), "rest") {
+			continue
+		}
+
+		for _, probe := range s.MeatProbes() {
+			carryover, ok := calculateCarryover(data, probe, stage)
+			if ok {
+				result = append(result, carryover)
+			}
+		}
+	}
+
+	return result
+}
+
+func calculateCarryover(data []ThermoworksData, probe Probe, stage Stage) (Carryover, bool) {
+	result := Carryover{Probe: probe, Stage: stage}
+	found := false
+	for _, d := range data {
+		if d.Time.Before(stage.Start) || (!stage.End.IsZero() && d.Time.After(stage.End)) || !d.HasProbeData(probe.Position) {
+			continue
+		}
+
+		value := d.GetProbeData(probe.Position)
+		if !found {
+			result.StartTemp = value
+			found = true
+		}
+		if value > result.PeakTemp {
+			result.PeakTemp = value
+			result.PeakTime = d.Time
+		}
+	}
+
+	result.Rise = result.PeakTemp - result.StartTemp
+	return result, found
+}
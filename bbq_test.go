@@ -0,0 +1,92 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBBQSession() Session {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+	s := Session{
+		Type: SessionTypeBBQ,
+		Probes: []Probe{
+			{Name: "Pit", Position: ProbePosition1},
+			{Name: "Brisket", Position: ProbePosition2},
+		},
+		Stages: []Stage{
+			{Name: "Smoke", Start: start, End: start.Add(240 * time.Minute)},
+			{Name: "Rest", Start: start.Add(240 * time.Minute), End: start.Add(300 * time.Minute)},
+		},
+		Events: []Event{
+			{Note: "Spritz", Time: start.Add(90 * time.Minute)},
+			{Note: "Wrapped in butcher paper", Time: start.Add(150 * time.Minute)},
+		},
+	}
+
+	// meat rises to 160 in the first hour, stalls for 90 minutes, finishes at 190, then rises 6 degrees while resting
+	for i := range 300 {
+		var meat float64
+		switch {
+		case i < 60:
+			meat = 100 + float64(i)
+		case i < 150:
+			meat = 160 + float64(i%2)*0.5
+		case i < 240:
+			meat = 160 + float64(i-150)/3
+		case i < 252:
+			meat = 190 + float64(i-240)/2
+		default:
+			meat = 196 - float64(i-252)/4
+		}
+		s.Data = append(s.Data, ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Minute),
+			ProbeData: []float64{250, meat},
+		})
+	}
+
+	return s
+}
+
+func TestDetectStalls(t *testing.T) {
+	s := testBBQSession()
+	start := s.Stages[0].Start
+
+	stalls := s.DetectStalls(StallOptions{})
+	require.Len(t, stalls, 1)
+
+	stall := stalls[0]
+	assert.Equal(t, "Brisket", stall.Probe.Name)
+	// the rate window blurs the edges of the stall a little
+	assert.WithinDuration(t, start.Add(60*time.Minute), stall.Start, 5*time.Minute)
+	assert.WithinDuration(t, start.Add(150*time.Minute), stall.End, 5*time.Minute)
+	assert.InDelta(t, 90*time.Minute, stall.Duration, float64(10*time.Minute))
+	require.NotNil(t, stall.Wrap)
+	assert.Equal(t, "Wrapped in butcher paper", stall.Wrap.Note)
+	assert.Equal(t, stall.Wrap.Time.Sub(stall.Start), stall.WrapAfter)
+
+	assert.Equal(t, Stage{Name: "Stall", Start: stall.Start, End: stall.End, Duration: stall.Duration}, stall.Stage())
+
+	t.Run("TemperatureWindow", func(t *testing.T) {
+		assert.Empty(t, s.DetectStalls(StallOptions{MinTemp: 170, MaxTemp: 180}))
+	})
+
+	t.Run("MinDuration", func(t *testing.T) {
+		assert.Empty(t, s.DetectStalls(StallOptions{MinDuration: 2 * time.Hour}))
+	})
+}
+
+func TestCarryover(t *testing.T) {
+	s := testBBQSession()
+
+	carryover := s.Carryover()
+	require.Len(t, carryover, 1)
+	assert.Equal(t, "Brisket", carryover[0].Probe.Name)
+	assert.Equal(t, "Rest", carryover[0].Stage.Name)
+	assert.Equal(t, 190.0, carryover[0].StartTemp)
+	assert.Equal(t, 196.0, carryover[0].PeakTemp)
+	assert.Equal(t, 6.0, carryover[0].Rise)
+	assert.Equal(t, s.Stages[1].Start.Add(12*time.Minute), carryover[0].PeakTime)
+}
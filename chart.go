@@ -2,64 +2,234 @@ package twchart
 
 import (
 	"slices"
+	"strconv"
 	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
 )
 
-func (s Session) ChartData() [][]opts.LineData {
+// stallColor is used by default to shade stalls differently than the Session's Stages
+const stallColor = "rgba(128, 128, 128, 0.3)"
+
+// ChartOptions configures how a Session is charted
+type ChartOptions struct {
+	// MaxPoints is the maximum number of points in each probe's series. Larger series are downsampled. If it is 0,
+	// DefaultMaxPoints is used. Use a negative value to get the full resolution data
+	MaxPoints int
+
+	// From and To limit the data to a time range. They are ignored if zero
+	From time.Time
+	To   time.Time
+
+	// DerivedSeries are added to the chart on secondary Y axes. If nil, the Session's default DerivedSeries are used
+	DerivedSeries []DerivedSeries
+
+	// StallOptions configure the stall detection for BBQ sessions. Stalls are shaded on the chart
+	StallOptions StallOptions
+
+	// Theme sets the chart's colors. If nil, the default light Theme for the Session's type is used
+	Theme *Theme
+
+	// ElapsedFrom changes the x-axis from the time of day to the time elapsed since it. Use Alignment.Origin to
+	// measure from the Session's start or a stage. It is ignored if zero
+	ElapsedFrom time.Time
+}
+
+// elapsedFormatter formats seconds on the x-axis as mm:ss. JavaScript functions in chart options use single quotes
+// since double quotes are escaped when the options are encoded
+const elapsedFormatter = `function (seconds) {
+	const sign = seconds < 0 ? '-' : '';
+	seconds = Math.round(Math.abs(seconds));
+	return sign + Math.floor(seconds / 60) + ':' + String(seconds % 60).padStart(2, '0');
+}`
+
+// elapsedTooltipFormatter shows the elapsed time of a reading as mm:ss
+const elapsedTooltipFormatter = `function (params) {
+	if (!Array.isArray(params.value)) {
+		return params.name;
+	}
+	const format = ` + elapsedFormatter + `;
+	return params.marker + params.seriesName + '<br/>' + format(params.value[0]) + '  <b>' + params.value[1] + '</b>';
+}`
+
+// xValue is the time's value on the x-axis: an RFC3339 timestamp, or the seconds since ElapsedFrom
+func (o ChartOptions) xValue(t time.Time) any {
+	if o.ElapsedFrom.IsZero() {
+		return t.Format(time.RFC3339)
+	}
+	return t.Sub(o.ElapsedFrom).Seconds()
+}
+
+// xAxis is the time axis, or a value axis of elapsed seconds formatted as mm:ss
+func (o ChartOptions) xAxis() opts.XAxis {
+	axisPointer := &opts.AxisPointer{
+		Show: opts.Bool(true),
+		Snap: opts.Bool(false),
+	}
+	if o.ElapsedFrom.IsZero() {
+		return opts.XAxis{Type: "time", AxisPointer: axisPointer}
+	}
+
+	return opts.XAxis{
+		Name:        "Elapsed",
+		Type:        "value",
+		AxisPointer: axisPointer,
+		AxisLabel:   &opts.AxisLabel{Formatter: opts.FuncOpts(elapsedFormatter)},
+	}
+}
+
+// markArea is the Stage's MarkArea on the chart's x-axis
+func (o ChartOptions) markArea(stage Stage, color string) []opts.MarkAreaData {
+	area := stage.MarkArea(color)
+	if o.ElapsedFrom.IsZero() {
+		return area
+	}
+
+	area[0].XAxis = o.xValue(stage.Start)
+	area[1].XAxis = nil
+	if !stage.End.IsZero() {
+		area[1].XAxis = o.xValue(stage.End)
+	}
+	return area
+}
+
+func (o ChartOptions) theme(s Session) Theme {
+	if o.Theme == nil {
+		return Themes(nil).Get(s.Type, false)
+	}
+	return *o.Theme
+}
+
+func (o ChartOptions) derivedSeries(s Session) []DerivedSeries {
+	if o.DerivedSeries == nil {
+		return s.DerivedSeries()
+	}
+	return o.DerivedSeries
+}
+
+// inRangePoints is the same as inRange, but for Points
+func (o ChartOptions) inRangePoints(points []Point) []Point {
+	if o.From.IsZero() && o.To.IsZero() {
+		return points
+	}
+
+	result := []Point{}
+	for _, p := range points {
+		if (!o.From.IsZero() && p.Time.Before(o.From)) || (!o.To.IsZero() && p.Time.After(o.To)) {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+func (o ChartOptions) maxPoints() int {
+	if o.MaxPoints == 0 {
+		return DefaultMaxPoints
+	}
+	return o.MaxPoints
+}
+
+// inRange returns the data that is within the From/To range
+func (o ChartOptions) inRange(data []ThermoworksData) []ThermoworksData {
+	if o.From.IsZero() && o.To.IsZero() {
+		return data
+	}
+
+	result := []ThermoworksData{}
+	for _, d := range data {
+		if !o.From.IsZero() && d.Time.Before(o.From) {
+			continue
+		}
+		if !o.To.IsZero() && d.Time.After(o.To) {
+			continue
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// ChartData creates the line data for each of the Session's Probes, in the same order as s.Probes. The Session's
+// CleaningOptions are applied first and then each series is downsampled to the ChartOptions' MaxPoints
+func (s Session) ChartData(chartOpts ChartOptions) [][]opts.LineData {
 	result := make([][]opts.LineData, len(s.Probes))
 
 	if len(s.Probes) == 0 {
 		return result
 	}
 
-	for _, datum := range s.Data {
-		for _, p := range s.Probes {
-			probeData := datum.GetProbeData(p.Position)
-			if probeData <= 0 {
-				result[p.Position-1] = append(result[p.Position-1], opts.LineData{
-					Value: []any{datum.Time.Format(time.RFC3339), nil},
-				})
-			}
+	data := chartOpts.inRange(s.CleanData())
+	for i, p := range s.Probes {
+		points := Downsample(ProbeSeries(data, p.Position), chartOpts.maxPoints())
 
-			result[p.Position-1] = append(result[p.Position-1], opts.LineData{
-				Value: []any{datum.Time.Format(time.RFC3339), probeData},
-			})
+		result[i] = make([]opts.LineData, 0, len(points))
+		for _, point := range points {
+			result[i] = append(result[i], point.lineData(chartOpts.xValue(point.Time)))
 		}
 	}
 
+	// When the range is limited, the bounds are not needed
+	if !chartOpts.From.IsZero() || !chartOpts.To.IsZero() {
+		return result
+	}
+
 	// Add time bounds so all Events and Stages show
 	earliest, latest := s.TimeBounds()
+	if earliest.IsZero() {
+		return result
+	}
 	result[0] = slices.Insert(result[0], 0, opts.LineData{
-		Value: []any{earliest.Format(time.RFC3339), nil},
+		Value: []any{chartOpts.xValue(earliest), nil},
 	})
 	result[0] = append(result[0], opts.LineData{
-		Value: []any{latest.Format(time.RFC3339), nil},
+		Value: []any{chartOpts.xValue(latest), nil},
 	})
 
 	return result
 }
 
-func (s Session) Chart() (*charts.Line, error) {
+// DerivedChartData creates the line data for each of the DerivedSeries in the ChartOptions
+func (s Session) DerivedChartData(chartOpts ChartOptions) [][]opts.LineData {
+	derived := chartOpts.derivedSeries(s)
+	result := make([][]opts.LineData, len(derived))
+
+	for i, series := range derived {
+		points := Downsample(chartOpts.inRangePoints(series.Compute(s)), chartOpts.maxPoints())
+
+		result[i] = make([]opts.LineData, 0, len(points))
+		for _, point := range points {
+			result[i] = append(result[i], point.lineData(chartOpts.xValue(point.Time)))
+		}
+	}
+
+	return result
+}
+
+func (s Session) Chart(chartOpts ChartOptions) (*charts.Line, error) {
+	theme := chartOpts.theme(s)
+	echartsTheme := "white"
+	if theme.Dark {
+		echartsTheme = "dark"
+	}
+
+	tooltip := opts.Tooltip{
+		Show:    opts.Bool(true),
+		Trigger: "item",
+	}
+	if !chartOpts.ElapsedFrom.IsZero() {
+		tooltip.Formatter = opts.FuncOpts(elapsedTooltipFormatter)
+	}
+
 	line := charts.NewLine()
 	line.SetGlobalOptions(
 		charts.WithInitializationOpts(opts.Initialization{
 			Width:  "100%",
 			Height: "80vh",
+			Theme:  echartsTheme,
 		}),
-		charts.WithXAxisOpts(opts.XAxis{
-			Type: "time",
-			AxisPointer: &opts.AxisPointer{
-				Show: opts.Bool(true),
-				Snap: opts.Bool(false),
-			},
-		}),
-		charts.WithTooltipOpts(opts.Tooltip{
-			Show:    opts.Bool(true),
-			Trigger: "item",
-		}),
+		charts.WithXAxisOpts(chartOpts.xAxis()),
+		charts.WithTooltipOpts(tooltip),
 		charts.WithDataZoomOpts(
 			opts.DataZoom{
 				Type:   "slider",
@@ -80,29 +250,28 @@ func (s Session) Chart() (*charts.Line, error) {
 	for _, event := range s.Events {
 		events = append(events, opts.MarkLineNameXAxisItem{
 			Name:  event.Note,
-			XAxis: event.Time,
+			XAxis: chartOpts.xValue(event.Time),
 		})
 	}
 
-	baseOpts := []charts.SeriesOpts{
-		charts.WithLineChartOpts(
-			opts.LineChart{
-				Smooth:       opts.Bool(true),
-				ShowSymbol:   opts.Bool(false),
-				ConnectNulls: opts.Bool(false),
-			},
-		),
+	lineChartOpts := func(axisIndex int) opts.LineChart {
+		return opts.LineChart{
+			Smooth:       opts.Bool(true),
+			ShowSymbol:   opts.Bool(false),
+			ConnectNulls: opts.Bool(false),
+			YAxisIndex:   axisIndex,
+		}
 	}
+	baseOpts := []charts.SeriesOpts{charts.WithLineChartOpts(lineChartOpts(0))}
 
-	colors := []string{
-		"rgba(144, 238, 144, 0.4)",
-		"rgba(255, 255, 102, 0.4)",
-		"rgba(173, 216, 230, 0.4)",
-		"rgba(255, 173, 177, 0.4)",
-	}
 	areas := []charts.SeriesOpts{}
 	for i, stage := range s.Stages {
-		areas = append(areas, charts.WithMarkAreaData(stage.MarkArea(colors[i])))
+		areas = append(areas, charts.WithMarkAreaData(chartOpts.markArea(stage, theme.StageColor(i, stage.Name))))
+	}
+	if s.Type == SessionTypeBBQ {
+		for _, stall := range s.DetectStalls(chartOpts.StallOptions) {
+			areas = append(areas, charts.WithMarkAreaData(chartOpts.markArea(stall.Stage(), theme.StallColor)))
+		}
 	}
 
 	optsWithAreaAndEvents := append(baseOpts,
@@ -118,12 +287,89 @@ func (s Session) Chart() (*charts.Line, error) {
 	)
 	optsWithAreaAndEvents = append(optsWithAreaAndEvents, areas...)
 
-	chartData := s.ChartData()
-	for _, probe := range s.Probes {
-		line.AddSeries(probe.Name, chartData[probe.Position-1], baseOpts...)
+	axes := yAxes{line: line, indexes: map[string]int{}}
+
+	chartData := s.ChartData(chartOpts)
+	for i, probe := range s.Probes {
+		probeOpts := []charts.SeriesOpts{charts.WithLineChartOpts(lineChartOpts(axes.index(probe.Measurement)))}
+		line.AddSeries(probe.Name, chartData[i], append(probeOpts, seriesColorOpts(theme.ProbeColor(i))...)...)
+	}
+
+	derivedData := s.DerivedChartData(chartOpts)
+	for i, series := range chartOpts.derivedSeries(s) {
+		// derived series continue the probe colors so they don't match a probe
+		color := theme.ProbeColor(len(s.Probes) + i)
+		line.AddSeries(series.Name(), derivedData[i], charts.WithLineChartOpts(lineChartOpts(axes.index(series.Measurement()))),
+			charts.WithLineStyleOpts(opts.LineStyle{Type: "dashed", Color: color}), charts.WithItemStyleOpts(opts.ItemStyle{Color: color}))
+	}
+
+	// make room for the labels of extra secondary axes
+	if len(axes.indexes) > 2 {
+		line.SetGlobalOptions(charts.WithGridOpts(opts.Grid{
+			Right: strconv.Itoa(defaultGridRight + (len(axes.indexes)-2)*secondaryAxisWidth),
+		}))
 	}
 
 	line.AddSeries("Stages + Events", nil, optsWithAreaAndEvents...)
 
 	return line, nil
 }
+
+const (
+	// secondaryAxisWidth is the space used by each secondary Y axis after the first
+	secondaryAxisWidth = 56
+	// defaultGridRight leaves room for the first secondary Y axis and the vertical zoom slider
+	defaultGridRight = 120
+)
+
+// yAxes assigns series to Y axes by unit. The first unit uses the primary axis on the left and each other unit gets
+// a secondary axis on the right. Secondary axes after the first are moved further right so they don't overlap
+type yAxes struct {
+	line    *charts.Line
+	indexes map[string]int
+}
+
+// index returns the index of the Measurement's axis, creating the axis if it is the first series with its unit
+func (a yAxes) index(m Measurement) int {
+	m = m.withDefaults()
+	if i, ok := a.indexes[m.Unit]; ok {
+		return i
+	}
+
+	i := len(a.indexes)
+	a.indexes[m.Unit] = i
+
+	axis := opts.YAxis{
+		Name: m.Label(),
+		Type: "value",
+	}
+	if minimum, maximum, ok := m.axisRange(); ok {
+		axis.Min, axis.Max = minimum, maximum
+	}
+	if i == 0 {
+		a.line.SetGlobalOptions(charts.WithYAxisOpts(axis))
+		return i
+	}
+
+	axis.Position = "right"
+	axis.SplitLine = &opts.SplitLine{Show: opts.Bool(false)}
+	if i > 1 {
+		margin := (i - 1) * secondaryAxisWidth
+		axis.AxisLabel = &opts.AxisLabel{Margin: float64(8 + margin)}
+		axis.NameLocation = "middle"
+		axis.NameGap = 40 + margin
+	}
+	a.line.ExtendYAxis(axis)
+	return i
+}
+
+// seriesColorOpts sets the color of a series' line and its legend and tooltip symbol
+func seriesColorOpts(color string) []charts.SeriesOpts {
+	if color == "" {
+		return nil
+	}
+	return []charts.SeriesOpts{
+		charts.WithLineStyleOpts(opts.LineStyle{Color: color}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: color}),
+	}
+}
@@ -0,0 +1,130 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartElapsedAxis(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast", start, 4*time.Minute)
+	s.Events = []Event{{Note: "First crack", Time: start.Add(5 * time.Minute)}}
+
+	t.Run("TimeByDefault", func(t *testing.T) {
+		data := s.ChartData(ChartOptions{MaxPoints: -1})
+		assert.Equal(t, opts.LineData{Value: []any{"2025-05-21T08:00:00Z", 100.0}}, data[1][0])
+
+		line, err := s.Chart(ChartOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "time", line.XAxisList[0].Type)
+	})
+
+	t.Run("ElapsedFromStage", func(t *testing.T) {
+		chartOpts := ChartOptions{MaxPoints: -1, ElapsedFrom: start.Add(4 * time.Minute)}
+
+		data := s.ChartData(chartOpts)
+		assert.Equal(t, opts.LineData{Value: []any{-240.0, 100.0}}, data[1][0])
+		assert.Equal(t, opts.LineData{Value: []any{360.0, 400.0}}, data[1][10])
+
+		line, err := s.Chart(chartOpts)
+		require.NoError(t, err)
+		assert.Equal(t, "value", line.XAxisList[0].Type)
+		assert.NotEmpty(t, line.XAxisList[0].AxisLabel.Formatter)
+		assert.NotContains(t, line.XAxisList[0].AxisLabel.Formatter, `"`)
+		assert.NotContains(t, line.Tooltip.Formatter, `"`)
+
+		marks := line.MultiSeries[len(line.MultiSeries)-1]
+		assert.Equal(t, 60.0, marks.MarkLines.Data[0].(opts.MarkLineNameXAxisItem).XAxis)
+
+		// stages are converted and the unfinished stage has no end
+		require.Len(t, marks.MarkAreas.Data, 2)
+		drying := marks.MarkAreas.Data[0].([]opts.MarkAreaData)
+		assert.Equal(t, -240.0, drying[0].XAxis)
+		assert.Equal(t, 0.0, drying[1].XAxis)
+		development := marks.MarkAreas.Data[1].([]opts.MarkAreaData)
+		assert.Nil(t, development[1].XAxis)
+	})
+
+	t.Run("BoundsWithUnfinishedStage", func(t *testing.T) {
+		data := s.ChartData(ChartOptions{MaxPoints: -1, ElapsedFrom: start.Add(4 * time.Minute)})
+
+		// the unfinished stage's start and the Event are the bounds
+		require.Len(t, data[0], 13)
+		assert.Equal(t, opts.LineData{Value: []any{0.0, nil}}, data[0][0])
+		assert.Equal(t, opts.LineData{Value: []any{60.0, nil}}, data[0][12])
+	})
+
+	t.Run("NoBoundsWithoutNotes", func(t *testing.T) {
+		noNotes := s
+		noNotes.Events = nil
+		noNotes.Stages = nil
+
+		data := noNotes.ChartData(ChartOptions{MaxPoints: -1, ElapsedFrom: start})
+		require.Len(t, data[0], 11)
+		assert.Equal(t, opts.LineData{Value: []any{0.0, 400.0}}, data[0][0])
+	})
+}
+
+func TestChartMeasurementAxes(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := Session{
+		Type: SessionTypeCoffee,
+		Probes: []Probe{
+			{Name: "Bean", Position: ProbePosition1},
+			{Name: "Humidity", Position: ProbePosition2, Measurement: Measurement{Kind: MeasurementHumidity}},
+			{Name: "Fan", Position: ProbePosition3, Measurement: Measurement{Kind: MeasurementFan}},
+			{Name: "Exhaust", Position: ProbePosition4},
+		},
+	}
+	for i := range 10 {
+		s.Data = append(s.Data, ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Minute),
+			ProbeData: []float64{100 + float64(i)*20, 40, 60, 120},
+		})
+	}
+
+	line, err := s.Chart(ChartOptions{})
+	require.NoError(t, err)
+
+	// temperature, percentages, and the bean's rate of rise
+	require.Len(t, line.YAxisList, 3)
+	assert.Equal(t, "Temperature (°F)", line.YAxisList[0].Name)
+	assert.Nil(t, line.YAxisList[0].Max)
+	assert.Equal(t, "Humidity (%)", line.YAxisList[1].Name)
+	assert.Equal(t, "right", line.YAxisList[1].Position)
+	assert.Equal(t, 0.0, line.YAxisList[1].Min)
+	assert.Equal(t, 100.0, line.YAxisList[1].Max)
+	assert.Equal(t, "Rate (°F/min)", line.YAxisList[2].Name)
+	assert.Equal(t, "middle", line.YAxisList[2].NameLocation)
+
+	axisIndexes := map[string]int{}
+	for _, series := range line.MultiSeries {
+		axisIndexes[series.Name] = series.YAxisIndex
+		assert.Equal(t, opts.Bool(true), series.Smooth, series.Name)
+	}
+	assert.Equal(t, map[string]int{
+		"Bean":            0,
+		"Humidity":        1,
+		"Fan":             1,
+		"Exhaust":         0,
+		"Bean RoR":        2,
+		"Stages + Events": 0,
+	}, axisIndexes)
+}
+
+func TestMeasurement(t *testing.T) {
+	assert.Equal(t, "Temperature (°F)", Measurement{}.Label())
+	assert.Equal(t, "Temperature (°C)", Measurement{Unit: "°C"}.Label())
+	assert.Equal(t, "Pressure (kPa)", Measurement{Kind: MeasurementPressure}.Label())
+
+	kind, err := ParseMeasurementKind("Humidity")
+	require.NoError(t, err)
+	assert.Equal(t, MeasurementHumidity, kind)
+
+	_, err = ParseMeasurementKind("speed")
+	assert.Error(t, err)
+}
@@ -0,0 +1,297 @@
+package twchart
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// SmoothingMethod selects the smoothing filter used when cleaning data
+type SmoothingMethod string
+
+const (
+	SmoothingNone          SmoothingMethod = ""
+	SmoothingMovingAverage SmoothingMethod = "moving-average"
+	SmoothingSavitzkyGolay SmoothingMethod = "savitzky-golay"
+)
+
+const defaultSmoothingWindow = 5
+
+// CleaningOptions configures how a Session's raw ThermoworksData is cleaned before it is charted or used for stats.
+// The zero value leaves the data unchanged
+type CleaningOptions struct {
+	// MaxRate is the largest change in degrees per second that is accepted from a probe. Faster changes are
+	// rejected as spikes. 0 disables spike rejection
+	MaxRate float64
+	// OutlierThreshold rejects readings that are more than this many median absolute deviations from the median
+	// of the surrounding OutlierWindow readings. 0 disables outlier rejection
+	OutlierThreshold float64
+	OutlierWindow    int
+	// GapThreshold is the time between readings that is considered a disconnect. A missing reading is inserted
+	// so the gap is not drawn as a line. 0 disables gap detection
+	GapThreshold time.Duration
+	// Smoothing and SmoothingWindow configure optional smoothing of the cleaned data
+	Smoothing       SmoothingMethod
+	SmoothingWindow int
+}
+
+// Validate checks that the options can be used to create a Pipeline
+func (o CleaningOptions) Validate() error {
+	switch o.Smoothing {
+	case SmoothingNone, SmoothingMovingAverage, SmoothingSavitzkyGolay:
+	default:
+		return fmt.Errorf("invalid smoothing method: %q", o.Smoothing)
+	}
+
+	if o.MaxRate < 0 || o.OutlierThreshold < 0 || o.OutlierWindow < 0 || o.GapThreshold < 0 || o.SmoothingWindow < 0 {
+		return fmt.Errorf("cleaning options must not be negative")
+	}
+
+	return nil
+}
+
+// Pipeline creates the cleaning Pipeline described by the options. Rejection steps run first, then gap detection
+// and smoothing
+func (o CleaningOptions) Pipeline() Pipeline {
+	var p Pipeline
+	if o.MaxRate > 0 {
+		p = append(p, RejectSpikes(o.MaxRate))
+	}
+	if o.OutlierThreshold > 0 {
+		window := o.OutlierWindow
+		if window == 0 {
+			window = defaultSmoothingWindow
+		}
+		p = append(p, RejectOutliers(window, o.OutlierThreshold))
+	}
+	if o.GapThreshold > 0 {
+		p = append(p, MarkGaps(o.GapThreshold))
+	}
+
+	window := o.SmoothingWindow
+	if window == 0 {
+		window = defaultSmoothingWindow
+	}
+	switch o.Smoothing {
+	case SmoothingMovingAverage:
+		p = append(p, MovingAverage(window))
+	case SmoothingSavitzkyGolay:
+		p = append(p, SavitzkyGolay(window))
+	}
+
+	return p
+}
+
+// Cleaner is a single step in a cleaning Pipeline. It may modify the input slice and its ProbeData in place since the
+// Pipeline always passes it a copy
+type Cleaner func([]ThermoworksData) []ThermoworksData
+
+// Pipeline is a sequence of Cleaners that are applied in order
+type Pipeline []Cleaner
+
+// Apply runs each Cleaner on a copy of the data so the original data is never modified
+func (p Pipeline) Apply(data []ThermoworksData) []ThermoworksData {
+	if len(p) == 0 {
+		return data
+	}
+
+	result := make([]ThermoworksData, len(data))
+	for i, d := range data {
+		result[i] = ThermoworksData{Time: d.Time, ProbeData: slices.Clone(d.ProbeData)}
+	}
+
+	for _, clean := range p {
+		result = clean(result)
+	}
+	return result
+}
+
+// RejectSpikes removes readings that change faster than maxRate degrees per second compared to the previous accepted
+// reading from the same probe
+func RejectSpikes(maxRate float64) Cleaner {
+	return func(data []ThermoworksData) []ThermoworksData {
+		for probe := range numProbes(data) {
+			var prev *ThermoworksData
+			for i := range data {
+				if !data[i].hasReading(probe) {
+					continue
+				}
+				if prev != nil {
+					elapsed := data[i].Time.Sub(prev.Time).Seconds()
+					change := math.Abs(data[i].ProbeData[probe] - prev.ProbeData[probe])
+					if elapsed <= 0 || change/elapsed > maxRate {
+						data[i].ProbeData[probe] = missingReading
+						continue
+					}
+				}
+				prev = &data[i]
+			}
+		}
+		return data
+	}
+}
+
+// RejectOutliers removes readings that are more than threshold median absolute deviations away from the median of
+// the window readings centered around them (Hampel filter)
+func RejectOutliers(window int, threshold float64) Cleaner {
+	// scale MAD to be comparable to the standard deviation for normally-distributed data
+	const madScale = 1.4826
+
+	return func(data []ThermoworksData) []ThermoworksData {
+		half := window / 2
+		for probe := range numProbes(data) {
+			indexes, values := probeReadings(data, probe)
+
+			for i, idx := range indexes {
+				lo, hi := max(0, i-half), min(len(values), i+half+1)
+				med := median(values[lo:hi])
+
+				deviations := make([]float64, 0, hi-lo)
+				for _, v := range values[lo:hi] {
+					deviations = append(deviations, math.Abs(v-med))
+				}
+				mad := madScale * median(deviations)
+
+				if mad > 0 && math.Abs(values[i]-med) > threshold*mad {
+					data[idx].ProbeData[probe] = missingReading
+				}
+			}
+		}
+		return data
+	}
+}
+
+// MarkGaps inserts a missing reading between any two consecutive data points that are more than threshold apart.
+// This makes disconnects explicit in the data so they are not connected when charted
+func MarkGaps(threshold time.Duration) Cleaner {
+	return func(data []ThermoworksData) []ThermoworksData {
+		result := make([]ThermoworksData, 0, len(data))
+		for i, d := range data {
+			if i > 0 && d.Time.Sub(data[i-1].Time) > threshold {
+				gap := ThermoworksData{
+					Time:      data[i-1].Time.Add(d.Time.Sub(data[i-1].Time) / 2),
+					ProbeData: make([]float64, len(d.ProbeData)),
+				}
+				for p := range gap.ProbeData {
+					gap.ProbeData[p] = missingReading
+				}
+				result = append(result, gap)
+			}
+			result = append(result, d)
+		}
+		return result
+	}
+}
+
+// MovingAverage smooths each probe's readings with a centered moving average over window readings. Averages do
+// not extend across missing readings
+func MovingAverage(window int) Cleaner {
+	weights := make([]float64, window|1)
+	for i := range weights {
+		weights[i] = 1 / float64(len(weights))
+	}
+	return convolve(weights)
+}
+
+// SavitzkyGolay smooths each probe's readings by fitting a quadratic polynomial over window readings. This preserves
+// peaks and rates of change better than a moving average
+func SavitzkyGolay(window int) Cleaner {
+	// window must be odd and at least 5 for a quadratic fit
+	m := max(window|1, 5) / 2
+
+	weights := make([]float64, 2*m+1)
+	norm := float64((2*m + 1) * (4*m*m + 4*m - 3))
+	for i := -m; i <= m; i++ {
+		weights[i+m] = float64(3*(3*m*m+3*m-1)-15*i*i) / norm
+	}
+	return convolve(weights)
+}
+
+// convolve creates a Cleaner that applies the weights centered on each reading. Readings near the edges of a run of
+// continuous data are left unchanged
+func convolve(weights []float64) Cleaner {
+	half := len(weights) / 2
+
+	return func(data []ThermoworksData) []ThermoworksData {
+		for probe := range numProbes(data) {
+			for _, run := range probeRuns(data, probe) {
+				smoothed := make([]float64, len(run))
+				for i := range run {
+					if i < half || i >= len(run)-half {
+						smoothed[i] = data[run[i]].ProbeData[probe]
+						continue
+					}
+
+					var sum float64
+					for w, weight := range weights {
+						sum += weight * data[run[i-half+w]].ProbeData[probe]
+					}
+					smoothed[i] = sum
+				}
+
+				for i, idx := range run {
+					data[idx].ProbeData[probe] = smoothed[i]
+				}
+			}
+		}
+		return data
+	}
+}
+
+// numProbes returns the largest number of probes in any data point
+func numProbes(data []ThermoworksData) int {
+	n := 0
+	for _, d := range data {
+		n = max(n, len(d.ProbeData))
+	}
+	return n
+}
+
+// probeReadings returns the indexes and values of all valid readings for a probe
+func probeReadings(data []ThermoworksData, probe int) ([]int, []float64) {
+	var indexes []int
+	var values []float64
+	for i, d := range data {
+		if d.hasReading(probe) {
+			indexes = append(indexes, i)
+			values = append(values, d.ProbeData[probe])
+		}
+	}
+	return indexes, values
+}
+
+// probeRuns splits a probe's valid readings into runs of consecutive data points without missing readings
+func probeRuns(data []ThermoworksData, probe int) [][]int {
+	var runs [][]int
+	var current []int
+	for i, d := range data {
+		if !d.hasReading(probe) {
+			if len(current) > 0 {
+				runs = append(runs, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, i)
+	}
+	if len(current) > 0 {
+		runs = append(runs, current)
+	}
+	return runs
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,102 @@
+package twchart
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// missing is used for missingReading by cleanTestData and probeValues since NaN is never equal to itself
+const missing = -math.MaxFloat64
+
+func cleanTestData(values ...float64) []ThermoworksData {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local)
+	data := make([]ThermoworksData, len(values))
+	for i, v := range values {
+		if v == missing {
+			v = missingReading
+		}
+		data[i] = ThermoworksData{Time: start.Add(time.Duration(i) * time.Second), ProbeData: []float64{v}}
+	}
+	return data
+}
+
+func probeValues(data []ThermoworksData) []float64 {
+	var values []float64
+	for _, d := range data {
+		if !d.hasReading(0) {
+			values = append(values, missing)
+			continue
+		}
+		values = append(values, d.ProbeData[0])
+	}
+	return values
+}
+
+func TestPipeline(t *testing.T) {
+	t.Run("RawDataUnchanged", func(t *testing.T) {
+		data := cleanTestData(100, 101, 500, 102)
+		cleaned := Pipeline{RejectSpikes(5)}.Apply(data)
+		assert.Equal(t, []float64{100, 101, 500, 102}, probeValues(data))
+		assert.Equal(t, []float64{100, 101, missing, 102}, probeValues(cleaned))
+	})
+
+	t.Run("EmptyPipeline", func(t *testing.T) {
+		data := cleanTestData(100, 101)
+		assert.Equal(t, data, Pipeline{}.Apply(data))
+	})
+}
+
+func TestRejectSpikes(t *testing.T) {
+	data := cleanTestData(100, 101, missing, missing, 300, 103, 104)
+	cleaned := RejectSpikes(2)(data)
+	assert.Equal(t, []float64{100, 101, missing, missing, missing, 103, 104}, probeValues(cleaned))
+}
+
+func TestRejectOutliers(t *testing.T) {
+	data := cleanTestData(100, 101, 102, 103, 60, 105, 106, 107)
+	cleaned := RejectOutliers(5, 3)(data)
+	assert.Equal(t, []float64{100, 101, 102, 103, missing, 105, 106, 107}, probeValues(cleaned))
+}
+
+func TestMarkGaps(t *testing.T) {
+	data := cleanTestData(100, 101)
+	data = append(data, ThermoworksData{Time: data[1].Time.Add(10 * time.Minute), ProbeData: []float64{102}})
+
+	cleaned := MarkGaps(time.Minute)(data)
+	assert.Len(t, cleaned, 4)
+	assert.Equal(t, []float64{100, 101, missing, 102}, probeValues(cleaned))
+	assert.Equal(t, data[1].Time.Add(5*time.Minute), cleaned[2].Time)
+}
+
+func TestSmoothing(t *testing.T) {
+	t.Run("MovingAverage", func(t *testing.T) {
+		data := cleanTestData(100, 103, 100, 103, 100, missing, 50, 60)
+		cleaned := MovingAverage(3)(data)
+		assert.InDeltaSlice(t, []float64{100, 101, 102, 101, 100, missing, 50, 60}, probeValues(cleaned), 0.0001)
+	})
+
+	t.Run("SavitzkyGolayPreservesLine", func(t *testing.T) {
+		data := cleanTestData(100, 102, 104, 106, 108, 110, 112)
+		cleaned := SavitzkyGolay(5)(data)
+		for i, v := range probeValues(cleaned) {
+			assert.InDelta(t, 100+2*float64(i), v, 0.0001)
+		}
+	})
+}
+
+func TestCleaningOptions(t *testing.T) {
+	assert.Empty(t, CleaningOptions{}.Pipeline())
+	assert.Len(t, CleaningOptions{
+		MaxRate:          1,
+		OutlierThreshold: 3,
+		GapThreshold:     time.Minute,
+		Smoothing:        SmoothingSavitzkyGolay,
+	}.Pipeline(), 4)
+
+	assert.NoError(t, CleaningOptions{Smoothing: SmoothingMovingAverage}.Validate())
+	assert.Error(t, CleaningOptions{Smoothing: "unknown"}.Validate())
+	assert.Error(t, CleaningOptions{MaxRate: -1}.Validate())
+}
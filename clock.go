@@ -0,0 +1,176 @@
+package twchart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAlignmentSearchRange = 30 * time.Minute
+	alignmentStepWindow         = time.Minute
+)
+
+// ClockOffset is the difference between the clock used to take notes and the Thermoworks clock. It is added to the
+// time of every Stage and Event
+type ClockOffset time.Duration
+
+func (co ClockOffset) AddToSession(s *Session) {
+	s.ShiftNotes(time.Duration(co))
+}
+
+// ShiftNotes moves all Stages and Events by the offset
+func (s *Session) ShiftNotes(offset time.Duration) {
+	if offset == 0 {
+		return
+	}
+
+	if !s.StartTime.IsZero() {
+		s.StartTime = s.StartTime.Add(offset)
+	}
+
+	for i := range s.Stages {
+		s.Stages[i].Start = s.Stages[i].Start.Add(offset)
+		if !s.Stages[i].End.IsZero() {
+			s.Stages[i].End = s.Stages[i].End.Add(offset)
+		}
+	}
+
+	for i := range s.Events {
+		s.Events[i].Time = s.Events[i].Time.Add(offset)
+	}
+}
+
+// MoveEvent changes the time of the Event with the note and time. The Events are kept in order of their times
+func (s *Session) MoveEvent(note string, from, to time.Time) error {
+	for i, e := range s.Events {
+		if e.Note == note && e.Time.Equal(from) {
+			s.Events[i].Time = to
+			s.SortEvents()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q at %s", ErrEventNotFound, note, from.Format(time.Kitchen))
+}
+
+// SortEvents puts the Events in order of their times. Events at the same time keep their order
+func (s *Session) SortEvents() {
+	slices.SortStableFunc(s.Events, func(a, b Event) int {
+		return a.Time.Compare(b.Time)
+	})
+}
+
+// ShiftData moves all ThermoworksData by the offset
+func (s *Session) ShiftData(offset time.Duration) {
+	for i := range s.Data {
+		s.Data[i].Time = s.Data[i].Time.Add(offset)
+	}
+}
+
+// ClockAlignment is a proposed ClockOffset that aligns an Event with the largest temperature change near it
+type ClockAlignment struct {
+	Event Event
+	Probe Probe
+
+	// ChangeTime is the start of the largest change in temperature and Change is the amount it changed by
+	ChangeTime time.Time
+	Change     float64
+
+	// Offset should be added to the notes to align them with the data
+	Offset time.Duration
+}
+
+var (
+	ErrEventNotFound = errors.New("event not found")
+	ErrProbeNotFound = errors.New("probe not found")
+	ErrNoData        = errors.New("no data")
+)
+
+// FindProbe returns the Probe with a name matching the input, ignoring case
+func (s Session) FindProbe(name string) (Probe, error) {
+	for _, p := range s.Probes {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return Probe{}, fmt.Errorf("%w: %q", ErrProbeNotFound, name)
+}
+
+// FindEvent returns the first Event with a note containing the input, ignoring case
+func (s Session) FindEvent(note string) (Event, error) {
+	for _, e := range s.Events {
+		if strings.Contains(strings.ToLower(e.Note), strings.ToLower(note)) {
+			return e, nil
+		}
+	}
+	return Event{}, fmt.Errorf("%w: %q", ErrEventNotFound, note)
+}
+
+// ProposeClockOffset finds the Event matching eventNote and the largest temperature step change in the probe within
+// searchRange of the Event. The proposed offset moves the Event to the start of that change. If searchRange is 0,
+// the default of 30 minutes is used
+func (s Session) ProposeClockOffset(eventNote, probeName string, searchRange time.Duration) (ClockAlignment, error) {
+	if searchRange == 0 {
+		searchRange = defaultAlignmentSearchRange
+	}
+
+	event, err := s.FindEvent(eventNote)
+	if err != nil {
+		return ClockAlignment{}, err
+	}
+
+	probe, err := s.FindProbe(probeName)
+	if err != nil {
+		return ClockAlignment{}, err
+	}
+
+	points := ProbeSeries(s.CleanData(), probe.Position)
+
+	result := ClockAlignment{Event: event, Probe: probe}
+	bestStart, bestEnd := -1, -1
+	end := 0
+	for start, p := range points {
+		if !p.Valid() || p.Time.Before(event.Time.Add(-searchRange)) || p.Time.After(event.Time.Add(searchRange)) {
+			continue
+		}
+
+		// find the reading at the end of the step window
+		end = max(end, start)
+		for end < len(points)-1 && points[end+1].Time.Sub(p.Time) <= alignmentStepWindow {
+			end++
+		}
+		if !points[end].Valid() || end == start {
+			continue
+		}
+
+		change := points[end].Value - p.Value
+		if bestStart == -1 || math.Abs(change) > math.Abs(result.Change) {
+			bestStart, bestEnd = start, end
+			result.Change = change
+		}
+	}
+
+	if bestStart == -1 || result.Change == 0 {
+		return ClockAlignment{}, fmt.Errorf("%w: no temperature change for %q within %s of %q", ErrNoData, probe.Name, searchRange, event.Note)
+	}
+
+	// The step window can start before the change, so use the steepest part of the window as the start
+	var steepest float64
+	result.ChangeTime = points[bestStart].Time
+	for i := bestStart; i < bestEnd; i++ {
+		if !points[i].Valid() || !points[i+1].Valid() {
+			continue
+		}
+		rate := math.Abs(points[i+1].Value-points[i].Value) / points[i+1].Time.Sub(points[i].Time).Seconds()
+		if rate > steepest {
+			steepest = rate
+			result.ChangeTime = points[i].Time
+		}
+	}
+
+	result.Offset = result.ChangeTime.Sub(event.Time)
+	return result, nil
+}
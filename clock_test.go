@@ -0,0 +1,110 @@
+package twchart
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClockOffset(t *testing.T) {
+	input := `Ciabatta
+Date: 2025-05-24
+
+Oven Probe: 1
+
+Note: 10:00AM: preheat
+Bake: 10:30AM
+Clock offset: -2m
+Note: 10:35AM: into oven
+Done: 10:55AM
+`
+
+	var s Session
+	_, err := io.Copy(&s, bytes.NewReader([]byte(input)))
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, time.May, 24, 9, 58, 0, 0, time.Local), s.StartTime)
+	assert.Equal(t, time.Date(2025, time.May, 24, 9, 58, 0, 0, time.Local), s.Events[0].Time)
+	assert.Equal(t, time.Date(2025, time.May, 24, 10, 33, 0, 0, time.Local), s.Events[1].Time)
+	assert.Equal(t, Stage{
+		Name:     "Bake",
+		Start:    time.Date(2025, time.May, 24, 10, 28, 0, 0, time.Local),
+		End:      time.Date(2025, time.May, 24, 10, 53, 0, 0, time.Local),
+		Duration: 25 * time.Minute,
+	}, s.Stages[0])
+}
+
+func TestProposeClockOffset(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 10, 0, 0, 0, time.Local)
+	s := Session{
+		Probes: []Probe{{Name: "Oven", Position: ProbePosition1}},
+		Events: []Event{{Note: "Into oven", Time: start.Add(20 * time.Minute)}},
+	}
+	for i := range 60 * 60 / 10 {
+		value := 200.0
+		if i >= 25*6 {
+			value = 450
+		}
+		s.Data = append(s.Data, ThermoworksData{Time: start.Add(time.Duration(i) * 10 * time.Second), ProbeData: []float64{value}})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		alignment, err := s.ProposeClockOffset("into oven", "oven", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, start.Add(24*time.Minute+50*time.Second), alignment.ChangeTime)
+		assert.Equal(t, 250.0, alignment.Change)
+		assert.Equal(t, 4*time.Minute+50*time.Second, alignment.Offset)
+	})
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		_, err := s.ProposeClockOffset("into oven", "oven", time.Minute)
+		assert.ErrorIs(t, err, ErrNoData)
+	})
+
+	t.Run("EventNotFound", func(t *testing.T) {
+		_, err := s.ProposeClockOffset("missing", "oven", 0)
+		assert.ErrorIs(t, err, ErrEventNotFound)
+	})
+
+	t.Run("ProbeNotFound", func(t *testing.T) {
+		_, err := s.ProposeClockOffset("into oven", "missing", 0)
+		assert.ErrorIs(t, err, ErrProbeNotFound)
+	})
+}
+
+func TestShiftData(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 10, 0, 0, 0, time.Local)
+	s := Session{Data: []ThermoworksData{{Time: start, ProbeData: []float64{1}}}}
+	s.ShiftData(-time.Minute)
+	assert.Equal(t, start.Add(-time.Minute), s.Data[0].Time)
+}
+
+func TestMoveEvent(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := Session{}
+	Event{Note: "Yellow", Time: start.Add(3 * time.Minute)}.AddToSession(&s)
+	Event{Note: "Charge", Time: start}.AddToSession(&s)
+	Event{Note: "First crack", Time: start.Add(8 * time.Minute)}.AddToSession(&s)
+	assert.Equal(t, []string{"Charge", "Yellow", "First crack"}, eventNotes(s))
+
+	t.Run("NotFound", func(t *testing.T) {
+		err := s.MoveEvent("Yellow", start, start.Add(time.Minute))
+		assert.ErrorIs(t, err, ErrEventNotFound)
+	})
+
+	err := s.MoveEvent("Yellow", start.Add(3*time.Minute), start.Add(9*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Charge", "First crack", "Yellow"}, eventNotes(s))
+	assert.Equal(t, start.Add(9*time.Minute), s.Events[2].Time)
+}
+
+func eventNotes(s Session) []string {
+	notes := []string{}
+	for _, e := range s.Events {
+		notes = append(notes, e.Note)
+	}
+	return notes
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -10,6 +15,8 @@ import (
 	"github.com/calvinmclean/babyapi/extensions"
 	"github.com/calvinmclean/twchart"
 	"github.com/calvinmclean/twchart/api"
+	"github.com/calvinmclean/twchart/notify"
+	"github.com/calvinmclean/twchart/source"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
@@ -37,6 +44,31 @@ func main() {
 			}
 		}
 
+		server.Notifier = notify.FromEnv()
+		server.BaseURL, _ = c.Flags().GetString("base-url")
+
+		assets, _ := c.Flags().GetString("assets")
+		err := server.UseAssets(assets)
+		if err != nil {
+			return err
+		}
+
+		alertRulesFlag := c.Flag("alert-rules")
+		if alertRulesFlag != nil && alertRulesFlag.Value.String() != "" {
+			err := server.LoadAlertTemplates(alertRulesFlag.Value.String())
+			if err != nil {
+				return err
+			}
+		}
+
+		themesFlag := c.Flag("themes")
+		if themesFlag != nil && themesFlag.Value.String() != "" {
+			err := server.LoadThemes(themesFlag.Value.String())
+			if err != nil {
+				return err
+			}
+		}
+
 		dirFlag := c.Flag("dir")
 		if dirFlag == nil || dirFlag.Value.String() == "" {
 			return nil
@@ -53,6 +85,10 @@ func main() {
 
 		c.Flags().String("dir", "", "directory to read data from")
 		c.Flags().String("store", "", "filename for JSON KV store")
+		c.Flags().String("alert-rules", "", "JSON file with default alert rules for each session type")
+		c.Flags().String("themes", "", "JSON file with chart themes for each session type")
+		c.Flags().String("base-url", "", "external URL of the server used for links in notifications")
+		c.Flags().String("assets", api.AssetsCDN, "where pages load CSS and JavaScript from: \"cdn\" or \"embedded\" for offline use")
 	}
 
 	migrateCmd := &cobra.Command{
@@ -76,6 +112,39 @@ func main() {
 	dbMigrateCmd.Flags().Int("steps", 0, "Number of migrations to run (0 = all)")
 	cmd.AddCommand(dbMigrateCmd)
 
+	pollCmd := &cobra.Command{
+		Use:   "poll",
+		Short: "Poll a readings endpoint and send live readings to a session",
+		Long:  "Sign in with Firebase Authentication, poll --readings-url for a device's readings, and send them to a session on a running server. The endpoint must return readings in the format described in the README. This is not a Thermoworks Cloud client. The password is read from the TWCHART_SOURCE_PASSWORD environment variable.",
+		RunE:  pollCommand,
+	}
+	pollCmd.Flags().String("server", "http://localhost:8080", "base URL of the twchart server")
+	pollCmd.Flags().String("session", "", "ID of the session to add readings to")
+	pollCmd.Flags().String("email", "", "Firebase account email")
+	pollCmd.Flags().String("api-key", "", "Firebase web API key")
+	pollCmd.Flags().String("device", "", "serial number of the device")
+	pollCmd.Flags().String("readings-url", "", "endpoint for device readings")
+	pollCmd.Flags().Duration("interval", 30*time.Second, "time between polls")
+	_ = pollCmd.MarkFlagRequired("session")
+	_ = pollCmd.MarkFlagRequired("email")
+	_ = pollCmd.MarkFlagRequired("device")
+	_ = pollCmd.MarkFlagRequired("readings-url")
+	cmd.AddCommand(pollCmd)
+
+	imageCmd := &cobra.Command{
+		Use:   "image [notes] [output]",
+		Short: "Draw a session's chart as an SVG or PNG image",
+		Long:  "Draw the chart for a notes file and its Thermoworks CSV data as an image. The format is chosen by the output file's extension (.svg or .png).",
+		Args:  cobra.ExactArgs(2),
+		RunE:  imageCommand,
+	}
+	imageCmd.Flags().String("data", "", "Thermoworks CSV file (default is the notes file with a .csv extension)")
+	imageCmd.Flags().Int("width", twchart.DefaultImageWidth, "image width in pixels")
+	imageCmd.Flags().Int("height", twchart.DefaultImageHeight, "image height in pixels")
+	imageCmd.Flags().Bool("dark", false, "use the dark theme")
+	imageCmd.Flags().String("align", "", "show elapsed time from the session's start or a stage (\"start\" or \"stage:[name]\") instead of the time of day")
+	cmd.AddCommand(imageCmd)
+
 	err := cmd.Execute()
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
@@ -176,6 +245,105 @@ func parseOld(fname string) ([]*api.SessionResource, error) {
 	return out, nil
 }
 
+func pollCommand(cmd *cobra.Command, _ []string) error {
+	serverURL, _ := cmd.Flags().GetString("server")
+	sessionID, _ := cmd.Flags().GetString("session")
+	email, _ := cmd.Flags().GetString("email")
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	device, _ := cmd.Flags().GetString("device")
+	readingsURL, _ := cmd.Flags().GetString("readings-url")
+	interval, _ := cmd.Flags().GetDuration("interval")
+
+	client, err := source.NewFirebaseHTTPSource(source.FirebaseHTTPConfig{
+		Email:       email,
+		Password:    os.Getenv("TWCHART_SOURCE_PASSWORD"),
+		APIKey:      apiKey,
+		Device:      device,
+		ReadingsURL: readingsURL,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating readings source: %w", err)
+	}
+
+	poller := &source.Poller{
+		Source:   client,
+		Sink:     source.SessionSink{ServerURL: serverURL, SessionID: sessionID},
+		Interval: interval,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	err = poller.Run(ctx)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func imageCommand(cmd *cobra.Command, args []string) error {
+	notesFilename, outputFilename := args[0], args[1]
+	dataFilename, _ := cmd.Flags().GetString("data")
+	width, _ := cmd.Flags().GetInt("width")
+	height, _ := cmd.Flags().GetInt("height")
+	dark, _ := cmd.Flags().GetBool("dark")
+	alignFlag, _ := cmd.Flags().GetString("align")
+
+	write := twchart.Session.WriteSVG
+	switch strings.ToLower(filepath.Ext(outputFilename)) {
+	case ".svg":
+	case ".png":
+		write = twchart.Session.WritePNG
+	default:
+		return fmt.Errorf("unsupported image format %q: use .svg or .png", filepath.Ext(outputFilename))
+	}
+
+	notes, err := os.ReadFile(notesFilename)
+	if err != nil {
+		return fmt.Errorf("error reading notes: %w", err)
+	}
+
+	var session twchart.Session
+	err = session.FromText(notes)
+	if err != nil {
+		return fmt.Errorf("error parsing notes: %w", err)
+	}
+
+	if dataFilename == "" {
+		dataFilename = strings.TrimSuffix(notesFilename, filepath.Ext(notesFilename)) + ".csv"
+	}
+	_, err = session.LoadDataFromFile(dataFilename, twchart.ImportOptions{})
+	if err != nil {
+		return fmt.Errorf("error loading Thermoworks data: %w", err)
+	}
+
+	theme := twchart.Themes(nil).Get(session.Type, dark)
+	chartOpts := twchart.ChartOptions{Theme: &theme}
+	if cmd.Flags().Changed("align") {
+		align, err := twchart.ParseAlignment(alignFlag)
+		if err != nil {
+			return err
+		}
+		chartOpts.ElapsedFrom, err = align.Origin(session)
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(outputFilename)
+	if err != nil {
+		return fmt.Errorf("error creating image file: %w", err)
+	}
+	defer f.Close()
+
+	err = write(session, f, chartOpts, twchart.ImageOptions{Width: width, Height: height})
+	if err != nil {
+		return fmt.Errorf("error drawing chart: %w", err)
+	}
+
+	return f.Close()
+}
+
 func dbMigrateCommand(cmd *cobra.Command, _ []string) error {
 	dbPath, _ := cmd.Flags().GetString("database")
 	migrationsPath, _ := cmd.Flags().GetString("migrations")
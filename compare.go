@@ -0,0 +1,254 @@
+package twchart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const alignmentStagePrefix = "stage:"
+
+var ErrStageNotFound = errors.New("stage not found")
+
+// Alignment chooses the time in each Session that is used as zero when comparing them
+type Alignment struct {
+	// Stage is the name of the stage whose start is used. If it is empty, the Session's start is used
+	Stage string
+}
+
+// ParseAlignment parses "start" or "stage:[name]". An empty string is the same as "start"
+func ParseAlignment(s string) (Alignment, error) {
+	switch {
+	case s == "" || strings.EqualFold(s, "start"):
+		return Alignment{}, nil
+	case strings.HasPrefix(strings.ToLower(s), alignmentStagePrefix):
+		stage := strings.TrimSpace(s[len(alignmentStagePrefix):])
+		if stage == "" {
+			return Alignment{}, errors.New("missing stage name for alignment")
+		}
+		return Alignment{Stage: stage}, nil
+	default:
+		return Alignment{}, fmt.Errorf("invalid alignment: %q", s)
+	}
+}
+
+func (a Alignment) String() string {
+	if a.Stage == "" {
+		return "start"
+	}
+	return alignmentStagePrefix + a.Stage
+}
+
+// Origin is the time in the Session that elapsed time is measured from. The Session's start is its StartTime, or the
+// first reading if it isn't set
+func (a Alignment) Origin(s Session) (time.Time, error) {
+	if a.Stage != "" {
+		for _, stage := range s.Stages {
+			if strings.EqualFold(stage.Name, a.Stage) {
+				return stage.Start, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%w: %q in %q", ErrStageNotFound, a.Stage, s.Name)
+	}
+
+	if !s.StartTime.IsZero() {
+		return s.StartTime, nil
+	}
+	if len(s.Data) > 0 {
+		return s.Data[0].Time, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %q has no start time", ErrNoData, s.Name)
+}
+
+// CompareOptions configures how Sessions are compared
+type CompareOptions struct {
+	// Probe is the name of the probe to compare. If it is empty, each Session's first probe is used
+	Probe string
+	Align Alignment
+	// MaxPoints limits the points in each Session's series like ChartOptions.MaxPoints
+	MaxPoints int
+}
+
+// ElapsedPoint is a reading at a time relative to the Alignment
+type ElapsedPoint struct {
+	Elapsed time.Duration
+	Value   float64
+	Missing bool
+}
+
+// elapsedPointJSON is how ElapsedPoint is encoded in JSON. Elapsed is in seconds
+type elapsedPointJSON struct {
+	Elapsed float64
+	Value   float64
+	Missing bool
+}
+
+func (p ElapsedPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(elapsedPointJSON{Elapsed: p.Elapsed.Seconds(), Value: p.Value, Missing: p.Missing})
+}
+
+// ElapsedStage is a stage with times relative to the Alignment. End is only set if the stage is Finished, since a
+// stage can end at the Alignment
+type ElapsedStage struct {
+	Name     string
+	Start    time.Duration
+	End      time.Duration
+	Finished bool
+}
+
+// elapsedStageJSON is how ElapsedStage is encoded in JSON. Start and End are in seconds and End is null if the stage
+// isn't finished
+type elapsedStageJSON struct {
+	Name  string
+	Start float64
+	End   *float64
+}
+
+func (s ElapsedStage) MarshalJSON() ([]byte, error) {
+	out := elapsedStageJSON{Name: s.Name, Start: s.Start.Seconds()}
+	if s.Finished {
+		end := s.End.Seconds()
+		out.End = &end
+	}
+	return json.Marshal(out)
+}
+
+// ComparisonSeries is one Session's data for a comparison
+type ComparisonSeries struct {
+	Name   string
+	Date   time.Time
+	Probe  string
+	Origin time.Time
+	Points []ElapsedPoint
+	Stages []ElapsedStage
+}
+
+// Label identifies the Session in the chart legend
+func (cs ComparisonSeries) Label() string {
+	return fmt.Sprintf("%s (%s)", cs.Name, cs.Date.Format(time.DateOnly))
+}
+
+// Compare aligns a probe from each Session so they can be charted together
+func Compare(sessions []Session, compareOpts CompareOptions) ([]ComparisonSeries, error) {
+	chartOpts := ChartOptions{MaxPoints: compareOpts.MaxPoints}
+
+	result := make([]ComparisonSeries, 0, len(sessions))
+	for _, s := range sessions {
+		var probe Probe
+		if compareOpts.Probe == "" {
+			if len(s.Probes) == 0 {
+				return nil, fmt.Errorf("%w: %q has no probes", ErrProbeNotFound, s.Name)
+			}
+			probe = s.Probes[0]
+		} else {
+			var err error
+			probe, err = s.FindProbe(compareOpts.Probe)
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, s.Name)
+			}
+		}
+
+		origin, err := compareOpts.Align.Origin(s)
+		if err != nil {
+			return nil, err
+		}
+
+		series := ComparisonSeries{Name: s.Name, Date: s.Date, Probe: probe.Name, Origin: origin}
+		for _, p := range Downsample(ProbeSeries(s.CleanData(), probe.Position), chartOpts.maxPoints()) {
+			series.Points = append(series.Points, ElapsedPoint{Elapsed: p.Time.Sub(origin), Value: p.Value, Missing: p.Missing})
+		}
+		for _, stage := range s.Stages {
+			elapsedStage := ElapsedStage{Name: stage.Name, Start: stage.Start.Sub(origin)}
+			if !stage.End.IsZero() {
+				elapsedStage.End = stage.End.Sub(origin)
+				elapsedStage.Finished = true
+			}
+			series.Stages = append(series.Stages, elapsedStage)
+		}
+
+		result = append(result, series)
+	}
+
+	return result, nil
+}
+
+// ComparisonChart creates a chart with a line for each ComparisonSeries. The x-axis is minutes from the Alignment
+// and each series marks the start and end of its stages. Ends are only marked when another stage doesn't start then
+func ComparisonChart(series []ComparisonSeries, theme Theme) *charts.Line {
+	echartsTheme := "white"
+	if theme.Dark {
+		echartsTheme = "dark"
+	}
+
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "100%",
+			Height: "80vh",
+			Theme:  echartsTheme,
+		}),
+		charts.WithXAxisOpts(opts.XAxis{
+			Name: "Minutes",
+			Type: "value",
+			AxisPointer: &opts.AxisPointer{
+				Show: opts.Bool(true),
+				Snap: opts.Bool(false),
+			},
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:    opts.Bool(true),
+			Trigger: "item",
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithDataZoomOpts(
+			opts.DataZoom{Type: "slider", Start: 0, End: 100, Orient: "horizontal"},
+			opts.DataZoom{Type: "slider", Start: 0, End: 100, Orient: "vertical"},
+		),
+	)
+
+	for i, cs := range series {
+		data := make([]opts.LineData, 0, len(cs.Points))
+		for _, p := range cs.Points {
+			if p.Missing {
+				data = append(data, opts.LineData{Value: []any{p.Elapsed.Minutes(), nil}})
+				continue
+			}
+			data = append(data, opts.LineData{Value: []any{p.Elapsed.Minutes(), p.Value}})
+		}
+
+		stages := make([]opts.MarkLineNameXAxisItem, 0, len(cs.Stages))
+		starts := map[time.Duration]bool{}
+		for _, stage := range cs.Stages {
+			stages = append(stages, opts.MarkLineNameXAxisItem{Name: stage.Name, XAxis: stage.Start.Minutes()})
+			starts[stage.Start] = true
+		}
+		for _, stage := range cs.Stages {
+			if stage.Finished && !starts[stage.End] {
+				stages = append(stages, opts.MarkLineNameXAxisItem{Name: stage.Name + " end", XAxis: stage.End.Minutes()})
+			}
+		}
+
+		seriesOpts := append([]charts.SeriesOpts{
+			charts.WithLineChartOpts(opts.LineChart{
+				Smooth:       opts.Bool(true),
+				ShowSymbol:   opts.Bool(false),
+				ConnectNulls: opts.Bool(false),
+			}),
+			charts.WithMarkLineNameXAxisItemOpts(stages...),
+			charts.WithMarkLineStyleOpts(opts.MarkLineStyle{
+				Symbol:    []string{"none", "none"},
+				LineStyle: &opts.LineStyle{Type: "dashed", Color: theme.ProbeColor(i)},
+				Label:     &opts.Label{Show: opts.Bool(true), Formatter: "{b}"},
+			}),
+		}, seriesColorOpts(theme.ProbeColor(i))...)
+
+		line.AddSeries(cs.Label(), data, seriesOpts...)
+	}
+
+	return line
+}
@@ -0,0 +1,186 @@
+package twchart
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compareSession(name string, start time.Time, developmentAt time.Duration) Session {
+	s := Session{
+		Name:      name,
+		Date:      start.Truncate(24 * time.Hour),
+		Type:      SessionTypeCoffee,
+		StartTime: start,
+		Probes:    []Probe{{Name: "Ambient", Position: ProbePosition1}, {Name: "Bean", Position: ProbePosition2}},
+		Stages: []Stage{
+			{Name: "Drying", Start: start, End: start.Add(developmentAt)},
+			{Name: "Development", Start: start.Add(developmentAt)},
+		},
+	}
+	for i := range 11 {
+		s.Data = append(s.Data, ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Minute),
+			ProbeData: []float64{400, 100 + float64(i)*30},
+		})
+	}
+	return s
+}
+
+func TestParseAlignment(t *testing.T) {
+	tests := []struct {
+		in          string
+		expected    Alignment
+		expectedErr string
+	}{
+		{"", Alignment{}, ""},
+		{"start", Alignment{}, ""},
+		{"Start", Alignment{}, ""},
+		{"stage:Development", Alignment{Stage: "Development"}, ""},
+		{"Stage: First Crack", Alignment{Stage: "First Crack"}, ""},
+		{"stage:", Alignment{}, "missing stage name for alignment"},
+		{"middle", Alignment{}, `invalid alignment: "middle"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			align, err := ParseAlignment(tt.in)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, align)
+		})
+	}
+
+	assert.Equal(t, "start", Alignment{}.String())
+	assert.Equal(t, "stage:Development", Alignment{Stage: "Development"}.String())
+}
+
+func TestAlignmentOrigin(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast", start, 6*time.Minute)
+
+	origin, err := Alignment{}.Origin(s)
+	require.NoError(t, err)
+	assert.Equal(t, start, origin)
+
+	origin, err = Alignment{Stage: "development"}.Origin(s)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(6*time.Minute), origin)
+
+	_, err = Alignment{Stage: "Cooling"}.Origin(s)
+	require.ErrorIs(t, err, ErrStageNotFound)
+
+	// the first reading is used without a StartTime
+	s.StartTime = time.Time{}
+	s.Data = s.Data[2:]
+	origin, err = Alignment{}.Origin(s)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(2*time.Minute), origin)
+
+	_, err = Alignment{}.Origin(Session{Name: "Empty"})
+	require.ErrorIs(t, err, ErrNoData)
+}
+
+func TestCompare(t *testing.T) {
+	first := compareSession("Roast 1", time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC), 4*time.Minute)
+	second := compareSession("Roast 2", time.Date(2025, time.May, 24, 9, 0, 0, 0, time.UTC), 6*time.Minute)
+
+	t.Run("AlignStart", func(t *testing.T) {
+		series, err := Compare([]Session{first, second}, CompareOptions{Probe: "bean"})
+		require.NoError(t, err)
+		require.Len(t, series, 2)
+
+		assert.Equal(t, "Roast 1 (2025-05-21)", series[0].Label())
+		assert.Equal(t, "Bean", series[0].Probe)
+		assert.Equal(t, ElapsedPoint{Elapsed: 0, Value: 100}, series[0].Points[0])
+		assert.Equal(t, ElapsedPoint{Elapsed: 10 * time.Minute, Value: 400}, series[1].Points[10])
+		assert.Equal(t, []ElapsedStage{
+			{Name: "Drying", Start: 0, End: 6 * time.Minute, Finished: true},
+			{Name: "Development", Start: 6 * time.Minute},
+		}, series[1].Stages)
+
+		// times are seconds in JSON
+		points, err := json.Marshal(series[1].Points[10])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Elapsed": 600, "Value": 400, "Missing": false}`, string(points))
+
+		stages, err := json.Marshal(series[1].Stages)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"Name": "Drying", "Start": 0, "End": 360}, {"Name": "Development", "Start": 360, "End": null}]`, string(stages))
+	})
+
+	t.Run("AlignStage", func(t *testing.T) {
+		series, err := Compare([]Session{first, second}, CompareOptions{Align: Alignment{Stage: "Development"}})
+		require.NoError(t, err)
+
+		// the first probe is used by default
+		assert.Equal(t, "Ambient", series[0].Probe)
+		assert.Equal(t, -4*time.Minute, series[0].Points[0].Elapsed)
+		assert.Equal(t, -6*time.Minute, series[1].Points[0].Elapsed)
+		assert.Equal(t, time.Duration(0), series[0].Stages[1].Start)
+		assert.Equal(t, time.Duration(0), series[1].Stages[1].Start)
+
+		// the first stage ends at the Alignment
+		assert.Equal(t, ElapsedStage{Name: "Drying", Start: -4 * time.Minute, Finished: true}, series[0].Stages[0])
+	})
+
+	t.Run("MaxPoints", func(t *testing.T) {
+		series, err := Compare([]Session{first}, CompareOptions{MaxPoints: 5})
+		require.NoError(t, err)
+		assert.Len(t, series[0].Points, 5)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := Compare([]Session{first}, CompareOptions{Probe: "Pit"})
+		require.ErrorIs(t, err, ErrProbeNotFound)
+
+		_, err = Compare([]Session{first, {Name: "No Probes"}}, CompareOptions{})
+		require.ErrorIs(t, err, ErrProbeNotFound)
+
+		_, err = Compare([]Session{first}, CompareOptions{Align: Alignment{Stage: "Cooling"}})
+		require.ErrorIs(t, err, ErrStageNotFound)
+	})
+}
+
+func TestComparisonChart(t *testing.T) {
+	first := compareSession("Roast 1", time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC), 4*time.Minute)
+	second := compareSession("Roast 2", time.Date(2025, time.May, 24, 9, 0, 0, 0, time.UTC), 6*time.Minute)
+
+	series, err := Compare([]Session{first, second}, CompareOptions{Probe: "Bean"})
+	require.NoError(t, err)
+
+	line := ComparisonChart(series, DefaultTheme(true))
+	require.Len(t, line.MultiSeries, 2)
+	assert.Equal(t, "Roast 2 (2025-05-24)", line.MultiSeries[1].Name)
+	assert.Equal(t, "dark", line.Initialization.Theme)
+
+	// the end of Drying is the start of Development, so it isn't marked again
+	marks := line.MultiSeries[1].MarkLines.Data
+	require.Len(t, marks, 2)
+	assert.Equal(t, opts.MarkLineNameXAxisItem{Name: "Development", XAxis: 6.0}, marks[1])
+
+	t.Run("StageEnds", func(t *testing.T) {
+		series := []ComparisonSeries{{
+			Name: "Bread",
+			Stages: []ElapsedStage{
+				{Name: "Proof", Start: 0, End: time.Hour, Finished: true},
+				{Name: "Bake", Start: 90 * time.Minute, End: 2 * time.Hour, Finished: true},
+			},
+		}}
+
+		marks := ComparisonChart(series, DefaultTheme(false)).MultiSeries[0].MarkLines.Data
+		assert.Equal(t, []any{
+			opts.MarkLineNameXAxisItem{Name: "Proof", XAxis: 0.0},
+			opts.MarkLineNameXAxisItem{Name: "Bake", XAxis: 90.0},
+			opts.MarkLineNameXAxisItem{Name: "Proof end", XAxis: 60.0},
+			opts.MarkLineNameXAxisItem{Name: "Bake end", XAxis: 120.0},
+		}, marks)
+	})
+}
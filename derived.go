@@ -0,0 +1,151 @@
+package twchart
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	defaultRateOfRiseWindow    = 30 * time.Second
+	defaultRateOfRiseSmoothing = 5
+)
+
+// DerivedSeries is a series that is computed from a Session's data instead of being recorded
+type DerivedSeries interface {
+	// Name is used as the series name on the chart
+	Name() string
+	// Measurement is the kind and unit of the series' values. Series with the same unit share a Y axis
+	Measurement() Measurement
+	// Compute creates the series from the Session's cleaned data
+	Compute(s Session) []Point
+}
+
+// DerivedSeries returns the default derived series for the Session's type
+func (s Session) DerivedSeries() []DerivedSeries {
+	switch s.Type {
+	case SessionTypeCoffee:
+		for _, p := range s.Probes {
+			if strings.Contains(strings.ToLower(p.Name), "bean") {
+				return []DerivedSeries{RateOfRise{Probe: p}}
+			}
+		}
+	}
+	return nil
+}
+
+// RateOfRise calculates the change in a probe's temperature in degrees per minute. It is most useful for the bean
+// temperature when roasting coffee
+type RateOfRise struct {
+	Probe Probe
+
+	// Window is how far back to look when calculating the rate. A longer window reduces noise, but responds slower
+	// to changes. Defaults to 30s
+	Window time.Duration
+	// Smoothing is the number of rate values that are averaged. Defaults to 5. Use a negative value to disable
+	Smoothing int
+}
+
+var _ DerivedSeries = RateOfRise{}
+
+func (ror RateOfRise) Name() string {
+	return ror.Probe.Name + " RoR"
+}
+
+func (ror RateOfRise) Measurement() Measurement {
+	return Measurement{Kind: MeasurementRate, Unit: ror.Probe.Measurement.withDefaults().Unit + "/min"}
+}
+
+func (ror RateOfRise) Compute(s Session) []Point {
+	window := ror.Window
+	if window <= 0 {
+		window = defaultRateOfRiseWindow
+	}
+	smoothing := ror.Smoothing
+	if smoothing == 0 {
+		smoothing = defaultRateOfRiseSmoothing
+	}
+
+	readings := ProbeSeries(s.CleanData(), ror.Probe.Position)
+
+	result := make([]Point, 0, len(readings))
+	addMissing := func(t time.Time) {
+		if len(result) > 0 && !result[len(result)-1].Valid() {
+			return
+		}
+		result = append(result, Point{Time: t, Missing: true})
+	}
+
+	prev := 0
+	for _, p := range readings {
+		if !p.Valid() {
+			addMissing(p.Time)
+			continue
+		}
+
+		// find the earliest valid reading within the window
+		for prev < len(readings) && (!readings[prev].Valid() || p.Time.Sub(readings[prev].Time) > window) {
+			prev++
+		}
+
+		elapsed := p.Time.Sub(readings[prev].Time)
+		if elapsed < window/2 {
+			addMissing(p.Time)
+			continue
+		}
+
+		result = append(result, Point{
+			Time:  p.Time,
+			Value: (p.Value - readings[prev].Value) / elapsed.Minutes(),
+		})
+	}
+
+	if smoothing > 1 {
+		result = smoothPoints(result, smoothing)
+	}
+
+	return result
+}
+
+// smoothPoints applies a trailing moving average to the valid points. The average does not include points before a
+// missing point
+func smoothPoints(points []Point, window int) []Point {
+	result := make([]Point, len(points))
+	var sum float64
+	count := 0
+	for i, p := range points {
+		if !p.Valid() {
+			sum, count = 0, 0
+			result[i] = p
+			continue
+		}
+
+		sum += p.Value
+		count++
+		if count > window {
+			sum -= points[i-window].Value
+			count = window
+		}
+
+		result[i] = Point{Time: p.Time, Value: sum / float64(count)}
+	}
+	return result
+}
+
+// StageAverage returns the average of the valid points within the Stage. If the Stage is not finished, all points
+// after the start are used. The second return value is false if there are no points in the Stage
+func StageAverage(points []Point, stage Stage) (float64, bool) {
+	var sum float64
+	count := 0
+	for _, p := range points {
+		if !p.Valid() || p.Time.Before(stage.Start) || (!stage.End.IsZero() && p.Time.After(stage.End)) {
+			continue
+		}
+		sum += p.Value
+		count++
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
@@ -0,0 +1,54 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateOfRise(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local)
+	bean := Probe{Name: "Bean", Position: ProbePosition2}
+	s := Session{
+		Type:   SessionTypeCoffee,
+		Probes: []Probe{{Name: "Ambient", Position: ProbePosition1}, bean},
+	}
+	// bean temperature rises 10 degrees per minute
+	for i := range 120 {
+		s.Data = append(s.Data, ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Second),
+			ProbeData: []float64{70, 100 + float64(i)/6},
+		})
+	}
+
+	t.Run("DefaultSeries", func(t *testing.T) {
+		assert.Equal(t, []DerivedSeries{RateOfRise{Probe: bean}}, s.DerivedSeries())
+		assert.Nil(t, Session{Type: SessionTypeBread, Probes: s.Probes}.DerivedSeries())
+	})
+
+	t.Run("Compute", func(t *testing.T) {
+		ror := RateOfRise{Probe: bean}
+		assert.Equal(t, "Bean RoR", ror.Name())
+
+		points := ror.Compute(s)
+		assert.Len(t, points, 106)
+
+		// not enough data at the start
+		assert.False(t, points[0].Valid())
+		for _, p := range points[1:] {
+			assert.True(t, p.Valid())
+			assert.InDelta(t, 10, p.Value, 0.0001)
+		}
+	})
+
+	t.Run("StageAverage", func(t *testing.T) {
+		points := RateOfRise{Probe: bean, Smoothing: -1}.Compute(s)
+		avg, ok := StageAverage(points, Stage{Start: start.Add(30 * time.Second), End: start.Add(time.Minute)})
+		assert.True(t, ok)
+		assert.InDelta(t, 10, avg, 0.0001)
+
+		_, ok = StageAverage(points, Stage{Start: start.Add(time.Hour)})
+		assert.False(t, ok)
+	})
+}
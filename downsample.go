@@ -0,0 +1,148 @@
+package twchart
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// DefaultMaxPoints is the number of points that each probe's series is downsampled to for charting
+const DefaultMaxPoints = 2000
+
+// Point is a single value in a series
+type Point struct {
+	Time    time.Time
+	Value   float64
+	Missing bool
+}
+
+// Valid returns true if the Point is not a missing reading
+func (p Point) Valid() bool {
+	return !p.Missing
+}
+
+// lineData creates the chart data for the Point using x as its x-axis value
+func (p Point) lineData(x any) opts.LineData {
+	if !p.Valid() {
+		return opts.LineData{Value: []any{x, nil}}
+	}
+	return opts.LineData{Value: []any{x, p.Value}}
+}
+
+// ProbeSeries extracts the readings for a single probe. Consecutive missing readings are combined into one
+func ProbeSeries(data []ThermoworksData, pos ProbePosition) []Point {
+	result := make([]Point, 0, len(data))
+	for _, d := range data {
+		p := Point{Time: d.Time, Missing: true}
+		if d.HasProbeData(pos) {
+			p = Point{Time: d.Time, Value: d.GetProbeData(pos)}
+		}
+
+		if !p.Valid() && len(result) > 0 && !result[len(result)-1].Valid() {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// Downsample reduces the series to approximately maxPoints using Largest-Triangle-Three-Buckets. Each continuous
+// run of readings is downsampled separately so missing readings are kept
+func Downsample(points []Point, maxPoints int) []Point {
+	if maxPoints <= 0 || len(points) <= maxPoints {
+		return points
+	}
+
+	var runs [][]Point
+	start := -1
+	numValid := 0
+	for i, p := range points {
+		if p.Valid() {
+			numValid++
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start != -1 {
+			runs = append(runs, points[start:i])
+			start = -1
+		}
+		runs = append(runs, points[i:i+1])
+	}
+	if start != -1 {
+		runs = append(runs, points[start:])
+	}
+
+	// missing readings are kept, so they are subtracted from the budget for valid readings
+	budget := max(maxPoints-(len(points)-numValid), 0)
+
+	result := make([]Point, 0, maxPoints)
+	for _, run := range runs {
+		if !run[0].Valid() {
+			result = append(result, run...)
+			continue
+		}
+
+		threshold := int(math.Round(float64(budget) * float64(len(run)) / float64(numValid)))
+		result = append(result, LTTB(run, threshold)...)
+	}
+	return result
+}
+
+// LTTB downsamples points to threshold points using the Largest-Triangle-Three-Buckets algorithm. The first and last
+// points are always kept. All points are expected to be valid
+func LTTB(points []Point, threshold int) []Point {
+	if threshold >= len(points) || len(points) <= 2 {
+		return points
+	}
+	if threshold < 2 {
+		threshold = 2
+	}
+	if threshold == 2 {
+		return []Point{points[0], points[len(points)-1]}
+	}
+
+	x := func(p Point) float64 {
+		return float64(p.Time.UnixMilli())
+	}
+
+	result := make([]Point, 0, threshold)
+	result = append(result, points[0])
+
+	// the first and last points are their own buckets
+	bucketSize := float64(len(points)-2) / float64(threshold-2)
+
+	selected := 0
+	for i := range threshold - 2 {
+		bucketStart := int(math.Floor(float64(i)*bucketSize)) + 1
+		bucketEnd := int(math.Floor(float64(i+1)*bucketSize)) + 1
+
+		// average of the next bucket is the third point of the triangle
+		nextStart := bucketEnd
+		nextEnd := min(int(math.Floor(float64(i+2)*bucketSize))+1, len(points))
+		var avgX, avgY float64
+		for _, p := range points[nextStart:nextEnd] {
+			avgX += x(p)
+			avgY += p.Value
+		}
+		n := float64(nextEnd - nextStart)
+		avgX /= n
+		avgY /= n
+
+		a := points[selected]
+		maxArea := -1.0
+		for j := bucketStart; j < bucketEnd; j++ {
+			area := math.Abs((x(a)-avgX)*(points[j].Value-a.Value) - (x(a)-x(points[j]))*(avgY-a.Value))
+			if area > maxArea {
+				maxArea = area
+				selected = j
+			}
+		}
+
+		result = append(result, points[selected])
+	}
+
+	return append(result, points[len(points)-1])
+}
@@ -0,0 +1,95 @@
+package twchart
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPoints(n int, value func(int) float64) []Point {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local)
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = Point{Time: start.Add(time.Duration(i) * time.Second)}
+		if v := value(i); math.IsNaN(v) {
+			points[i].Missing = true
+		} else {
+			points[i].Value = v
+		}
+	}
+	return points
+}
+
+func TestLTTB(t *testing.T) {
+	t.Run("KeepsEndsAndPeak", func(t *testing.T) {
+		points := testPoints(1000, func(i int) float64 {
+			if i == 500 {
+				return 400
+			}
+			return 100
+		})
+
+		result := LTTB(points, 50)
+		assert.Len(t, result, 50)
+		assert.Equal(t, points[0], result[0])
+		assert.Equal(t, points[999], result[49])
+		assert.Contains(t, result, points[500])
+	})
+
+	t.Run("SmallInput", func(t *testing.T) {
+		points := testPoints(10, func(i int) float64 { return float64(i + 1) })
+		assert.Equal(t, points, LTTB(points, 20))
+		assert.Equal(t, []Point{points[0], points[9]}, LTTB(points, 1))
+	})
+
+	t.Run("Ordered", func(t *testing.T) {
+		points := testPoints(5000, func(i int) float64 { return 100 + 50*math.Sin(float64(i)/100) })
+		result := LTTB(points, 300)
+		assert.Len(t, result, 300)
+		for i := 1; i < len(result); i++ {
+			assert.True(t, result[i].Time.After(result[i-1].Time))
+		}
+	})
+}
+
+func TestDownsample(t *testing.T) {
+	points := testPoints(1000, func(i int) float64 {
+		if i >= 400 && i < 410 {
+			return missingReading
+		}
+		return 100 + float64(i%7)
+	})
+
+	result := Downsample(points, 100)
+	assert.LessOrEqual(t, len(result), 100)
+
+	missing := 0
+	for _, p := range result {
+		if !p.Valid() {
+			missing++
+		}
+	}
+	assert.Equal(t, 10, missing)
+	assert.Equal(t, points, Downsample(points, -1))
+}
+
+func TestProbeSeries(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local)
+	data := []ThermoworksData{
+		{Time: start, ProbeData: []float64{100, 1}},
+		{Time: start.Add(time.Second), ProbeData: []float64{missingReading, 1}},
+		{Time: start.Add(2 * time.Second), ProbeData: []float64{missingReading, 1}},
+		{Time: start.Add(3 * time.Second), ProbeData: []float64{0, 1}},
+		{Time: start.Add(4 * time.Second), ProbeData: []float64{101, 1}},
+	}
+
+	// zero is a reading, like a fan that is off
+	assert.Equal(t, []Point{
+		{Time: start, Value: 100},
+		{Time: start.Add(time.Second), Missing: true},
+		{Time: start.Add(3 * time.Second), Value: 0},
+		{Time: start.Add(4 * time.Second), Value: 101},
+	}, ProbeSeries(data, ProbePosition1))
+}
@@ -0,0 +1,247 @@
+package twchart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultETAWindow = 30 * time.Minute
+	minETASamples    = 5
+)
+
+// ETAMethod is the type of curve that was fit to the data to estimate the ETA
+type ETAMethod string
+
+const (
+	// ETAMethodExponential fits an exponential approach to the ambient temperature (Newton's law of heating)
+	ETAMethodExponential ETAMethod = "exponential"
+	// ETAMethodLinear extrapolates the trend of the recent data
+	ETAMethodLinear ETAMethod = "linear"
+)
+
+var (
+	ErrNoTarget          = errors.New("probe has no target")
+	ErrTargetUnreachable = errors.New("target is not reachable with the current trend")
+)
+
+// ETAOptions configures how the ETA is estimated
+type ETAOptions struct {
+	// Window is how much of the most recent data is used to fit the curve. Defaults to 30m
+	Window time.Duration
+}
+
+// ETA is the estimated time that a probe will reach its target
+type ETA struct {
+	Probe Probe
+
+	// Current is the last reading and CurrentTime is when it was recorded
+	Current     float64
+	CurrentTime time.Time
+
+	// Reached is true if the target was already reached. In this case, Time is when it was first reached
+	Reached bool
+
+	// Time is the estimated time the target is reached and Remaining is the time from the last reading until then
+	Time      time.Time
+	Remaining time.Duration
+	Method    ETAMethod
+}
+
+// ETAs estimates when each probe with a Target will reach it. Probes that can't be estimated are not included
+func (s Session) ETAs(etaOpts ETAOptions) []ETA {
+	result := []ETA{}
+	for _, p := range s.Probes {
+		if p.Target == 0 {
+			continue
+		}
+
+		eta, err := s.EstimateETA(p, etaOpts)
+		if err != nil {
+			continue
+		}
+		result = append(result, eta)
+	}
+	return result
+}
+
+// EstimateETA estimates when the probe will reach its Target using the most recent data. An exponential approach to
+// the ambient probe's temperature is used when possible, otherwise the recent trend is extrapolated linearly
+func (s Session) EstimateETA(probe Probe, etaOpts ETAOptions) (ETA, error) {
+	if probe.Target == 0 {
+		return ETA{}, fmt.Errorf("%w: %q", ErrNoTarget, probe.Name)
+	}
+
+	window := etaOpts.Window
+	if window <= 0 {
+		window = defaultETAWindow
+	}
+
+	data := s.CleanData()
+	readings := validPoints(ProbeSeries(data, probe.Position))
+	if len(readings) == 0 {
+		return ETA{}, fmt.Errorf("%w: %q", ErrNoData, probe.Name)
+	}
+
+	last := readings[len(readings)-1]
+	eta := ETA{Probe: probe, Current: last.Value, CurrentTime: last.Time}
+
+	for _, r := range readings {
+		if r.Value >= probe.Target {
+			eta.Reached = true
+			eta.Time = r.Time
+			return eta, nil
+		}
+	}
+
+	recent := recentPoints(readings, window)
+	if len(recent) < minETASamples {
+		return ETA{}, fmt.Errorf("%w: not enough recent readings for %q", ErrNoData, probe.Name)
+	}
+
+	var remaining time.Duration
+	var ok bool
+	if ambient, hasAmbient := s.ambientTemperature(data, window, probe.Position); hasAmbient {
+		// the food can't get hotter than the cooker
+		if ambient <= probe.Target {
+			return ETA{}, fmt.Errorf("%w: %q target is above the ambient temperature", ErrTargetUnreachable, probe.Name)
+		}
+		remaining, ok = exponentialETA(recent, ambient, probe.Target)
+		eta.Method = ETAMethodExponential
+	}
+	if !ok {
+		remaining, ok = linearETA(recent, probe.Target)
+		eta.Method = ETAMethodLinear
+	}
+	if !ok {
+		return ETA{}, fmt.Errorf("%w: %q", ErrTargetUnreachable, probe.Name)
+	}
+
+	eta.Remaining = remaining
+	eta.Time = last.Time.Add(remaining)
+	return eta, nil
+}
+
+// ambientTemperature is the average of the ambient probes' recent readings. The probe at the excluded position isn't
+// included, so an ambient probe with a Target isn't compared to itself
+func (s Session) ambientTemperature(data []ThermoworksData, window time.Duration, exclude ProbePosition) (float64, bool) {
+	var sum float64
+	count := 0
+	for _, p := range s.Probes {
+		if !p.IsAmbient() || p.Position == exclude {
+			continue
+		}
+		for _, r := range recentPoints(validPoints(ProbeSeries(data, p.Position)), window) {
+			sum += r.Value
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+func validPoints(points []Point) []Point {
+	result := make([]Point, 0, len(points))
+	for _, p := range points {
+		if p.Valid() {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// recentPoints returns the points within the window before the last point
+func recentPoints(points []Point, window time.Duration) []Point {
+	if len(points) == 0 {
+		return points
+	}
+
+	start := points[len(points)-1].Time.Add(-window)
+	for i, p := range points {
+		if !p.Time.Before(start) {
+			return points[i:]
+		}
+	}
+	return nil
+}
+
+// exponentialETA fits T(t) = ambient - (ambient - T0) * e^(-kt) by using a linear regression of ln(ambient - T) and
+// returns the time after the last point when T reaches the target
+func exponentialETA(points []Point, ambient, target float64) (time.Duration, bool) {
+	last := points[len(points)-1].Time
+	xs := make([]float64, 0, len(points))
+	ys := make([]float64, 0, len(points))
+	for _, p := range points {
+		if p.Value >= ambient {
+			return 0, false
+		}
+		xs = append(xs, p.Time.Sub(last).Minutes())
+		ys = append(ys, math.Log(ambient-p.Value))
+	}
+
+	slope, intercept, ok := linearRegression(xs, ys)
+	// the slope is -k, so it must be negative for the temperature to approach ambient
+	if !ok || slope >= 0 {
+		return 0, false
+	}
+
+	minutes := (math.Log(ambient-target) - intercept) / slope
+	return etaDuration(minutes)
+}
+
+// linearETA extrapolates a linear regression of the points and returns the time after the last point when it reaches
+// the target
+func linearETA(points []Point, target float64) (time.Duration, bool) {
+	last := points[len(points)-1].Time
+	xs := make([]float64, 0, len(points))
+	ys := make([]float64, 0, len(points))
+	for _, p := range points {
+		xs = append(xs, p.Time.Sub(last).Minutes())
+		ys = append(ys, p.Value)
+	}
+
+	slope, intercept, ok := linearRegression(xs, ys)
+	if !ok || slope <= 0 {
+		return 0, false
+	}
+
+	minutes := (target - intercept) / slope
+	return etaDuration(minutes)
+}
+
+func etaDuration(minutes float64) (time.Duration, bool) {
+	if math.IsNaN(minutes) || math.IsInf(minutes, 0) {
+		return 0, false
+	}
+	return time.Duration(max(minutes, 0) * float64(time.Minute)).Round(time.Second), true
+}
+
+// linearRegression calculates the least squares fit of y = slope*x + intercept
+func linearRegression(xs, ys []float64) (float64, float64, bool) {
+	n := float64(len(xs))
+	if n < 2 {
+		return 0, 0, false
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	return slope, intercept, true
+}
@@ -0,0 +1,102 @@
+package twchart
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testETASession(ambient bool) Session {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+	s := Session{
+		Probes: []Probe{
+			{Name: "Pit", Position: ProbePosition1},
+			{Name: "Pork", Position: ProbePosition2, Target: 195},
+		},
+	}
+
+	// the meat follows Newton's law of heating towards 250 with k = 0.01/min for 2 hours
+	for i := range 120 {
+		pit := 250.0
+		if !ambient {
+			pit = missingReading
+		}
+		s.Data = append(s.Data, ThermoworksData{
+			Time:      start.Add(time.Duration(i) * time.Minute),
+			ProbeData: []float64{pit, 250 - 210*math.Exp(-0.01*float64(i))},
+		})
+	}
+
+	return s
+}
+
+func TestEstimateETA(t *testing.T) {
+	// 250 - 210e^(-0.01t) = 195 at t = 100 * ln(210/55) = 134 minutes
+	expected := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local).Add(134 * time.Minute)
+
+	t.Run("Exponential", func(t *testing.T) {
+		s := testETASession(true)
+
+		eta, err := s.EstimateETA(s.Probes[1], ETAOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, ETAMethodExponential, eta.Method)
+		assert.False(t, eta.Reached)
+		assert.WithinDuration(t, expected, eta.Time, time.Minute)
+		assert.Equal(t, eta.Time.Sub(eta.CurrentTime), eta.Remaining)
+	})
+
+	t.Run("LinearFallback", func(t *testing.T) {
+		s := testETASession(false)
+
+		eta, err := s.EstimateETA(s.Probes[1], ETAOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, ETAMethodLinear, eta.Method)
+		// a linear trend underestimates the time since the rate is slowing down
+		assert.True(t, eta.Time.Before(expected))
+		assert.True(t, eta.Time.After(eta.CurrentTime))
+	})
+
+	t.Run("Reached", func(t *testing.T) {
+		s := testETASession(true)
+		s.Probes[1].Target = 150
+
+		eta, err := s.EstimateETA(s.Probes[1], ETAOptions{})
+		require.NoError(t, err)
+		assert.True(t, eta.Reached)
+		// 250 - 210e^(-0.01t) = 150 at t = 74.2 minutes
+		assert.Equal(t, time.Date(2025, time.May, 24, 9, 15, 0, 0, time.Local), eta.Time)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		s := testETASession(true)
+		s.Probes[1].Target = 300
+
+		_, err := s.EstimateETA(s.Probes[1], ETAOptions{})
+		assert.ErrorIs(t, err, ErrTargetUnreachable)
+	})
+
+	t.Run("AmbientProbe", func(t *testing.T) {
+		// the pit is rising by 1 degree per minute and it isn't used as its own ambient temperature
+		s := testETASession(false)
+		s.Probes[0].Target = 350
+		for i := range s.Data {
+			s.Data[i].ProbeData[0] = 200 + float64(i)
+		}
+
+		eta, err := s.EstimateETA(s.Probes[0], ETAOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, ETAMethodLinear, eta.Method)
+		assert.InDelta(t, 31, eta.Remaining.Minutes(), 0.5)
+	})
+
+	t.Run("NoTarget", func(t *testing.T) {
+		s := testETASession(true)
+
+		_, err := s.EstimateETA(s.Probes[0], ETAOptions{})
+		assert.ErrorIs(t, err, ErrNoTarget)
+		assert.Len(t, s.ETAs(ETAOptions{}), 1)
+	})
+}
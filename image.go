@@ -0,0 +1,544 @@
+package twchart
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultImageWidth  = 1200
+	DefaultImageHeight = 600
+
+	// MinImageWidth and MinImageHeight leave room for the plot inside the title, legend, and axis labels
+	MinImageWidth  = 200
+	MinImageHeight = 150
+
+	// maxEventLabel is the number of characters of an Event's note that are shown on an image
+	maxEventLabel = 24
+)
+
+// ImageOptions configures a static chart image
+type ImageOptions struct {
+	// Width and Height are the size of the image in pixels. If they are 0, DefaultImageWidth and
+	// DefaultImageHeight are used. Smaller sizes are increased to MinImageWidth and MinImageHeight
+	Width  int
+	Height int
+}
+
+func (o ImageOptions) size() (int, int) {
+	width, height := o.Width, o.Height
+	if width <= 0 {
+		width = DefaultImageWidth
+	}
+	if height <= 0 {
+		height = DefaultImageHeight
+	}
+	return max(width, MinImageWidth), max(height, MinImageHeight)
+}
+
+// WriteSVG draws the Session's chart as an SVG image. It has the probes, stages, stalls, and events from the
+// interactive Chart, but not the DerivedSeries
+func (s Session) WriteSVG(w io.Writer, chartOpts ChartOptions, imgOpts ImageOptions) error {
+	chart, err := s.staticChart(chartOpts, imgOpts)
+	if err != nil {
+		return err
+	}
+
+	svg := newSVGCanvas(chart.width, chart.height, chart.background)
+	chart.draw(svg)
+	_, err = svg.WriteTo(w)
+	return err
+}
+
+// WritePNG draws the same image as WriteSVG as a PNG. Text is drawn with the Go Regular font
+func (s Session) WritePNG(w io.Writer, chartOpts ChartOptions, imgOpts ImageOptions) error {
+	chart, err := s.staticChart(chartOpts, imgOpts)
+	if err != nil {
+		return err
+	}
+
+	raster := newRasterCanvas(chart.width, chart.height, chart.background)
+	chart.draw(raster)
+	return raster.encode(w)
+}
+
+// vec is a position on the image in pixels
+type vec struct {
+	x, y float64
+}
+
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas draws shapes for a staticChart. It is implemented for SVG and PNG
+type canvas interface {
+	fillRect(min, max vec, c color.NRGBA)
+	polyline(points []vec, c color.NRGBA, width float64, dashed bool)
+	// text draws s with its baseline at p
+	text(p vec, s string, c color.NRGBA, size float64, anchor textAnchor)
+}
+
+type tick struct {
+	value float64
+	label string
+}
+
+// staticAxis is the Y axis for the series with the same unit. The first axis is on the left of the plot and the
+// others are on the right, like the interactive Chart
+type staticAxis struct {
+	label      string
+	yMin, yMax float64
+	ticks      []tick
+}
+
+type staticSeries struct {
+	name  string
+	color color.NRGBA
+	axis  int
+	// segments are broken up by missing readings
+	segments [][]vec
+}
+
+type staticBand struct {
+	label    string
+	color    color.NRGBA
+	from, to float64
+}
+
+type staticEvent struct {
+	label string
+	x     float64
+}
+
+// staticChart is the layout of a chart image. X values are seconds, either Unix time or elapsed time
+type staticChart struct {
+	title         string
+	width, height int
+	// plotMin and plotMax are the top left and bottom right corners of the plot area
+	plotMin, plotMax vec
+	xMin, xMax       float64
+	xTicks           []tick
+	axes             []staticAxis
+
+	series []staticSeries
+	bands  []staticBand
+	events []staticEvent
+
+	background, foreground, grid color.NRGBA
+}
+
+func (c staticChart) xPixel(x float64) float64 {
+	return c.plotMin.x + (x-c.xMin)/(c.xMax-c.xMin)*(c.plotMax.x-c.plotMin.x)
+}
+
+// toPixel converts a point on the axis to its position on the image
+func (c staticChart) toPixel(x, y float64, axis int) vec {
+	a := c.axes[axis]
+	return vec{
+		x: c.xPixel(x),
+		y: c.plotMax.y - (y-a.yMin)/(a.yMax-a.yMin)*(c.plotMax.y-c.plotMin.y),
+	}
+}
+
+// rightAxisX is the position of a secondary axis on the right of the plot
+func (c staticChart) rightAxisX(axis int) float64 {
+	return c.plotMax.x + float64(axis-1)*secondaryAxisWidth
+}
+
+func (s Session) staticChart(chartOpts ChartOptions, imgOpts ImageOptions) (staticChart, error) {
+	theme := chartOpts.theme(s)
+	width, height := imgOpts.size()
+
+	// each unit gets its own Y axis
+	axisIndexes := map[string]int{}
+	probeAxes := make([]int, len(s.Probes))
+	var measurements []Measurement
+	for i, probe := range s.Probes {
+		m := probe.Measurement.withDefaults()
+		index, ok := axisIndexes[m.Unit]
+		if !ok {
+			index = len(measurements)
+			axisIndexes[m.Unit] = index
+			measurements = append(measurements, m)
+		}
+		probeAxes[i] = index
+	}
+	if len(measurements) == 0 {
+		measurements = []Measurement{{}}
+	}
+
+	// the plot leaves room on the right for the secondary axes
+	plotRight := float64(width) - 24 - float64(len(measurements)-1)*secondaryAxisWidth
+
+	chart := staticChart{
+		title:      s.Name,
+		width:      width,
+		height:     height,
+		plotMin:    vec{56, 84},
+		plotMax:    vec{math.Max(plotRight, 57), float64(height) - 36},
+		background: mustParseColor("#ffffff"),
+		foreground: mustParseColor("#333333"),
+		grid:       mustParseColor("#e6e6e6"),
+	}
+	if !s.Date.IsZero() {
+		chart.title = fmt.Sprintf("%s (%s)", s.Name, s.Date.Format(time.DateOnly))
+	}
+	if theme.Dark {
+		chart.background = mustParseColor("#100c2a")
+		chart.foreground = mustParseColor("#eeeeee")
+		chart.grid = mustParseColor("#484753")
+	}
+
+	loc := time.Local
+	xValue := func(t time.Time) float64 {
+		if chartOpts.ElapsedFrom.IsZero() {
+			return float64(t.UnixMilli()) / 1000
+		}
+		return t.Sub(chartOpts.ElapsedFrom).Seconds()
+	}
+
+	// the x range includes all of the data, stages, and events unless it is limited by the ChartOptions
+	chart.xMin, chart.xMax = math.Inf(1), math.Inf(-1)
+	include := func(t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		chart.xMin = math.Min(chart.xMin, xValue(t))
+		chart.xMax = math.Max(chart.xMax, xValue(t))
+	}
+
+	data := chartOpts.inRange(s.CleanData())
+	if len(data) > 0 {
+		loc = data[0].Time.Location()
+		include(data[0].Time)
+		include(data[len(data)-1].Time)
+	}
+	if chartOpts.From.IsZero() && chartOpts.To.IsZero() {
+		for _, stage := range s.Stages {
+			include(stage.Start)
+			include(stage.End)
+		}
+		for _, event := range s.Events {
+			include(event.Time)
+		}
+	} else {
+		include(chartOpts.From)
+		include(chartOpts.To)
+	}
+	if math.IsInf(chart.xMin, 0) {
+		return chart, fmt.Errorf("%w: %q has nothing to chart", ErrNoData, s.Name)
+	}
+	if chart.xMax == chart.xMin {
+		chart.xMax = chart.xMin + 60
+	}
+
+	maxPoints := chartOpts.MaxPoints
+	if maxPoints == 0 {
+		maxPoints = int(chart.plotMax.x - chart.plotMin.x)
+	}
+
+	for _, m := range measurements {
+		chart.axes = append(chart.axes, staticAxis{label: m.Label(), yMin: math.Inf(1), yMax: math.Inf(-1)})
+	}
+
+	probePoints := make([][]Point, len(s.Probes))
+	for i, probe := range s.Probes {
+		axis := &chart.axes[probeAxes[i]]
+		probePoints[i] = Downsample(ProbeSeries(data, probe.Position), maxPoints)
+		for _, p := range probePoints[i] {
+			if p.Valid() {
+				axis.yMin = math.Min(axis.yMin, p.Value)
+				axis.yMax = math.Max(axis.yMax, p.Value)
+			}
+		}
+	}
+
+	for i, m := range measurements {
+		axis := &chart.axes[i]
+		if minimum, maximum, ok := m.axisRange(); ok {
+			axis.yMin, axis.yMax = minimum, maximum
+		} else if math.IsInf(axis.yMin, 0) {
+			axis.yMin, axis.yMax = 0, 100
+		}
+
+		var yStep float64
+		axis.yMin, axis.yMax, yStep = niceRange(axis.yMin, axis.yMax, 6)
+		for y := axis.yMin; y <= axis.yMax+yStep/2; y += yStep {
+			axis.ticks = append(axis.ticks, tick{value: y, label: formatTickValue(y, yStep)})
+		}
+	}
+
+	maxXTicks := max(2, int((chart.plotMax.x-chart.plotMin.x)/90))
+	xStep := timeTickStep(chart.xMax-chart.xMin, maxXTicks)
+	for x := math.Ceil(chart.xMin/xStep) * xStep; x <= chart.xMax; x += xStep {
+		label := formatElapsedTick(x)
+		if chartOpts.ElapsedFrom.IsZero() {
+			label = formatClockTick(time.UnixMilli(int64(x*1000)).In(loc), xStep)
+		}
+		chart.xTicks = append(chart.xTicks, tick{value: x, label: label})
+	}
+
+	for i, probe := range s.Probes {
+		series := staticSeries{name: probe.Name, color: parseColorOr(theme.ProbeColor(i), chart.foreground), axis: probeAxes[i]}
+
+		var segment []vec
+		for _, p := range probePoints[i] {
+			if !p.Valid() {
+				if len(segment) > 0 {
+					series.segments = append(series.segments, segment)
+				}
+				segment = nil
+				continue
+			}
+			segment = append(segment, chart.toPixel(xValue(p.Time), p.Value, series.axis))
+		}
+		if len(segment) > 0 {
+			series.segments = append(series.segments, segment)
+		}
+
+		chart.series = append(chart.series, series)
+	}
+
+	addBand := func(stage Stage, colorString string) {
+		band := staticBand{label: stage.Name, color: parseColorOr(colorString, chart.grid), from: xValue(stage.Start), to: chart.xMax}
+		if !stage.End.IsZero() {
+			band.to = xValue(stage.End)
+		}
+		band.from, band.to = math.Max(band.from, chart.xMin), math.Min(band.to, chart.xMax)
+		if band.from < band.to {
+			chart.bands = append(chart.bands, band)
+		}
+	}
+	for i, stage := range s.Stages {
+		addBand(stage, theme.StageColor(i, stage.Name))
+	}
+	if s.Type == SessionTypeBBQ {
+		for _, stall := range s.DetectStalls(chartOpts.StallOptions) {
+			addBand(stall.Stage(), theme.StallColor)
+		}
+	}
+
+	for _, event := range s.Events {
+		x := xValue(event.Time)
+		if x < chart.xMin || x > chart.xMax {
+			continue
+		}
+
+		label := event.Note
+		if runes := []rune(label); len(runes) > maxEventLabel {
+			label = string(runes[:maxEventLabel-3]) + "..."
+		}
+		chart.events = append(chart.events, staticEvent{label: label, x: x})
+	}
+
+	return chart, nil
+}
+
+func (c staticChart) draw(cv canvas) {
+	cv.text(vec{c.plotMin.x, 24}, c.title, c.foreground, 16, anchorStart)
+
+	// legend
+	legendX := c.plotMin.x
+	for _, series := range c.series {
+		cv.fillRect(vec{legendX, 42}, vec{legendX + 14, 46}, series.color)
+		cv.text(vec{legendX + 18, 48}, series.name, c.foreground, 12, anchorStart)
+		legendX += 18 + float64(len(series.name))*7 + 16
+	}
+
+	for _, band := range c.bands {
+		from, to := vec{c.xPixel(band.from), c.plotMin.y}, vec{c.xPixel(band.to), c.plotMax.y}
+		cv.fillRect(from, to, band.color)
+		cv.text(vec{(from.x + to.x) / 2, c.plotMin.y + 14}, band.label, c.foreground, 12, anchorMiddle)
+	}
+
+	// only the primary axis has grid lines
+	for i, axis := range c.axes {
+		if i == 0 {
+			cv.text(vec{8, c.plotMin.y - 10}, axis.label, c.foreground, 12, anchorStart)
+			for _, t := range axis.ticks {
+				p := c.toPixel(c.xMin, t.value, i)
+				cv.polyline([]vec{p, {c.plotMax.x, p.y}}, c.grid, 1, false)
+				cv.text(vec{p.x - 6, p.y + 4}, t.label, c.foreground, 12, anchorEnd)
+			}
+			continue
+		}
+
+		// secondary axis labels are stacked above the plot so they don't overlap
+		x := c.rightAxisX(i)
+		cv.text(vec{float64(c.width) - 8, c.plotMin.y - 10 - float64(len(c.axes)-1-i)*14}, axis.label, c.foreground, 12, anchorEnd)
+		cv.polyline([]vec{{x, c.plotMin.y}, {x, c.plotMax.y}}, c.foreground, 1, false)
+		for _, t := range axis.ticks {
+			y := c.toPixel(c.xMin, t.value, i).y
+			cv.text(vec{x + 6, y + 4}, t.label, c.foreground, 12, anchorStart)
+		}
+	}
+	for _, t := range c.xTicks {
+		p := vec{c.xPixel(t.value), c.plotMax.y}
+		cv.polyline([]vec{p, {p.x, p.y + 5}}, c.foreground, 1, false)
+		cv.text(vec{p.x, p.y + 18}, t.label, c.foreground, 12, anchorMiddle)
+	}
+	cv.polyline([]vec{c.plotMin, {c.plotMin.x, c.plotMax.y}, c.plotMax}, c.foreground, 1, false)
+
+	eventColor := c.foreground
+	eventColor.A = 160
+	for i, event := range c.events {
+		top, bottom := vec{c.xPixel(event.x), c.plotMin.y}, vec{c.xPixel(event.x), c.plotMax.y}
+		cv.polyline([]vec{top, bottom}, eventColor, 1, true)
+		// stagger the labels so nearby events don't overlap as much
+		cv.text(vec{top.x + 4, top.y + 32 + float64(i%3)*14}, event.label, c.foreground, 11, anchorStart)
+	}
+
+	for _, series := range c.series {
+		for _, segment := range series.segments {
+			cv.polyline(segment, series.color, 2, false)
+		}
+	}
+}
+
+// niceRange extends the range to round numbers and returns the step between at most maxTicks ticks
+func niceRange(lower, upper float64, maxTicks int) (float64, float64, float64) {
+	if upper-lower < 1 {
+		lower, upper = lower-1, upper+1
+	}
+
+	raw := (upper - lower) / float64(maxTicks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * magnitude
+	for _, m := range []float64{1, 2, 5} {
+		if m*magnitude >= raw {
+			step = m * magnitude
+			break
+		}
+	}
+
+	return math.Floor(lower/step) * step, math.Ceil(upper/step) * step, step
+}
+
+// timeTickStep chooses a step in seconds so there are at most maxTicks ticks in the span
+func timeTickStep(span float64, maxTicks int) float64 {
+	steps := []float64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 10800, 21600, 43200, 86400}
+	for _, step := range steps {
+		if span/step <= float64(maxTicks) {
+			return step
+		}
+	}
+	return math.Ceil(span/float64(maxTicks)/86400) * 86400
+}
+
+func formatTickValue(v, step float64) string {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// formatClockTick formats the time of day, including seconds if the ticks are less than a minute apart
+func formatClockTick(t time.Time, step float64) string {
+	if step < 60 {
+		return t.Format("3:04:05PM")
+	}
+	return t.Format(time.Kitchen)
+}
+
+// formatElapsedTick formats seconds as mm:ss like the interactive chart's elapsed axis
+func formatElapsedTick(seconds float64) string {
+	sign := ""
+	if seconds < 0 {
+		sign = "-"
+	}
+	total := int(math.Round(math.Abs(seconds)))
+	return fmt.Sprintf("%s%d:%02d", sign, total/60, total%60)
+}
+
+var namedColors = map[string]string{
+	"black":  "#000000",
+	"white":  "#ffffff",
+	"gray":   "#808080",
+	"grey":   "#808080",
+	"red":    "#ff0000",
+	"green":  "#008000",
+	"blue":   "#0000ff",
+	"yellow": "#ffff00",
+	"orange": "#ffa500",
+	"purple": "#800080",
+}
+
+// parseColor parses the CSS colors used by Themes: hex, rgb(), rgba(), and a few names
+func parseColor(s string) (color.NRGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if named, ok := namedColors[s]; ok {
+		s = named
+	}
+
+	switch {
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+		}
+		return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+	case strings.HasPrefix(s, "rgb"):
+		start, end := strings.Index(s, "("), strings.LastIndex(s, ")")
+		if start == -1 || end < start {
+			return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+		}
+
+		parts := strings.Split(s[start+1:end], ",")
+		if len(parts) != 3 && len(parts) != 4 {
+			return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+		}
+
+		values := make([]float64, 4)
+		values[3] = 1
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+			}
+			values[i] = v
+		}
+		return color.NRGBA{
+			R: uint8(math.Round(math.Max(0, math.Min(255, values[0])))),
+			G: uint8(math.Round(math.Max(0, math.Min(255, values[1])))),
+			B: uint8(math.Round(math.Max(0, math.Min(255, values[2])))),
+			A: uint8(math.Round(math.Max(0, math.Min(1, values[3])) * 255)),
+		}, nil
+	default:
+		return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+	}
+}
+
+// parseColorOr parses the color or returns the fallback if it is empty or invalid
+func parseColorOr(s string, fallback color.NRGBA) color.NRGBA {
+	c, err := parseColor(s)
+	if err != nil {
+		return fallback
+	}
+	return c
+}
+
+func mustParseColor(s string) color.NRGBA {
+	c, err := parseColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
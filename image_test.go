@@ -0,0 +1,154 @@
+package twchart
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected color.NRGBA
+	}{
+		{"#5470c6", color.NRGBA{0x54, 0x70, 0xc6, 255}},
+		{"#fff", color.NRGBA{255, 255, 255, 255}},
+		{"rgb(1, 2, 3)", color.NRGBA{1, 2, 3, 255}},
+		{"rgba(255, 165, 0, 0.4)", color.NRGBA{255, 165, 0, 102}},
+		{"Gray", color.NRGBA{128, 128, 128, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := parseColor(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, c)
+		})
+	}
+
+	for _, invalid := range []string{"", "#12345", "#zzzzzz", "rgba(1, 2)", "rgb(a, b, c)", "teal"} {
+		_, err := parseColor(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	lower, upper, step := niceRange(98.3, 402, 6)
+	assert.Equal(t, []float64{0, 500, 100}, []float64{lower, upper, step})
+
+	lower, upper, step = niceRange(70, 70, 6)
+	assert.Equal(t, []float64{69, 71, 0.5}, []float64{lower, upper, step})
+	assert.Equal(t, "69.5", formatTickValue(69.5, step))
+
+	assert.Equal(t, 60.0, timeTickStep(600, 10))
+	assert.Equal(t, 1800.0, timeTickStep(4*3600, 10))
+
+	assert.Equal(t, "4:05", formatElapsedTick(245))
+	assert.Equal(t, "-1:30", formatElapsedTick(-90))
+	assert.Equal(t, "62:00", formatElapsedTick(3720))
+}
+
+func TestWriteImage(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast <1>", start, 4*time.Minute)
+	s.Events = []Event{{Note: "First crack", Time: start.Add(5 * time.Minute)}}
+
+	t.Run("SVG", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := s.WriteSVG(&buf, ChartOptions{}, ImageOptions{Width: 800, Height: 400})
+		require.NoError(t, err)
+
+		svg := buf.String()
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="800" height="400"`))
+		assert.Contains(t, svg, "Roast &lt;1&gt; (2025-05-21)")
+		assert.Contains(t, svg, ">Development</text>")
+		assert.Contains(t, svg, ">First crack</text>")
+		assert.Contains(t, svg, ">8:04AM</text>")
+		assert.Contains(t, svg, `stroke-dasharray="4,4"`)
+		// stage bands are translucent
+		assert.Contains(t, svg, `fill="rgb(160,82,45)" fill-opacity="0.40"`)
+	})
+
+	t.Run("SVGElapsed", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := s.WriteSVG(&buf, ChartOptions{ElapsedFrom: start.Add(4 * time.Minute)}, ImageOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), ">-4:00</text>")
+		assert.Contains(t, buf.String(), ">0:00</text>")
+	})
+
+	t.Run("PNG", func(t *testing.T) {
+		dark := DefaultTheme(true)
+
+		var buf bytes.Buffer
+		err := s.WritePNG(&buf, ChartOptions{Theme: &dark}, ImageOptions{Width: 640, Height: 320})
+		require.NoError(t, err)
+
+		img, err := png.Decode(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, 640, img.Bounds().Dx())
+		assert.Equal(t, 320, img.Bounds().Dy())
+
+		r, g, b, _ := img.At(0, 0).RGBA()
+		assert.Equal(t, []uint32{0x10, 0x0c, 0x2a}, []uint32{r >> 8, g >> 8, b >> 8})
+	})
+
+	t.Run("PNGText", func(t *testing.T) {
+		draw := func(text string) []uint8 {
+			c := newRasterCanvas(40, 20, mustParseColor("#ffffff"))
+			c.text(vec{2, 16}, text, mustParseColor("#000000"), 12, anchorStart)
+			return c.img.Pix
+		}
+
+		// lowercase letters have their own glyphs
+		assert.NotEqual(t, draw("Roast"), draw("ROAST"))
+		assert.NotEqual(t, draw("Roast"), draw(""))
+	})
+
+	t.Run("MeasurementAxes", func(t *testing.T) {
+		s := s
+		s.Probes = append(slices.Clone(s.Probes), Probe{Name: "Humidity", Position: ProbePosition3, Measurement: Measurement{Kind: MeasurementHumidity}})
+		s.Data = slices.Clone(s.Data)
+		for i := range s.Data {
+			s.Data[i].ProbeData = append(slices.Clone(s.Data[i].ProbeData), 40)
+		}
+
+		chart, err := s.staticChart(ChartOptions{}, ImageOptions{})
+		require.NoError(t, err)
+		require.Len(t, chart.axes, 2)
+		assert.Equal(t, "Temperature (°F)", chart.axes[0].label)
+		assert.Equal(t, []float64{100, 400}, []float64{chart.axes[0].yMin, chart.axes[0].yMax})
+		assert.Equal(t, "Humidity (%)", chart.axes[1].label)
+		assert.Equal(t, []float64{0, 100}, []float64{chart.axes[1].yMin, chart.axes[1].yMax})
+		assert.Equal(t, []int{0, 0, 1}, []int{chart.series[0].axis, chart.series[1].axis, chart.series[2].axis})
+
+		var buf bytes.Buffer
+		require.NoError(t, s.WriteSVG(&buf, ChartOptions{}, ImageOptions{}))
+		assert.Contains(t, buf.String(), ">Temperature (°F)</text>")
+		assert.Contains(t, buf.String(), ">Humidity (%)</text>")
+	})
+
+	t.Run("MinimumSize", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := s.WriteSVG(&buf, ChartOptions{}, ImageOptions{Width: 20, Height: 10})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(buf.String(), `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="150"`))
+
+		chart, err := s.staticChart(ChartOptions{}, ImageOptions{Width: 1, Height: 1})
+		require.NoError(t, err)
+		assert.Less(t, chart.plotMin.x, chart.plotMax.x)
+		assert.Less(t, chart.plotMin.y, chart.plotMax.y)
+	})
+
+	t.Run("NoData", func(t *testing.T) {
+		err := Session{Name: "Empty"}.WriteSVG(&bytes.Buffer{}, ChartOptions{}, ImageOptions{})
+		require.ErrorIs(t, err, ErrNoData)
+	})
+}
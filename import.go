@@ -0,0 +1,283 @@
+package twchart
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const defaultGapThreshold = 5 * time.Minute
+
+// ImportOptions configures how CSV data is loaded into a Session
+type ImportOptions struct {
+	// Strict causes the import to fail on the first row that cannot be loaded instead of skipping it
+	Strict bool
+	// GapThreshold is the minimum time between two readings from a probe for it to be reported as a gap.
+	// Defaults to 5 minutes
+	GapThreshold time.Duration
+	// Mode is how the data is combined with the Session's existing data. Defaults to UploadModeAppend
+	Mode UploadMode
+}
+
+// UploadMode is how uploaded data is combined with a Session's existing data
+type UploadMode string
+
+const (
+	// UploadModeAppend adds rows with new timestamps and leaves out rows at existing timestamps, so uploading the
+	// same data again doesn't change anything
+	UploadModeAppend UploadMode = "append"
+	// UploadModeMerge adds rows with new timestamps and merges the readings of rows at existing timestamps into them
+	UploadModeMerge UploadMode = "merge"
+	// UploadModeReplace removes the existing data before adding the new rows
+	UploadModeReplace UploadMode = "replace"
+)
+
+// ParseUploadMode parses an UploadMode. An empty string is UploadModeAppend
+func ParseUploadMode(s string) (UploadMode, error) {
+	switch mode := UploadMode(s); mode {
+	case "":
+		return UploadModeAppend, nil
+	case UploadModeAppend, UploadModeMerge, UploadModeReplace:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid upload mode: %q", s)
+	}
+}
+
+// ImportReport summarizes the data loaded from a CSV export
+type ImportReport struct {
+	RowsRead     int
+	RowsLoaded   int
+	RowsSkipped  []SkippedRow
+	RowsCombined int
+
+	// Mode is how the loaded rows were combined with the Session's existing data. RowsInserted are at new timestamps,
+	// RowsUpdated were merged into existing rows, and RowsUnchanged were left out since the data already had them
+	Mode          UploadMode
+	RowsInserted  int
+	RowsUpdated   int
+	RowsUnchanged int
+
+	Start time.Time
+	End   time.Time
+
+	Probes []ProbeImportStats
+}
+
+// SkippedRow is a CSV row that was not loaded
+type SkippedRow struct {
+	Line   int
+	Reason string
+}
+
+// ProbeImportStats has the number of samples and any gaps in the data for a single probe
+type ProbeImportStats struct {
+	Position ProbePosition
+	Name     string
+	Samples  int
+	Gaps     []Gap
+}
+
+// Gap is a period of time without any readings from a probe
+type Gap struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+// addRow records the result of reading a single row from the CSV
+func (r *ImportReport) addRow(data ThermoworksData, err error) {
+	r.RowsRead++
+
+	if err != nil {
+		if errors.Is(err, ErrDuplicateTimestamp) {
+			r.RowsCombined++
+			return
+		}
+
+		skipped := SkippedRow{Reason: err.Error()}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			skipped.Line = rowErr.Line
+			skipped.Reason = rowErr.Err.Error()
+		}
+		r.RowsSkipped = append(r.RowsSkipped, skipped)
+		return
+	}
+
+	r.RowsLoaded++
+	if r.Start.IsZero() || data.Time.Before(r.Start) {
+		r.Start = data.Time
+	}
+	if data.Time.After(r.End) {
+		r.End = data.Time
+	}
+}
+
+// addProbeStats calculates sample counts and gaps for each probe in the loaded data
+func (r *ImportReport) addProbeStats(data []ThermoworksData, probes []Probe, gapThreshold time.Duration) {
+	if gapThreshold == 0 {
+		gapThreshold = defaultGapThreshold
+	}
+
+	numProbes := 0
+	for _, d := range data {
+		numProbes = max(numProbes, len(d.ProbeData))
+	}
+
+	for i := range numProbes {
+		stats := ProbeImportStats{Position: ProbePosition(i + 1)}
+		for _, p := range probes {
+			if p.Position == stats.Position {
+				stats.Name = p.Name
+			}
+		}
+
+		var lastSample time.Time
+		missed := false
+		for _, d := range data {
+			if !d.hasReading(i) {
+				missed = true
+				continue
+			}
+			stats.Samples++
+
+			if !lastSample.IsZero() && (missed || d.Time.Sub(lastSample) >= gapThreshold) {
+				stats.Gaps = append(stats.Gaps, Gap{
+					Start:    lastSample,
+					End:      d.Time,
+					Duration: d.Time.Sub(lastSample),
+				})
+			}
+			lastSample = d.Time
+			missed = false
+		}
+
+		r.Probes = append(r.Probes, stats)
+	}
+}
+
+// DataChanges are the changes to a Session's data from combining it with uploaded data
+type DataChanges struct {
+	Mode UploadMode
+	// Insert are rows at new timestamps. Each timestamp is only inserted once
+	Insert []ThermoworksData
+	// Update are rows at existing timestamps with the uploaded readings merged into them, when that changes them
+	Update []ThermoworksData
+	// Unchanged is the number of rows that are left out because their timestamp is already stored or appears earlier
+	// in the upload. With UploadModeMerge, stored rows at these timestamps have the same readings. Readings from a
+	// repeated timestamp are merged into the row that is inserted, except with UploadModeAppend
+	Unchanged int
+}
+
+// PlanDataChanges compares uploaded data to the existing data to decide which rows are inserted, updated, or left
+// out by the UploadMode. The uploaded data can repeat a timestamp, like the hour that repeats when daylight saving
+// time ends, and it is handled like a timestamp that is already stored
+func PlanDataChanges(existing, data []ThermoworksData, mode UploadMode) DataChanges {
+	if mode == "" {
+		mode = UploadModeAppend
+	}
+	changes := DataChanges{Mode: mode}
+
+	byTime := make(map[int64]ThermoworksData, len(existing))
+	if mode != UploadModeReplace {
+		for _, d := range existing {
+			byTime[d.Time.UnixNano()] = d
+		}
+	}
+
+	// inserted and updated have the index of each timestamp's row in changes.Insert and changes.Update
+	inserted, updated := map[int64]int{}, map[int64]int{}
+	for _, d := range data {
+		key := d.Time.UnixNano()
+		if i, ok := inserted[key]; ok {
+			if mode != UploadModeAppend && changesReadings(changes.Insert[i], d) {
+				changes.Insert[i] = mergedReadings(changes.Insert[i], d)
+			}
+			changes.Unchanged++
+			continue
+		}
+
+		current, ok := byTime[key]
+		switch {
+		case !ok:
+			inserted[key] = len(changes.Insert)
+			changes.Insert = append(changes.Insert, d)
+		case mode != UploadModeMerge || !changesReadings(current, d):
+			changes.Unchanged++
+		default:
+			// later rows at this timestamp are compared to the merged readings
+			current = mergedReadings(current, d)
+			byTime[key] = current
+			if i, ok := updated[key]; ok {
+				changes.Update[i] = current
+				changes.Unchanged++
+				continue
+			}
+			updated[key] = len(changes.Update)
+			changes.Update = append(changes.Update, current)
+		}
+	}
+
+	return changes
+}
+
+// MergeData combines the data with the Session's Data using the UploadMode. Data is kept in time order
+func (s *Session) MergeData(data []ThermoworksData, mode UploadMode) DataChanges {
+	changes := PlanDataChanges(s.Data, data, mode)
+	if changes.Mode == UploadModeReplace {
+		s.Data = nil
+	}
+
+	if len(changes.Update) > 0 {
+		index := make(map[int64]int, len(s.Data))
+		for i, d := range s.Data {
+			index[d.Time.UnixNano()] = i
+		}
+		for _, d := range changes.Update {
+			s.Data[index[d.Time.UnixNano()]].mergeReadings(d)
+		}
+	}
+
+	s.Data = append(s.Data, changes.Insert...)
+	slices.SortStableFunc(s.Data, func(a, b ThermoworksData) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return changes
+}
+
+// RecordChanges adds the number of inserted, updated, and unchanged rows to the report
+func (r *ImportReport) RecordChanges(changes DataChanges) {
+	r.Mode = changes.Mode
+	r.RowsInserted = len(changes.Insert)
+	r.RowsUpdated = len(changes.Update)
+	r.RowsUnchanged = changes.Unchanged
+}
+
+// changesReadings is true if merging the row into the current one would change any of its readings
+func changesReadings(current, row ThermoworksData) bool {
+	for i, v := range row.ProbeData {
+		if row.hasReading(i) && (!current.hasReading(i) || current.ProbeData[i] != v) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedReadings returns a copy of the row with the other row's readings merged into it, so neither row is changed
+func mergedReadings(row, other ThermoworksData) ThermoworksData {
+	row.ProbeData = slices.Clone(row.ProbeData)
+	row.mergeReadings(other)
+	return row
+}
+
+// mergeReadings sets the readings that the other row has. Readings that it is missing are kept
+func (td *ThermoworksData) mergeReadings(other ThermoworksData) {
+	for i, v := range other.ProbeData {
+		if other.hasReading(i) {
+			td.setProbeData(ProbePosition(i+1), v)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package twchart
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const importTestCSV = `DateTime,Probe 1,Probe 2
+2025-05-24 20:00:00,70,71
+2025-05-24 20:01:00,70,
+2025-05-24 20:01:00,70,
+2025-05-24 20:02:00,abc,72
+2025-05-24 20:03:00,71,73
+not a time,71,73
+2025-05-24 20:10:00,72,74
+`
+
+func TestLoadData(t *testing.T) {
+	t.Run("Report", func(t *testing.T) {
+		s := Session{Probes: []Probe{{Name: "Ambient", Position: ProbePosition1}}}
+		report, err := s.LoadData(strings.NewReader(importTestCSV), ImportOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, s.Data, 4)
+
+		assert.Equal(t, 7, report.RowsRead)
+		assert.Equal(t, 4, report.RowsLoaded)
+		assert.Equal(t, 1, report.RowsCombined)
+		assert.Len(t, report.RowsSkipped, 2)
+		assert.Equal(t, 5, report.RowsSkipped[0].Line)
+		assert.Contains(t, report.RowsSkipped[0].Reason, "Probe 1")
+		assert.Equal(t, 7, report.RowsSkipped[1].Line)
+
+		assert.Equal(t, time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local), report.Start)
+		assert.Equal(t, time.Date(2025, time.May, 24, 20, 10, 0, 0, time.Local), report.End)
+
+		assert.Len(t, report.Probes, 2)
+		assert.Equal(t, "Ambient", report.Probes[0].Name)
+		assert.Equal(t, 4, report.Probes[0].Samples)
+		assert.Equal(t, []Gap{{
+			Start:    time.Date(2025, time.May, 24, 20, 3, 0, 0, time.Local),
+			End:      time.Date(2025, time.May, 24, 20, 10, 0, 0, time.Local),
+			Duration: 7 * time.Minute,
+		}}, report.Probes[0].Gaps)
+
+		assert.Equal(t, 3, report.Probes[1].Samples)
+		assert.Len(t, report.Probes[1].Gaps, 2)
+		assert.Equal(t, time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local), report.Probes[1].Gaps[0].Start)
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		var s Session
+		_, err := s.LoadData(strings.NewReader(importTestCSV), ImportOptions{Strict: true})
+		assert.ErrorContains(t, err, "line 5")
+		assert.Empty(t, s.Data)
+	})
+
+	t.Run("UploadTwice", func(t *testing.T) {
+		var s Session
+		report, err := s.LoadData(strings.NewReader(importTestCSV), ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, UploadModeAppend, report.Mode)
+		assert.Equal(t, 4, report.RowsInserted)
+
+		report, err = s.LoadData(strings.NewReader(importTestCSV), ImportOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, s.Data, 4)
+		assert.Equal(t, 0, report.RowsInserted)
+		assert.Equal(t, 4, report.RowsUnchanged)
+	})
+
+	t.Run("InvalidHeader", func(t *testing.T) {
+		var s Session
+		_, err := s.LoadData(strings.NewReader("Time,Probe 1\n"), ImportOptions{})
+		assert.ErrorContains(t, err, "unexpected header format")
+	})
+}
+
+func TestMergeData(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.UTC)
+	row := func(minute int, probeData ...float64) ThermoworksData {
+		return ThermoworksData{Time: start.Add(time.Duration(minute) * time.Minute), ProbeData: probeData}
+	}
+	existing := []ThermoworksData{row(0, 70, 71), row(1, 72, 73), row(2, 74, 75)}
+	upload := []ThermoworksData{row(3, 76, 77), row(1, 72, 73), row(2, 80, missingReading), row(-1, 69, 70)}
+
+	tests := []struct {
+		mode              UploadMode
+		expected          []ThermoworksData
+		expectedInserted  int
+		expectedUpdated   int
+		expectedUnchanged int
+	}{
+		{UploadModeAppend, []ThermoworksData{row(-1, 69, 70), row(0, 70, 71), row(1, 72, 73), row(2, 74, 75), row(3, 76, 77)}, 2, 0, 2},
+		// the missing reading for the second probe at 2 minutes is kept
+		{UploadModeMerge, []ThermoworksData{row(-1, 69, 70), row(0, 70, 71), row(1, 72, 73), row(2, 80, 75), row(3, 76, 77)}, 2, 1, 1},
+		{UploadModeReplace, []ThermoworksData{row(-1, 69, 70), row(1, 72, 73), row(2, 80, missingReading), row(3, 76, 77)}, 4, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			s := Session{Data: slices.Clone(existing)}
+			for i := range s.Data {
+				s.Data[i].ProbeData = slices.Clone(s.Data[i].ProbeData)
+			}
+
+			changes := s.MergeData(upload, tt.mode)
+			assert.Equal(t, tt.mode, changes.Mode)
+			assert.Len(t, changes.Insert, tt.expectedInserted)
+			assert.Len(t, changes.Update, tt.expectedUpdated)
+			assert.Equal(t, tt.expectedUnchanged, changes.Unchanged)
+			assertDataEqual(t, tt.expected, s.Data)
+		})
+	}
+
+	t.Run("RepeatedTimestamp", func(t *testing.T) {
+		// 4 minutes repeats later in the upload, like local times when daylight saving time ends
+		upload := []ThermoworksData{
+			row(4, 90, missingReading), row(5, 91, 92), row(4, missingReading, 93), row(2, 81, missingReading), row(2, 82, missingReading),
+		}
+
+		tests := []struct {
+			mode              UploadMode
+			expected          []ThermoworksData
+			expectedUnchanged int
+		}{
+			{UploadModeAppend, []ThermoworksData{row(0, 70, 71), row(1, 72, 73), row(2, 74, 75), row(4, 90, missingReading), row(5, 91, 92)}, 3},
+			{UploadModeMerge, []ThermoworksData{row(0, 70, 71), row(1, 72, 73), row(2, 82, 75), row(4, 90, 93), row(5, 91, 92)}, 2},
+			{UploadModeReplace, []ThermoworksData{row(2, 82, missingReading), row(4, 90, 93), row(5, 91, 92)}, 2},
+		}
+
+		for _, tt := range tests {
+			t.Run(string(tt.mode), func(t *testing.T) {
+				s := Session{Data: slices.Clone(existing)}
+				for i := range s.Data {
+					s.Data[i].ProbeData = slices.Clone(s.Data[i].ProbeData)
+				}
+				uploadCopy := slices.Clone(upload)
+				for i := range uploadCopy {
+					uploadCopy[i].ProbeData = slices.Clone(uploadCopy[i].ProbeData)
+				}
+
+				changes := s.MergeData(upload, tt.mode)
+				assert.Equal(t, tt.expectedUnchanged, changes.Unchanged)
+				assert.Equal(t, len(upload), len(changes.Insert)+len(changes.Update)+changes.Unchanged)
+				assertDataEqual(t, tt.expected, s.Data)
+				assertDataEqual(t, uploadCopy, upload)
+			})
+		}
+	})
+}
+
+func TestParseUploadMode(t *testing.T) {
+	mode, err := ParseUploadMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, UploadModeAppend, mode)
+
+	mode, err = ParseUploadMode("merge")
+	assert.NoError(t, err)
+	assert.Equal(t, UploadModeMerge, mode)
+
+	_, err = ParseUploadMode("upsert")
+	assert.EqualError(t, err, `invalid upload mode: "upsert"`)
+}
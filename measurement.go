@@ -0,0 +1,72 @@
+package twchart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MeasurementKind is the quantity a series measures
+type MeasurementKind string
+
+const (
+	MeasurementTemperature MeasurementKind = "temperature"
+	MeasurementHumidity    MeasurementKind = "humidity"
+	MeasurementFan         MeasurementKind = "fan"
+	MeasurementPressure    MeasurementKind = "pressure"
+	// MeasurementRate is a change per unit of time, like a DerivedSeries' rate of rise
+	MeasurementRate MeasurementKind = "rate"
+)
+
+// defaultUnits are used when a Measurement has a kind but no unit
+var defaultUnits = map[MeasurementKind]string{
+	MeasurementTemperature: "°F",
+	MeasurementHumidity:    "%",
+	MeasurementFan:         "%",
+	MeasurementPressure:    "kPa",
+}
+
+// ParseMeasurementKind returns the kind with the name. Names are case-insensitive
+func ParseMeasurementKind(name string) (MeasurementKind, error) {
+	kind := MeasurementKind(strings.ToLower(name))
+	if _, ok := defaultUnits[kind]; !ok {
+		return "", fmt.Errorf("unknown measurement kind: %q", name)
+	}
+	return kind, nil
+}
+
+// Measurement is the kind and unit of a series' values. Series with the same unit share a Y axis on the chart. The
+// zero value is a temperature in °F, which is what Thermoworks probes record
+type Measurement struct {
+	Kind MeasurementKind `json:",omitempty"`
+	Unit string          `json:",omitempty"`
+}
+
+// withDefaults fills in the kind and unit when they are not set
+func (m Measurement) withDefaults() Measurement {
+	if m.Kind == "" {
+		m.Kind = MeasurementTemperature
+	}
+	if m.Unit == "" {
+		m.Unit = defaultUnits[m.Kind]
+	}
+	return m
+}
+
+// Label names the Measurement's axis, like "Humidity (%)"
+func (m Measurement) Label() string {
+	m = m.withDefaults()
+	name := strings.ToUpper(string(m.Kind[:1])) + string(m.Kind[1:])
+	if m.Unit == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, m.Unit)
+}
+
+// axisRange is the fixed range of the Measurement's axis. Percentages are always shown from 0 to 100 and other
+// ranges are fit to the data, so ok is false
+func (m Measurement) axisRange() (minimum, maximum float64, ok bool) {
+	if m.withDefaults().Unit == "%" {
+		return 0, 100, true
+	}
+	return 0, 0, false
+}
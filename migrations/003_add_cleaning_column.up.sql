@@ -0,0 +1,2 @@
+-- Cleaning options are stored as JSON since they are only used by the application
+ALTER TABLE sessions ADD COLUMN cleaning TEXT;
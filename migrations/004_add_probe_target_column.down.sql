@@ -0,0 +1 @@
+ALTER TABLE probes DROP COLUMN target;
@@ -0,0 +1 @@
+ALTER TABLE probes ADD COLUMN target REAL;
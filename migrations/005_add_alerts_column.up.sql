@@ -0,0 +1,2 @@
+-- Alert rules are stored as JSON since they are only used by the application
+ALTER TABLE sessions ADD COLUMN alerts TEXT;
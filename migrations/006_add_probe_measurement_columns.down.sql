@@ -0,0 +1,2 @@
+ALTER TABLE probes DROP COLUMN unit;
+ALTER TABLE probes DROP COLUMN kind;
@@ -0,0 +1,2 @@
+ALTER TABLE probes ADD COLUMN kind TEXT;
+ALTER TABLE probes ADD COLUMN unit TEXT;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_thermoworks_data_session_timestamp;
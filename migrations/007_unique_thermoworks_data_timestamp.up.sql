@@ -0,0 +1,52 @@
+-- Each Session has one row per timestamp so uploading the same data again can't duplicate it. Live readings for
+-- different probes at the same time used to be stored in separate rows, so duplicates are merged into the first row
+-- that was stored before the others are deleted. If more than one row has a reading for a probe, the first one is kept.
+-- Timestamps are stored as text with their zone offset, so they are compared with julianday() to find the same
+-- instant written in different zones
+UPDATE thermoworks_data SET
+    probe1_temp = COALESCE(probe1_temp, (
+        SELECT d.probe1_temp FROM thermoworks_data d
+        WHERE d.session_id = thermoworks_data.session_id AND julianday(d.timestamp) = julianday(thermoworks_data.timestamp)
+            AND d.probe1_temp IS NOT NULL
+        ORDER BY d.id LIMIT 1
+    )),
+    probe2_temp = COALESCE(probe2_temp, (
+        SELECT d.probe2_temp FROM thermoworks_data d
+        WHERE d.session_id = thermoworks_data.session_id AND julianday(d.timestamp) = julianday(thermoworks_data.timestamp)
+            AND d.probe2_temp IS NOT NULL
+        ORDER BY d.id LIMIT 1
+    )),
+    probe3_temp = COALESCE(probe3_temp, (
+        SELECT d.probe3_temp FROM thermoworks_data d
+        WHERE d.session_id = thermoworks_data.session_id AND julianday(d.timestamp) = julianday(thermoworks_data.timestamp)
+            AND d.probe3_temp IS NOT NULL
+        ORDER BY d.id LIMIT 1
+    )),
+    probe4_temp = COALESCE(probe4_temp, (
+        SELECT d.probe4_temp FROM thermoworks_data d
+        WHERE d.session_id = thermoworks_data.session_id AND julianday(d.timestamp) = julianday(thermoworks_data.timestamp)
+            AND d.probe4_temp IS NOT NULL
+        ORDER BY d.id LIMIT 1
+    )),
+    probe5_temp = COALESCE(probe5_temp, (
+        SELECT d.probe5_temp FROM thermoworks_data d
+        WHERE d.session_id = thermoworks_data.session_id AND julianday(d.timestamp) = julianday(thermoworks_data.timestamp)
+            AND d.probe5_temp IS NOT NULL
+        ORDER BY d.id LIMIT 1
+    )),
+    probe6_temp = COALESCE(probe6_temp, (
+        SELECT d.probe6_temp FROM thermoworks_data d
+        WHERE d.session_id = thermoworks_data.session_id AND julianday(d.timestamp) = julianday(thermoworks_data.timestamp)
+            AND d.probe6_temp IS NOT NULL
+        ORDER BY d.id LIMIT 1
+    ))
+WHERE id IN (
+    SELECT MIN(id) FROM thermoworks_data GROUP BY session_id, julianday(timestamp) HAVING COUNT(*) > 1
+);
+
+DELETE FROM thermoworks_data
+WHERE id NOT IN (
+    SELECT MIN(id) FROM thermoworks_data GROUP BY session_id, julianday(timestamp)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_thermoworks_data_session_timestamp ON thermoworks_data(session_id, julianday(timestamp));
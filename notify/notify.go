@@ -0,0 +1,94 @@
+// Package notify sends notifications, like alerts about a Session, to external services
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Message is a notification to send
+type Message struct {
+	Title string
+	Body  string
+	// URL links to more details, like the Session's page
+	URL string
+}
+
+// Notifier sends Messages
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Multi sends each Message to all of its Notifiers. Errors are combined so one failure doesn't stop the others
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		err := n.Notify(ctx, msg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StatusError is returned when a service responds with an unexpected status
+type StatusError struct {
+	Service    string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s: %s", e.StatusCode, e.Service, e.Body)
+}
+
+// send sends the request and checks for a successful response
+func send(client *http.Client, service string, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending notification to %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{Service: service, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return nil
+}
+
+// FromEnv creates Notifiers for each service that is configured with environment variables:
+//   - Pushover: PUSHOVER_APP_TOKEN and PUSHOVER_RECIPIENT_TOKEN
+//   - ntfy: NTFY_TOPIC, and optionally NTFY_SERVER and NTFY_TOKEN
+//   - Webhook: ALERT_WEBHOOK_URL
+//
+// It returns nil if none are configured
+func FromEnv() Notifier {
+	var result Multi
+
+	if appToken, userKey := os.Getenv("PUSHOVER_APP_TOKEN"), os.Getenv("PUSHOVER_RECIPIENT_TOKEN"); appToken != "" && userKey != "" {
+		result = append(result, Pushover{AppToken: appToken, UserKey: userKey})
+	}
+	if topic := os.Getenv("NTFY_TOPIC"); topic != "" {
+		result = append(result, Ntfy{Topic: topic, ServerURL: os.Getenv("NTFY_SERVER"), Token: os.Getenv("NTFY_TOKEN")})
+	}
+	if webhookURL := os.Getenv("ALERT_WEBHOOK_URL"); webhookURL != "" {
+		result = append(result, Webhook{URL: webhookURL})
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessage = Message{
+	Title: "Brisket",
+	Body:  "Brisket is 203.0 (Brisket >= 203)",
+	URL:   "http://localhost:8080/sessions/abc",
+}
+
+// recordRequests starts a server that records each request and responds with the status
+func recordRequests(t *testing.T, status int) (*httptest.Server, *[]*http.Request, *[]string) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":1}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests, &bodies
+}
+
+func TestPushover(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		server, requests, bodies := recordRequests(t, http.StatusOK)
+
+		err := Pushover{AppToken: "app", UserKey: "user", URL: server.URL + "/1/messages.json"}.Notify(t.Context(), testMessage)
+		require.NoError(t, err)
+
+		require.Len(t, *requests, 1)
+		assert.Equal(t, "/1/messages.json", (*requests)[0].URL.Path)
+		assert.Equal(t, "application/x-www-form-urlencoded", (*requests)[0].Header.Get("Content-Type"))
+		assert.Equal(t,
+			"message=Brisket+is+203.0+%28Brisket+%3E%3D+203%29&title=Brisket&token=app&url=http%3A%2F%2Flocalhost%3A8080%2Fsessions%2Fabc&user=user",
+			(*bodies)[0],
+		)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		server, _, _ := recordRequests(t, http.StatusBadRequest)
+
+		err := Pushover{AppToken: "app", UserKey: "user", URL: server.URL}.Notify(t.Context(), testMessage)
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		assert.Equal(t, "Pushover", statusErr.Service)
+	})
+
+	t.Run("MissingConfig", func(t *testing.T) {
+		err := Pushover{AppToken: "app"}.Notify(t.Context(), testMessage)
+		assert.EqualError(t, err, "missing Pushover AppToken or UserKey")
+	})
+}
+
+func TestWebhook(t *testing.T) {
+	server, requests, bodies := recordRequests(t, http.StatusNoContent)
+
+	err := Webhook{URL: server.URL + "/hook", Headers: map[string]string{"X-Token": "secret"}}.Notify(t.Context(), testMessage)
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 1)
+	assert.Equal(t, "/hook", (*requests)[0].URL.Path)
+	assert.Equal(t, "secret", (*requests)[0].Header.Get("X-Token"))
+	assert.Equal(t, "application/json", (*requests)[0].Header.Get("Content-Type"))
+
+	var msg Message
+	require.NoError(t, json.Unmarshal([]byte((*bodies)[0]), &msg))
+	assert.Equal(t, testMessage, msg)
+}
+
+func TestNtfy(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		server, requests, bodies := recordRequests(t, http.StatusOK)
+
+		err := Ntfy{ServerURL: server.URL, Topic: "bbq", Token: "tk"}.Notify(t.Context(), testMessage)
+		require.NoError(t, err)
+
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, "/bbq", req.URL.Path)
+		assert.Equal(t, "Brisket", req.Header.Get("Title"))
+		assert.Equal(t, testMessage.URL, req.Header.Get("Click"))
+		assert.Equal(t, "Bearer tk", req.Header.Get("Authorization"))
+		assert.Equal(t, testMessage.Body, (*bodies)[0])
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		server, _, _ := recordRequests(t, http.StatusForbidden)
+
+		err := Ntfy{ServerURL: server.URL, Topic: "bbq"}.Notify(t.Context(), testMessage)
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+	})
+}
+
+func TestMulti(t *testing.T) {
+	server, requests, _ := recordRequests(t, http.StatusOK)
+
+	err := Multi{
+		Ntfy{},
+		Webhook{URL: server.URL},
+	}.Notify(t.Context(), testMessage)
+
+	assert.EqualError(t, err, "missing ntfy Topic")
+	assert.Len(t, *requests, 1, "other notifiers are still used after an error")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		t.Setenv("PUSHOVER_APP_TOKEN", "app")
+		assert.Nil(t, FromEnv())
+	})
+
+	t.Run("All", func(t *testing.T) {
+		t.Setenv("PUSHOVER_APP_TOKEN", "app")
+		t.Setenv("PUSHOVER_RECIPIENT_TOKEN", "user")
+		t.Setenv("NTFY_TOPIC", "bbq")
+		t.Setenv("ALERT_WEBHOOK_URL", "http://localhost/hook")
+
+		assert.Equal(t, Multi{
+			Pushover{AppToken: "app", UserKey: "user"},
+			Ntfy{Topic: "bbq"},
+			Webhook{URL: "http://localhost/hook"},
+		}, FromEnv())
+	})
+}
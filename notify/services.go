@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultPushoverURL = "https://api.pushover.net/1/messages.json"
+	defaultNtfyURL     = "https://ntfy.sh"
+)
+
+// Pushover sends notifications using the Pushover API
+type Pushover struct {
+	// AppToken is the application's API token and UserKey is the user or group key to send to
+	AppToken string
+	UserKey  string
+
+	// URL defaults to the Pushover messages endpoint
+	URL        string
+	HTTPClient *http.Client
+}
+
+var _ Notifier = Pushover{}
+
+func (p Pushover) Notify(ctx context.Context, msg Message) error {
+	if p.AppToken == "" || p.UserKey == "" {
+		return errors.New("missing Pushover AppToken or UserKey")
+	}
+
+	endpoint := p.URL
+	if endpoint == "" {
+		endpoint = defaultPushoverURL
+	}
+
+	form := url.Values{
+		"token":   {p.AppToken},
+		"user":    {p.UserKey},
+		"title":   {msg.Title},
+		"message": {msg.Body},
+	}
+	if msg.URL != "" {
+		form.Set("url", msg.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return send(p.HTTPClient, "Pushover", req)
+}
+
+// Webhook sends each Message as JSON to a URL
+type Webhook struct {
+	URL string
+	// Headers are added to each request, like for authentication
+	Headers    map[string]string
+	HTTPClient *http.Client
+}
+
+var _ Notifier = Webhook{}
+
+func (wh Webhook) Notify(ctx context.Context, msg Message) error {
+	if wh.URL == "" {
+		return errors.New("missing webhook URL")
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range wh.Headers {
+		req.Header.Set(k, v)
+	}
+
+	return send(wh.HTTPClient, "webhook", req)
+}
+
+// Ntfy publishes notifications to an ntfy topic
+type Ntfy struct {
+	Topic string
+	// ServerURL defaults to https://ntfy.sh
+	ServerURL string
+	// Token is an optional access token for protected topics
+	Token      string
+	HTTPClient *http.Client
+}
+
+var _ Notifier = Ntfy{}
+
+func (n Ntfy) Notify(ctx context.Context, msg Message) error {
+	if n.Topic == "" {
+		return errors.New("missing ntfy Topic")
+	}
+
+	serverURL := n.ServerURL
+	if serverURL == "" {
+		serverURL = defaultNtfyURL
+	}
+
+	endpoint, err := url.JoinPath(serverURL, n.Topic)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", msg.Title)
+	req.Header.Set("Priority", "high")
+	if msg.URL != "" {
+		req.Header.Set("Click", msg.URL)
+	}
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	return send(n.HTTPClient, "ntfy", req)
+}
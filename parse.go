@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -21,6 +23,7 @@ func (s *Session) Write(p []byte) (int, error) {
 // FromText parses the input bytes into the Session struct
 func (s *Session) FromText(input []byte) error {
 	var currentDate time.Time
+	var clockOffset ClockOffset
 	for line := range bytes.SplitSeq(input, []byte{'\n'}) {
 		line = bytes.TrimSpace(line)
 		if len(line) == 0 {
@@ -31,6 +34,12 @@ func (s *Session) FromText(input []byte) error {
 		if err != nil {
 			return err
 		}
+
+		// The clock offset applies to all notes, so it is added after everything else is parsed
+		if offset, ok := result.(ClockOffset); ok {
+			clockOffset += offset
+			continue
+		}
 		result.AddToSession(s)
 
 		currentDate = newCurrentDate
@@ -44,6 +53,8 @@ func (s *Session) FromText(input []byte) error {
 		}
 	}
 
+	clockOffset.AddToSession(s)
+
 	return nil
 }
 
@@ -167,8 +178,13 @@ func (st SessionTypeVal) AddToSession(s *Session) {
 	s.Type = SessionType(st)
 }
 
+// AddToSession adds the Event after the ones before it, since Events can also be added later, like from the chart
 func (e Event) AddToSession(s *Session) {
-	s.Events = append(s.Events, e)
+	i := len(s.Events)
+	for i > 0 && s.Events[i-1].Time.After(e.Time) {
+		i--
+	}
+	s.Events = slices.Insert(s.Events, i, e)
 }
 
 type SessionName string
@@ -178,7 +194,7 @@ func (sn SessionName) AddToSession(s *Session) {
 }
 
 var (
-	probeRE = regexp.MustCompile(`(?i)(?P<name>.+?)\s+probe:\s+(?P<number>\d+)`)
+	probeRE = regexp.MustCompile(`(?i)(?P<name>.+?)\s+probe:\s+(?P<number>\d+)(?:,?\s+(?P<kind>temperature|humidity|fan|pressure)(?:\s+in\s+(?P<unit>[^\s,]+))?)?(?:,?\s+target\s+(?P<target>\d+(?:\.\d+)?))?`)
 	noteRE  = regexp.MustCompile(`(?i)^Note:\s+(?P<timestamp>.+?):\s+(?P<note>.+)$`)
 )
 
@@ -187,7 +203,7 @@ func ParseLine(in []byte, currentDate, startTime time.Time) (SessionPart, time.T
 		return SessionName(in), currentDate, nil
 	}
 
-	if match := probeRE.FindSubmatch(in); len(match) == 3 {
+	if match := probeRE.FindSubmatch(in); len(match) == 6 {
 		probe := Probe{
 			Name: string(match[1]),
 		}
@@ -196,6 +212,21 @@ func ParseLine(in []byte, currentDate, startTime time.Time) (SessionPart, time.T
 			return nil, time.Time{}, fmt.Errorf("error parsing ProbePosition %q: %w", string(match[2]), err)
 		}
 
+		if len(match[3]) > 0 {
+			probe.Measurement.Kind, err = ParseMeasurementKind(string(match[3]))
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			probe.Measurement.Unit = string(match[4])
+		}
+
+		if len(match[5]) > 0 {
+			probe.Target, err = strconv.ParseFloat(string(match[5]), 64)
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("error parsing probe target %q: %w", string(match[5]), err)
+			}
+		}
+
 		return probe, currentDate, nil
 	} else if match := noteRE.FindSubmatch(in); len(match) == 3 {
 		event := Event{
@@ -230,6 +261,22 @@ func ParseLine(in []byte, currentDate, startTime time.Time) (SessionPart, time.T
 		return SessionTypeVal(strings.ToLower(stageTimeStr)), currentDate, nil
 	}
 
+	if strings.ToLower(stageName) == "clock offset" {
+		offset, err := time.ParseDuration(stageTimeStr)
+		if err != nil {
+			return nil, currentDate, fmt.Errorf("error parsing clock offset: %w", err)
+		}
+		return ClockOffset(offset), currentDate, nil
+	}
+
+	if strings.ToLower(stageName) == "alert" {
+		rule, err := ParseAlertRule(stageTimeStr)
+		if err != nil {
+			return nil, currentDate, fmt.Errorf("error parsing alert: %w", err)
+		}
+		return rule, currentDate, nil
+	}
+
 	stageTime, err := parseTime(stageTimeStr, currentDate, startTime)
 	if err != nil {
 		return nil, time.Time{}, fmt.Errorf("error parsing Stage time %q: %w", stageTimeStr, err)
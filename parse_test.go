@@ -39,7 +39,46 @@ func TestParseLine(t *testing.T) {
 		result.AddToSession(s)
 		assert.Equal(t, "Other", s.Probes[1].Name)
 		assert.Equal(t, ProbePosition(ProbePosition2), s.Probes[1].Position)
+		assert.Zero(t, s.Probes[1].Target)
 
+		input = "Brisket probe: 3, target 203.5"
+		result, currentDate, err = ParseLine([]byte(input), currentDate, currentDate)
+		assert.NoError(t, err)
+		result.AddToSession(s)
+		assert.Equal(t, "Brisket", s.Probes[2].Name)
+		assert.Equal(t, ProbePosition(ProbePosition3), s.Probes[2].Position)
+		assert.Equal(t, 203.5, s.Probes[2].Target)
+		assert.Zero(t, s.Probes[2].Measurement)
+
+		input = "Fan probe: 4, fan"
+		result, currentDate, err = ParseLine([]byte(input), currentDate, currentDate)
+		assert.NoError(t, err)
+		result.AddToSession(s)
+		assert.Equal(t, "Fan", s.Probes[3].Name)
+		assert.Equal(t, Measurement{Kind: MeasurementFan}, s.Probes[3].Measurement)
+
+		input = "Pit probe: 5, temperature in °C, target 110"
+		result, _, err = ParseLine([]byte(input), currentDate, currentDate)
+		assert.NoError(t, err)
+		result.AddToSession(s)
+		assert.Equal(t, Measurement{Kind: MeasurementTemperature, Unit: "°C"}, s.Probes[4].Measurement)
+		assert.Equal(t, 110.0, s.Probes[4].Target)
+	})
+
+	t.Run("ParseAlert", func(t *testing.T) {
+		s := &Session{}
+		currentDate := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.Local)
+
+		input := "Alert: Pit < 225 for 10m"
+		result, newDate, err := ParseLine([]byte(input), currentDate, currentDate)
+		assert.NoError(t, err)
+		assert.Equal(t, currentDate, newDate)
+		result.AddToSession(s)
+		assert.Equal(t, []AlertRule{{Condition: AlertBelow, Probe: "Pit", Threshold: 225, For: 10 * time.Minute}}, s.Alerts)
+
+		input = "Alert: Pit above 225"
+		_, _, err = ParseLine([]byte(input), currentDate, currentDate)
+		assert.EqualError(t, err, `error parsing alert: invalid alert rule: "Pit above 225"`)
 	})
 
 	t.Run("ParseNote", func(t *testing.T) {
@@ -0,0 +1,238 @@
+package twchart
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// pdfLetterWidth and pdfLetterHeight are the size of a US Letter page in points
+const (
+	pdfLetterWidth  = 612
+	pdfLetterHeight = 792
+)
+
+// pdfPage writes a single-page PDF. Text uses the standard Helvetica fonts, which PDF readers have built in, so no
+// fonts are embedded and only characters in WinAnsiEncoding can be shown. Positions are in points from the bottom left
+type pdfPage struct {
+	width, height float64
+	title         string
+	content       bytes.Buffer
+}
+
+func newPDFPage(width, height float64) *pdfPage {
+	return &pdfPage{width: width, height: height}
+}
+
+func (p *pdfPage) rect(x, y, width, height float64, c color.NRGBA) {
+	fmt.Fprintf(&p.content, "%s rg %.2f %.2f %.2f %.2f re f\n", pdfColor(c), x, y, width, height)
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2 float64, c color.NRGBA, width float64) {
+	p.polyline([][2]float64{{x1, y1}, {x2, y2}}, c, width, false)
+}
+
+func (p *pdfPage) polyline(points [][2]float64, c color.NRGBA, width float64, dashed bool) {
+	if len(points) == 0 {
+		return
+	}
+
+	dash := "[] 0 d"
+	if dashed {
+		dash = fmt.Sprintf("[%.2f %.2f] 0 d", width*4, width*4)
+	}
+	fmt.Fprintf(&p.content, "%s RG %.2f w %s 1 j 1 J\n", pdfColor(c), width, dash)
+
+	for i, point := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&p.content, "%.2f %.2f %s\n", point[0], point[1], op)
+	}
+	p.content.WriteString("S\n")
+}
+
+// text draws s with its baseline starting at x and y
+func (p *pdfPage) text(x, y float64, s string, c color.NRGBA, size float64, bold bool) {
+	if s == "" {
+		return
+	}
+
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %s rg %.2f %.2f Td (%s) Tj ET\n", font, size, pdfColor(c), x, y, pdfString(s))
+}
+
+// truncate shortens s with "..." so it fits in the width at the font size
+func (p *pdfPage) truncate(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// WriteTo writes the PDF file
+func (p *pdfPage) WriteTo(w io.Writer) (int64, error) {
+	var content bytes.Buffer
+	zw := zlib.NewWriter(&content)
+	_, err := p.content.WriteTo(zw)
+	if err != nil {
+		return 0, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", p.width, p.height),
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (twchart) >>", pdfString(p.title)),
+	}
+
+	var buf bytes.Buffer
+	// the comment with high bytes tells readers the file is binary
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+
+	return buf.WriteTo(w)
+}
+
+// pdfCanvas draws a staticChart on a pdfPage. The chart's pixels are scaled to points and its top left corner is at
+// the origin. PDF colors can't be transparent without extra objects, so colors are blended with the background
+type pdfCanvas struct {
+	page       *pdfPage
+	origin     vec
+	scale      float64
+	background color.NRGBA
+}
+
+func (c *pdfCanvas) point(p vec) [2]float64 {
+	return [2]float64{c.origin.x + p.x*c.scale, c.origin.y - p.y*c.scale}
+}
+
+func (c *pdfCanvas) fillRect(min, max vec, col color.NRGBA) {
+	bottomLeft, topRight := c.point(vec{min.x, max.y}), c.point(vec{max.x, min.y})
+	c.page.rect(bottomLeft[0], bottomLeft[1], topRight[0]-bottomLeft[0], topRight[1]-bottomLeft[1], blend(col, c.background))
+}
+
+func (c *pdfCanvas) polyline(points []vec, col color.NRGBA, width float64, dashed bool) {
+	converted := make([][2]float64, 0, len(points))
+	for _, p := range points {
+		converted = append(converted, c.point(p))
+	}
+	c.page.polyline(converted, blend(col, c.background), width*c.scale, dashed)
+}
+
+func (c *pdfCanvas) text(p vec, s string, col color.NRGBA, size float64, anchor textAnchor) {
+	size *= c.scale
+	start := c.point(p)
+	switch anchor {
+	case anchorMiddle:
+		start[0] -= textWidth(s, size) / 2
+	case anchorEnd:
+		start[0] -= textWidth(s, size)
+	}
+	c.page.text(start[0], start[1], s, blend(col, c.background), size, false)
+}
+
+// blend mixes a transparent color with the background
+func blend(c, background color.NRGBA) color.NRGBA {
+	if c.A == 255 {
+		return c
+	}
+
+	alpha := float64(c.A) / 255
+	mix := func(fg, bg uint8) uint8 {
+		return uint8(float64(fg)*alpha + float64(bg)*(1-alpha) + 0.5)
+	}
+	return color.NRGBA{R: mix(c.R, background.R), G: mix(c.G, background.G), B: mix(c.B, background.B), A: 255}
+}
+
+func pdfColor(c color.NRGBA) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// winAnsi has the characters outside of Latin-1 that WinAnsiEncoding can show
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfString encodes s with WinAnsiEncoding and escapes it for a PDF string. Characters that can't be encoded are
+// replaced with "?"
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths are the widths of the printable ASCII characters in Helvetica, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// textWidth estimates the width of s in regular Helvetica at the font size. Characters outside of ASCII use the
+// width of a digit
+func textWidth(s string, size float64) float64 {
+	var width int
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7f:
+			width += helveticaWidths[r-0x20]
+		case r == '°':
+			width += 400
+		case r == '…', r == '—':
+			width += 1000
+		default:
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
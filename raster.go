@@ -0,0 +1,112 @@
+package twchart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// goRegular is the font for text. It is parsed the first time it is used
+var goRegular = sync.OnceValue(func() *opentype.Font {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return f
+})
+
+// rasterCanvas draws shapes on an image without anti-aliasing. Shapes are drawn to a mask first so overlapping
+// parts of a translucent line aren't blended twice. Text is anti-aliased
+type rasterCanvas struct {
+	img *image.RGBA
+}
+
+func newRasterCanvas(width, height int, background color.NRGBA) *rasterCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	return &rasterCanvas{img: img}
+}
+
+func (c *rasterCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+func (c *rasterCanvas) fillRect(min, max vec, col color.NRGBA) {
+	r := image.Rect(int(math.Round(min.x)), int(math.Round(min.y)), int(math.Round(max.x)), int(math.Round(max.y)))
+	draw.Draw(c.img, r.Intersect(c.img.Bounds()), &image.Uniform{col}, image.Point{}, draw.Over)
+}
+
+func (c *rasterCanvas) polyline(points []vec, col color.NRGBA, width float64, dashed bool) {
+	if len(points) == 0 {
+		return
+	}
+
+	brush := max(1, int(math.Round(width)))
+	bounds := image.Rectangle{}
+	for _, p := range points {
+		bounds = bounds.Union(image.Rect(int(p.x)-brush, int(p.y)-brush, int(p.x)+brush+1, int(p.y)+brush+1))
+	}
+	mask := image.NewAlpha(bounds.Intersect(c.img.Bounds()))
+
+	var distance float64
+	stamp(mask, points[0], brush)
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		length := math.Hypot(to.x-from.x, to.y-from.y)
+
+		steps := max(1, int(math.Ceil(length)))
+		for step := 0; step <= steps; step++ {
+			t := float64(step) / float64(steps)
+			if dashed && math.Mod(distance+t*length, 8) >= 4 {
+				continue
+			}
+			stamp(mask, vec{from.x + t*(to.x-from.x), from.y + t*(to.y-from.y)}, brush)
+		}
+		distance += length
+	}
+
+	c.drawMask(mask, col)
+}
+
+// text draws s with the Go Regular font, which is close to the sans-serif font used by the SVG. Sizes are in pixels
+// like the SVG's font-size
+func (c *rasterCanvas) text(p vec, s string, col color.NRGBA, size float64, anchor textAnchor) {
+	// NewFace only fails for invalid options
+	face, _ := opentype.NewFace(goRegular(), &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	defer face.Close()
+
+	d := font.Drawer{Dst: c.img, Src: &image.Uniform{col}, Face: face}
+	x := fixed.Int26_6(math.Round(p.x * 64))
+	switch anchor {
+	case anchorMiddle:
+		x -= d.MeasureString(s) / 2
+	case anchorEnd:
+		x -= d.MeasureString(s)
+	}
+	d.Dot = fixed.Point26_6{X: x, Y: fixed.Int26_6(math.Round(p.y * 64))}
+	d.DrawString(s)
+}
+
+func (c *rasterCanvas) drawMask(mask *image.Alpha, col color.NRGBA) {
+	draw.DrawMask(c.img, mask.Rect, &image.Uniform{col}, image.Point{}, mask, mask.Rect.Min, draw.Over)
+}
+
+// stamp fills a square of size brush centered on the point
+func stamp(mask *image.Alpha, p vec, brush int) {
+	x0 := int(math.Round(p.x - float64(brush-1)/2))
+	y0 := int(math.Round(p.y - float64(brush-1)/2))
+	for dy := range brush {
+		for dx := range brush {
+			mask.SetAlpha(x0+dx, y0+dy, color.Alpha{A: 255})
+		}
+	}
+}
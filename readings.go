@@ -0,0 +1,158 @@
+package twchart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Reading is a single sample from a probe. It is used to add live data while a Session is in progress
+type Reading struct {
+	Time  time.Time
+	Probe ProbePosition
+	Value float64
+}
+
+func (r Reading) Validate() error {
+	if r.Time.IsZero() {
+		return errors.New("missing time")
+	}
+	if r.Probe == ProbePositionNone {
+		return errors.New("missing probe")
+	}
+	return nil
+}
+
+// AddReadings groups the readings by time and adds them to the Session's Data. Readings at the same time as existing
+// Data are merged into it. The new and updated rows are returned in order
+func (s *Session) AddReadings(readings []Reading) ([]ThermoworksData, error) {
+	for i, r := range readings {
+		err := r.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid reading %d: %w", i, err)
+		}
+	}
+
+	existing := map[int64]int{}
+	for i, d := range s.Data {
+		existing[d.Time.UnixNano()] = i
+	}
+
+	changed := map[int64]struct{}{}
+	sorted := true
+	for _, r := range readings {
+		key := r.Time.UnixNano()
+		i, ok := existing[key]
+		if !ok {
+			i = len(s.Data)
+			existing[key] = i
+			if i > 0 && r.Time.Before(s.Data[i-1].Time) {
+				sorted = false
+			}
+			s.Data = append(s.Data, ThermoworksData{Time: r.Time})
+		}
+
+		s.Data[i].setProbeData(r.Probe, r.Value)
+		changed[key] = struct{}{}
+	}
+
+	if !sorted {
+		slices.SortStableFunc(s.Data, func(a, b ThermoworksData) int {
+			return a.Time.Compare(b.Time)
+		})
+	}
+
+	result := make([]ThermoworksData, 0, len(changed))
+	for _, d := range s.Data {
+		if _, ok := changed[d.Time.UnixNano()]; ok {
+			result = append(result, d)
+		}
+	}
+
+	return result, nil
+}
+
+// setProbeData sets the probe's reading and fills in any probes before it as missing
+func (td *ThermoworksData) setProbeData(pos ProbePosition, value float64) {
+	for len(td.ProbeData) < int(pos) {
+		td.ProbeData = append(td.ProbeData, missingReading)
+	}
+	td.ProbeData[pos-1] = value
+}
+
+// maxReadingDistance is how far a reading can be from an Event when there isn't a reading on each side of it to
+// interpolate between
+const maxReadingDistance = 5 * time.Minute
+
+// ProbeReading is a probe's value at a point in time
+type ProbeReading struct {
+	Probe string
+	Value float64
+	Unit  string
+}
+
+// EventReadings is an Event with each probe's reading at its time
+type EventReadings struct {
+	Event
+	Readings []ProbeReading
+}
+
+// EventReadings gets each probe's reading at the time of each Event from the cleaned data. Readings are interpolated
+// between the samples before and after the Event and rounded to one decimal. Probes without a reading near an Event
+// are left out
+func (s Session) EventReadings() []EventReadings {
+	data := s.CleanData()
+	series := make([][]Point, len(s.Probes))
+	for i, p := range s.Probes {
+		series[i] = ProbeSeries(data, p.Position)
+	}
+
+	result := make([]EventReadings, 0, len(s.Events))
+	for _, e := range s.Events {
+		readings := EventReadings{Event: e, Readings: []ProbeReading{}}
+		for i, p := range s.Probes {
+			value, ok := valueAt(series[i], e.Time)
+			if !ok {
+				continue
+			}
+			readings.Readings = append(readings.Readings, ProbeReading{
+				Probe: p.Name,
+				Value: math.Round(value*10) / 10,
+				Unit:  p.Measurement.withDefaults().Unit,
+			})
+		}
+		result = append(result, readings)
+	}
+	return result
+}
+
+// valueAt interpolates the series' value at the time. If there isn't a valid reading on each side of it, like at the
+// ends of the data or next to missing readings, the nearest one is used if it is within maxReadingDistance
+func valueAt(points []Point, t time.Time) (float64, bool) {
+	i, _ := slices.BinarySearchFunc(points, t, func(p Point, t time.Time) int {
+		return p.Time.Compare(t)
+	})
+
+	var before, after *Point
+	if i > 0 && points[i-1].Valid() {
+		before = &points[i-1]
+	}
+	if i < len(points) && points[i].Valid() {
+		after = &points[i]
+	}
+
+	switch {
+	case after != nil && after.Time.Equal(t):
+		return after.Value, true
+	case before != nil && after != nil:
+		fraction := float64(t.Sub(before.Time)) / float64(after.Time.Sub(before.Time))
+		return before.Value + fraction*(after.Value-before.Value), true
+	case before != nil && t.Sub(before.Time) <= maxReadingDistance:
+		return before.Value, true
+	case after != nil && after.Time.Sub(t) <= maxReadingDistance:
+		return after.Value, true
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddReadings(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+	s := Session{
+		Data: []ThermoworksData{
+			{Time: start, ProbeData: []float64{250, 100}},
+		},
+	}
+
+	changed, err := s.AddReadings([]Reading{
+		{Time: start.Add(time.Minute), Probe: ProbePosition1, Value: 251},
+		{Time: start.Add(time.Minute), Probe: ProbePosition2, Value: 101},
+		{Time: start.Add(2 * time.Minute), Probe: ProbePosition2, Value: 102},
+		{Time: start, Probe: ProbePosition3, Value: 70},
+	})
+	require.NoError(t, err)
+
+	expected := []ThermoworksData{
+		{Time: start, ProbeData: []float64{250, 100, 70}},
+		{Time: start.Add(time.Minute), ProbeData: []float64{251, 101}},
+		{Time: start.Add(2 * time.Minute), ProbeData: []float64{missingReading, 102}},
+	}
+	assertDataEqual(t, expected, changed)
+	assertDataEqual(t, expected, s.Data)
+
+	t.Run("OutOfOrder", func(t *testing.T) {
+		changed, err := s.AddReadings([]Reading{
+			{Time: start.Add(-time.Minute), Probe: ProbePosition1, Value: 249},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []ThermoworksData{{Time: start.Add(-time.Minute), ProbeData: []float64{249}}}, changed)
+		assert.Equal(t, start.Add(-time.Minute), s.Data[0].Time)
+		assert.Len(t, s.Data, 4)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := s.AddReadings([]Reading{{Probe: ProbePosition1, Value: 249}})
+		assert.EqualError(t, err, "invalid reading 0: missing time")
+
+		_, err = s.AddReadings([]Reading{{Time: start, Value: 249}})
+		assert.EqualError(t, err, "invalid reading 0: missing probe")
+		assert.Len(t, s.Data, 4)
+	})
+}
+
+func TestEventReadings(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+	s := Session{
+		Probes: []Probe{
+			{Name: "Dough", Position: ProbePosition1},
+			{Name: "Humidity", Position: ProbePosition2, Measurement: Measurement{Kind: MeasurementHumidity}},
+		},
+		Data: []ThermoworksData{
+			{Time: start, ProbeData: []float64{70, 50}},
+			{Time: start.Add(time.Minute), ProbeData: []float64{76, 60}},
+			{Time: start.Add(2 * time.Minute), ProbeData: []float64{80}},
+			{Time: start.Add(3 * time.Minute), ProbeData: []float64{81}},
+		},
+		Events: []Event{
+			{Note: "Exact", Time: start.Add(time.Minute)},
+			{Note: "Between", Time: start.Add(20 * time.Second)},
+			{Note: "Missing humidity", Time: start.Add(150 * time.Second)},
+			{Note: "After", Time: start.Add(7 * time.Minute)},
+			{Note: "Too late", Time: start.Add(10 * time.Minute)},
+		},
+	}
+
+	readings := s.EventReadings()
+	require.Len(t, readings, 5)
+
+	assert.Equal(t, s.Events[0], readings[0].Event)
+	assert.Equal(t, []ProbeReading{{"Dough", 76, "°F"}, {"Humidity", 60, "%"}}, readings[0].Readings)
+	assert.Equal(t, []ProbeReading{{"Dough", 72, "°F"}, {"Humidity", 53.3, "%"}}, readings[1].Readings)
+	assert.Equal(t, []ProbeReading{{"Dough", 80.5, "°F"}}, readings[2].Readings)
+	assert.Equal(t, []ProbeReading{{"Dough", 81, "°F"}}, readings[3].Readings)
+	assert.Empty(t, readings[4].Readings)
+}
@@ -0,0 +1,121 @@
+package twchart
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// ReplayFrameKind is what happened in a ReplayFrame
+type ReplayFrameKind string
+
+const (
+	ReplayReading    ReplayFrameKind = "reading"
+	ReplayEvent      ReplayFrameKind = "event"
+	ReplayStageStart ReplayFrameKind = "stage_start"
+	ReplayStageEnd   ReplayFrameKind = "stage_end"
+)
+
+// replayOrder sorts frames at the same time so a Stage ends before the next one starts, and notes are shown once their
+// Stage has started
+var replayOrder = map[ReplayFrameKind]int{
+	ReplayStageEnd:   0,
+	ReplayStageStart: 1,
+	ReplayEvent:      2,
+	ReplayReading:    3,
+}
+
+// ReplayFrame is one step of a Replay
+type ReplayFrame struct {
+	Kind ReplayFrameKind
+	Time time.Time
+
+	// Readings are the values of each series at the Time, by series name. A nil value is a missing reading
+	Readings map[string]*float64 `json:",omitempty"`
+	// Note is the Event's note
+	Note string `json:",omitempty"`
+	// Stage is the name of the Stage that started or ended
+	Stage string `json:",omitempty"`
+	// Color is the Stage's color on the chart
+	Color string `json:",omitempty"`
+}
+
+// Replay is a Session's readings, Events, and Stages merged into one time-ordered stream so the Session can be played
+// back
+type Replay struct {
+	// Start is when the playback starts. It is the Session's StartTime, or the first frame if it isn't set
+	Start time.Time
+	// End is the time of the last frame
+	End time.Time
+	// Series are the names of the probes and DerivedSeries that have readings
+	Series []string
+	Frames []ReplayFrame
+}
+
+// Replay creates the stream of frames for playing back the Session. The probe and DerivedSeries readings are
+// downsampled to the ChartOptions' MaxPoints and the Stage colors come from its Theme. From and To are ignored since
+// the whole Session is played back
+func (s Session) Replay(chartOpts ChartOptions) Replay {
+	replay := Replay{Series: []string{}, Frames: []ReplayFrame{}}
+
+	readings := map[time.Time]map[string]*float64{}
+	addPoints := func(name string, points []Point) {
+		replay.Series = append(replay.Series, name)
+		for _, p := range points {
+			if readings[p.Time] == nil {
+				readings[p.Time] = map[string]*float64{}
+			}
+			var value *float64
+			if p.Valid() {
+				value = &p.Value
+			}
+			readings[p.Time][name] = value
+		}
+	}
+
+	data := s.CleanData()
+	for _, p := range s.Probes {
+		addPoints(p.Name, Downsample(ProbeSeries(data, p.Position), chartOpts.maxPoints()))
+	}
+	for _, series := range chartOpts.derivedSeries(s) {
+		addPoints(series.Name(), Downsample(series.Compute(s), chartOpts.maxPoints()))
+	}
+
+	for t, values := range readings {
+		replay.Frames = append(replay.Frames, ReplayFrame{Kind: ReplayReading, Time: t, Readings: values})
+	}
+
+	for _, e := range s.Events {
+		replay.Frames = append(replay.Frames, ReplayFrame{Kind: ReplayEvent, Time: e.Time, Note: e.Note})
+	}
+
+	theme := chartOpts.theme(s)
+	for i, stage := range s.Stages {
+		color := theme.StageColor(i, stage.Name)
+		replay.Frames = append(replay.Frames, ReplayFrame{Kind: ReplayStageStart, Time: stage.Start, Stage: stage.Name, Color: color})
+		if !stage.End.IsZero() {
+			replay.Frames = append(replay.Frames, ReplayFrame{Kind: ReplayStageEnd, Time: stage.End, Stage: stage.Name, Color: color})
+		}
+	}
+
+	slices.SortFunc(replay.Frames, func(a, b ReplayFrame) int {
+		return cmp.Or(a.Time.Compare(b.Time), cmp.Compare(replayOrder[a.Kind], replayOrder[b.Kind]))
+	})
+
+	if len(replay.Frames) == 0 {
+		replay.Start = s.StartTime
+		replay.End = s.StartTime
+		return replay
+	}
+
+	replay.Start = s.StartTime
+	if replay.Start.IsZero() {
+		replay.Start = replay.Frames[0].Time
+	}
+	replay.End = replay.Frames[len(replay.Frames)-1].Time
+	if replay.End.Before(replay.Start) {
+		replay.End = replay.Start
+	}
+
+	return replay
+}
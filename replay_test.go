@@ -0,0 +1,72 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast", start, 4*time.Minute)
+	s.Events = []Event{
+		{Note: "Charge", Time: start.Add(-time.Minute)},
+		{Note: "First crack", Time: start.Add(4 * time.Minute)},
+	}
+
+	replay := s.Replay(ChartOptions{DerivedSeries: []DerivedSeries{}})
+	assert.Equal(t, start, replay.Start)
+	assert.Equal(t, start.Add(10*time.Minute), replay.End)
+	assert.Equal(t, []string{"Ambient", "Bean"}, replay.Series)
+	require.Len(t, replay.Frames, 16)
+
+	assert.Equal(t, ReplayFrame{Kind: ReplayEvent, Time: start.Add(-time.Minute), Note: "Charge"}, replay.Frames[0])
+	assert.Equal(t, ReplayStageStart, replay.Frames[1].Kind)
+	assert.Equal(t, "Drying", replay.Frames[1].Stage)
+	assert.NotEmpty(t, replay.Frames[1].Color)
+
+	reading := replay.Frames[2]
+	assert.Equal(t, ReplayReading, reading.Kind)
+	assert.Equal(t, start, reading.Time)
+	require.Len(t, reading.Readings, 2)
+	assert.Equal(t, 400.0, *reading.Readings["Ambient"])
+	assert.Equal(t, 100.0, *reading.Readings["Bean"])
+
+	t.Run("SameTimeOrder", func(t *testing.T) {
+		kinds := []ReplayFrameKind{}
+		for _, f := range replay.Frames {
+			if f.Time.Equal(start.Add(4 * time.Minute)) {
+				kinds = append(kinds, f.Kind)
+			}
+		}
+		assert.Equal(t, []ReplayFrameKind{ReplayStageEnd, ReplayStageStart, ReplayEvent, ReplayReading}, kinds)
+	})
+
+	t.Run("DerivedSeries", func(t *testing.T) {
+		replay := s.Replay(ChartOptions{})
+		assert.Equal(t, []string{"Ambient", "Bean", "Bean RoR"}, replay.Series)
+	})
+
+	t.Run("MissingReadings", func(t *testing.T) {
+		s := s
+		s.Data = []ThermoworksData{{Time: start, ProbeData: []float64{400, 100}}, {Time: start.Add(time.Minute), ProbeData: []float64{400}}}
+
+		replay := s.Replay(ChartOptions{DerivedSeries: []DerivedSeries{}})
+		for _, f := range replay.Frames {
+			if f.Kind == ReplayReading && f.Time.Equal(start.Add(time.Minute)) {
+				assert.Contains(t, f.Readings, "Bean")
+				assert.Nil(t, f.Readings["Bean"])
+			}
+		}
+	})
+
+	t.Run("NoStartTime", func(t *testing.T) {
+		s := s
+		s.StartTime = time.Time{}
+
+		replay := s.Replay(ChartOptions{})
+		assert.Equal(t, start.Add(-time.Minute), replay.Start)
+	})
+}
@@ -0,0 +1,237 @@
+package twchart
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report is a summary of a Session for printing
+type Report struct {
+	Name string
+	Type SessionType
+	Date time.Time
+	// Start is the Session's StartTime, or its first Stage or Event if it isn't set
+	Start time.Time
+	// End is the time of the last Event, reading, or Stage end
+	End      time.Time
+	Duration time.Duration
+
+	Probes []Probe
+	Stages []StageStats
+	Events []EventReadings
+}
+
+// Report collects the Session's details, Stage stats, and the probe readings at each Event
+func (s Session) Report() Report {
+	report := Report{
+		Name:   s.Name,
+		Type:   s.Type,
+		Date:   s.Date,
+		Start:  s.StartTime,
+		Probes: s.Probes,
+		Stages: s.StageStats(),
+		Events: s.EventReadings(),
+	}
+	if report.Start.IsZero() && (len(s.Stages) > 0 || len(s.Events) > 0) {
+		report.Start = s.timelineStart()
+	}
+
+	report.End = s.lastTime(report.Start, time.Time{})
+	for _, stage := range s.Stages {
+		if stage.End.After(report.End) {
+			report.End = stage.End
+		}
+	}
+	if !report.Start.IsZero() {
+		report.Duration = report.End.Sub(report.Start)
+	}
+
+	return report
+}
+
+const (
+	// reportMargin is the space around the page of a PDF report, in points
+	reportMargin = 40
+	// reportChartScale is the size of a chart pixel on the PDF report, in points
+	reportChartScale = 0.6
+	// reportChartHeight is the height of the chart on the PDF report, in pixels
+	reportChartHeight = 420
+)
+
+// WriteReportPDF writes a one-page, US Letter PDF with the Session's Report and its chart. The chart is drawn like
+// WriteSVG, but it always has a light background since it is meant to be printed. If the Report doesn't fit on the
+// page, the remaining Stages and Events are left out with a line saying how many. Events are only included if there is
+// room after all of the Stages
+func (s Session) WriteReportPDF(w io.Writer, chartOpts ChartOptions) error {
+	report := s.Report()
+	page := newPDFPage(pdfLetterWidth, pdfLetterHeight)
+	page.title = s.Name
+
+	black, gray, rule := mustParseColor("#222222"), mustParseColor("#666666"), mustParseColor("#cccccc")
+	left, right := float64(reportMargin), float64(pdfLetterWidth-reportMargin)
+	y := float64(pdfLetterHeight - reportMargin)
+
+	// header
+	y -= 18
+	page.text(left, y, report.Name, black, 18, true)
+	y -= 16
+	page.text(left, y, report.subtitle(), gray, 10, false)
+	if len(report.Probes) > 0 {
+		y -= 14
+		page.text(left, y, "Probes: "+report.probeList(), gray, 10, false)
+	}
+
+	// chart
+	y -= 12
+	if chartOpts.Theme != nil && chartOpts.Theme.Dark {
+		// the colors are kept and only the background and text are light
+		light := *chartOpts.Theme
+		light.Dark = false
+		chartOpts.Theme = &light
+	}
+	imgOpts := ImageOptions{
+		Width:  int((right - left) / reportChartScale),
+		Height: reportChartHeight,
+	}
+	chart, err := s.staticChart(chartOpts, imgOpts)
+	switch {
+	case errors.Is(err, ErrNoData):
+		y -= 14
+		page.text(left, y, "No data to chart", gray, 10, false)
+	case err != nil:
+		return err
+	default:
+		cv := &pdfCanvas{page: page, origin: vec{left, y}, scale: reportChartScale, background: chart.background}
+		chart.draw(cv)
+		y -= reportChartHeight * reportChartScale
+	}
+
+	// stages
+	bottom := float64(reportMargin)
+	columns := []float64{left, left + 160, left + 230, left + 300}
+	if len(report.Stages) > 0 {
+		y -= 28
+		page.text(left, y, "Stages", black, 13, true)
+		y -= 16
+		for i, header := range []string{"Stage", "Start", "End", "Duration"} {
+			page.text(columns[i], y, header, gray, 9, true)
+		}
+		y -= 4
+		page.line(left, y, right, y, rule, 0.5)
+
+		for i, stats := range report.Stages {
+			// leave room to say how many Stages are left out
+			height := 13 * float64(1+len(stats.Probes))
+			if remaining := len(report.Stages) - i; y-height < bottom || (remaining > 1 && y-height-13 < bottom) {
+				if y-13 >= bottom {
+					more := fmt.Sprintf("and %d more stages", remaining)
+					if remaining == 1 {
+						more = "and 1 more stage"
+					}
+					y -= 13
+					page.text(left, y, more, gray, 9, false)
+				}
+				break
+			}
+
+			stage := stats.Stage
+			y -= 13
+			page.text(columns[0], y, page.truncate(stage.Name, 9, columns[1]-columns[0]-8), black, 9, false)
+			page.text(columns[1], y, stage.Start.Format(time.Kitchen), black, 9, false)
+			end, duration := "–", "–"
+			if !stage.End.IsZero() {
+				end = stage.End.Format(time.Kitchen)
+				duration = formatReportDuration(stage.End.Sub(stage.Start))
+			}
+			page.text(columns[2], y, end, black, 9, false)
+			page.text(columns[3], y, duration, black, 9, false)
+
+			for _, probe := range stats.Probes {
+				y -= 11
+				line := fmt.Sprintf("%s: %.1f to %.1f (%+.1f), min %.1f, max %.1f, mean %.1f",
+					probe.Probe.Name, probe.Start, probe.End, probe.Delta, probe.Min, probe.Max, probe.Mean)
+				page.text(columns[0]+12, y, page.truncate(line, 8, right-columns[0]-12), gray, 8, false)
+			}
+		}
+	}
+
+	// notes
+	if len(report.Events) > 0 && y-44 >= bottom {
+		y -= 28
+		page.text(left, y, "Notes", black, 13, true)
+		y -= 4
+		page.line(left, y, right, y, rule, 0.5)
+
+		noteX, readingsX := left+110, left+330
+		for i, event := range report.Events {
+			// leave room to say how many Events are left out
+			if remaining := len(report.Events) - i; remaining > 1 && y-26 < bottom {
+				y -= 13
+				page.text(left, y, fmt.Sprintf("and %d more notes", remaining), gray, 9, false)
+				break
+			}
+
+			y -= 13
+			page.text(left, y, event.Time.Format(time.Kitchen), black, 9, false)
+			if !report.Start.IsZero() && !event.Time.Before(report.Start) {
+				page.text(left+50, y, "+"+formatReportDuration(event.Time.Sub(report.Start)), gray, 9, false)
+			}
+			page.text(noteX, y, page.truncate(event.Note, 9, readingsX-noteX-8), black, 9, false)
+
+			readings := make([]string, 0, len(event.Readings))
+			for _, r := range event.Readings {
+				readings = append(readings, fmt.Sprintf("%.0f%s %s", r.Value, r.Unit, r.Probe))
+			}
+			page.text(readingsX, y, page.truncate(strings.Join(readings, ", "), 9, right-readingsX), gray, 9, false)
+		}
+	}
+
+	_, err = page.WriteTo(w)
+	return err
+}
+
+// subtitle has the Session's type, date, start time, and duration
+func (r Report) subtitle() string {
+	var parts []string
+	if r.Type != SessionTypeNone {
+		parts = append(parts, strings.ToUpper(string(r.Type[:1]))+string(r.Type[1:]))
+	}
+	if !r.Date.IsZero() {
+		parts = append(parts, r.Date.Format("Monday, Jan 2, 2006"))
+	}
+	if !r.Start.IsZero() {
+		parts = append(parts, "started "+r.Start.Format(time.Kitchen))
+	}
+	if r.Duration > 0 {
+		parts = append(parts, formatReportDuration(r.Duration))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// probeList has each probe's name and target
+func (r Report) probeList() string {
+	probes := make([]string, 0, len(r.Probes))
+	for _, p := range r.Probes {
+		if p.Target != 0 {
+			probes = append(probes, fmt.Sprintf("%s (target %g%s)", p.Name, p.Target, p.Measurement.withDefaults().Unit))
+			continue
+		}
+		probes = append(probes, p.Name)
+	}
+	return strings.Join(probes, ", ")
+}
+
+// formatReportDuration formats the duration in hours and minutes like "2h5m", or seconds if it is under a minute
+func formatReportDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+}
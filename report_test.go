@@ -0,0 +1,156 @@
+package twchart
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast", start, 4*time.Minute)
+	s.Events = []Event{{Note: "First crack", Time: start.Add(5 * time.Minute)}}
+
+	report := s.Report()
+	assert.Equal(t, "Roast", report.Name)
+	assert.Equal(t, start, report.Start)
+	assert.Equal(t, start.Add(10*time.Minute), report.End)
+	assert.Equal(t, 10*time.Minute, report.Duration)
+	require.Len(t, report.Stages, 2)
+	assert.Equal(t, "Drying", report.Stages[0].Stage.Name)
+	require.Len(t, report.Events, 1)
+	assert.Equal(t, []ProbeReading{{Probe: "Ambient", Value: 400, Unit: "°F"}, {Probe: "Bean", Value: 250, Unit: "°F"}}, report.Events[0].Readings)
+
+	assert.Equal(t, "Coffee · Wednesday, May 21, 2025 · started 8:00AM · 10m", report.subtitle())
+
+	t.Run("NoStartTime", func(t *testing.T) {
+		s := s
+		s.StartTime = time.Time{}
+		s.Stages[1].End = start.Add(20 * time.Minute)
+
+		report := s.Report()
+		assert.Equal(t, start, report.Start)
+		assert.Equal(t, start.Add(20*time.Minute), report.End)
+	})
+}
+
+func TestWriteReportPDF(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast (light)", start, 4*time.Minute)
+	s.Events = []Event{{Note: "First crack", Time: start.Add(5 * time.Minute)}}
+	dark := DefaultTheme(true)
+
+	var buf bytes.Buffer
+	err := s.WriteReportPDF(&buf, ChartOptions{Theme: &dark})
+	require.NoError(t, err)
+
+	pdf := buf.String()
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.Contains(t, pdf, "/BaseFont /Helvetica ")
+	assert.Contains(t, pdf, `/Title (Roast \(light\))`)
+
+	// each xref entry points at its object
+	xref := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf, -1)
+	require.Len(t, xref, 7)
+	for i, entry := range xref {
+		offset, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj\n"), "object %d", i+1)
+	}
+	startXref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	require.Len(t, startXref, 2)
+	offset, err := strconv.Atoi(startXref[1])
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pdf[offset:], "xref\n"))
+
+	content := reportPDFContent(t, pdf)
+
+	assert.Contains(t, content, `(Roast \(light\)) Tj`)
+	assert.Contains(t, content, "(Coffee \xb7 Wednesday, May 21, 2025 \xb7 started 8:00AM \xb7 10m) Tj")
+	assert.Contains(t, content, "(Drying) Tj")
+	assert.Contains(t, content, "(Bean: 100.0 to 220.0 \\(+120.0\\), min 100.0, max 220.0, mean 160.0) Tj")
+	assert.Contains(t, content, "(First crack) Tj")
+	assert.Contains(t, content, "(400\xb0F Ambient, 250\xb0F Bean) Tj")
+	// the chart is light even though the Theme is dark
+	assert.NotContains(t, content, "0.933 0.933 0.933")
+
+	t.Run("ConfiguredColors", func(t *testing.T) {
+		dark := Themes{SessionTypeNone: {ProbeColors: []string{"#123456"}}}.Get(s.Type, true)
+
+		var buf bytes.Buffer
+		err := s.WriteReportPDF(&buf, ChartOptions{Theme: &dark})
+		require.NoError(t, err)
+		content := reportPDFContent(t, buf.String())
+
+		assert.Contains(t, content, "0.071 0.204 0.337")
+		assert.NotContains(t, content, "0.933 0.933 0.933")
+	})
+
+	t.Run("MoreStages", func(t *testing.T) {
+		s := compareSession("Roast", start, 4*time.Minute)
+		s.Events = []Event{{Note: "First crack", Time: start.Add(5 * time.Minute)}}
+		s.Stages = nil
+		for i := range 30 {
+			s.Stages = append(s.Stages, Stage{
+				Name:  fmt.Sprintf("Stage %d", i+1),
+				Start: start.Add(time.Duration(i) * 20 * time.Second),
+				End:   start.Add(time.Duration(i+1) * 20 * time.Second),
+			})
+		}
+
+		var buf bytes.Buffer
+		err := s.WriteReportPDF(&buf, ChartOptions{})
+		require.NoError(t, err)
+		content := reportPDFContent(t, buf.String())
+
+		// the chart labels the Stages too, so only the names in the table's first column are counted
+		drawn := len(regexp.MustCompile(`40\.00 [\d.]+ Td \(Stage \d+\) Tj`).FindAllString(content, -1))
+		more := regexp.MustCompile(`\(and (\d+) more stages\) Tj`).FindStringSubmatch(content)
+		require.Len(t, more, 2)
+		assert.Equal(t, strconv.Itoa(30-drawn), more[1])
+		assert.NotContains(t, content, "(Notes) Tj")
+	})
+
+	t.Run("NoData", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := Session{Name: "Empty"}.WriteReportPDF(&buf, ChartOptions{})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(buf.String(), "%PDF-1.4\n"))
+	})
+}
+
+func TestPDFString(t *testing.T) {
+	assert.Equal(t, `a \(b\) \\ c`, pdfString(`a (b) \ c`))
+	assert.Equal(t, "200\xb0F \x96 ok?", pdfString("200°F – ok→"))
+	assert.Equal(t, "line one", pdfString("line\none"))
+}
+
+func TestPDFTruncate(t *testing.T) {
+	page := newPDFPage(pdfLetterWidth, pdfLetterHeight)
+	assert.Equal(t, "short", page.truncate("short", 10, 100))
+	assert.Equal(t, "a long no...", page.truncate("a long note about the roast", 10, 50))
+	assert.InDelta(t, 22.24, textWidth("0 1 2", 10), 0.01)
+}
+
+// reportPDFContent decompresses the content stream of the report's page
+func reportPDFContent(t *testing.T, pdf string) string {
+	t.Helper()
+
+	streamStart := strings.Index(pdf, "stream\n") + len("stream\n")
+	streamEnd := strings.Index(pdf, "\nendstream")
+	zr, err := zlib.NewReader(strings.NewReader(pdf[streamStart:streamEnd]))
+	require.NoError(t, err)
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return string(content)
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -38,6 +40,12 @@ type Session struct {
 
 	Data []ThermoworksData
 
+	// Cleaning is applied to Data before it is charted or used for stats. Data always holds the raw readings
+	Cleaning CleaningOptions
+
+	// Alerts are checked when new data is added to notify about things like a probe reaching its target
+	Alerts []AlertRule
+
 	UploadedAt time.Time
 }
 
@@ -56,6 +64,11 @@ type Event struct {
 type Probe struct {
 	Name     string
 	Position ProbePosition
+	// Target is the temperature the probe should reach. It is used to estimate when the food is done
+	Target float64
+	// Measurement is what the probe records. It defaults to temperature, but some devices also log things like
+	// humidity or fan speed
+	Measurement Measurement
 }
 
 func (s *Stage) Finish(t time.Time) {
@@ -83,7 +96,10 @@ func (s *Stage) MarkArea(color string) []opts.MarkAreaData {
 	}
 }
 
-func (s *Session) LoadData(r io.Reader) error {
+// LoadData reads Thermoworks CSV data and combines it with the Session's Data using opts.Mode. Rows that can't be
+// parsed are skipped and recorded in the ImportReport unless opts.Strict is set, in which case no data is added and
+// an error is returned
+func (s *Session) LoadData(r io.Reader, opts ImportOptions) (ImportReport, error) {
 	// Clean Unicode character U+FEFF from the beginning of CSV
 	br := bufio.NewReader(r)
 	b, _ := br.Peek(3)
@@ -95,48 +111,93 @@ func (s *Session) LoadData(r io.Reader) error {
 
 	csvData, err := iterCSV(reader)
 	if err != nil {
-		return err
+		return ImportReport{}, err
 	}
 
+	var report ImportReport
+	var loaded []ThermoworksData
 	for data, err := range csvData {
+		report.addRow(data, err)
 		if err != nil {
+			if opts.Strict && !errors.Is(err, ErrDuplicateTimestamp) {
+				return report, err
+			}
 			continue
 		}
 
-		s.Data = append(s.Data, data)
+		loaded = append(loaded, data)
 	}
 
-	return nil
+	report.addProbeStats(loaded, s.Probes, opts.GapThreshold)
+	report.RecordChanges(s.MergeData(loaded, opts.Mode))
+
+	return report, nil
 }
 
-func (s *Session) LoadDataFromFile(csvFile string) error {
+func (s *Session) LoadDataFromFile(csvFile string, opts ImportOptions) (ImportReport, error) {
 	file, err := os.Open(csvFile)
 	if err != nil {
-		return err
+		return ImportReport{}, err
 	}
 	defer file.Close()
 
-	return s.LoadData(file)
+	return s.LoadData(file, opts)
 }
 
-// TimeBounds returns the earliest and latest Events or Stages to set the bounds on the Chart
+// UnmarshalJSON reads missing readings as null. Sessions stored before that used 0 or below for missing readings,
+// which is also how Thermoworks reports a disconnected temperature probe, so those values are still missing for
+// temperature probes. Other Measurements, like a fan at 0%, keep them
+func (s *Session) UnmarshalJSON(input []byte) error {
+	type session Session
+	err := json.Unmarshal(input, (*session)(s))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range s.Probes {
+		if p.Position == ProbePositionNone || p.Measurement.withDefaults().Kind != MeasurementTemperature {
+			continue
+		}
+		for _, d := range s.Data {
+			if d.HasProbeData(p.Position) && d.GetProbeData(p.Position) <= 0 {
+				d.ProbeData[p.Position-1] = missingReading
+			}
+		}
+	}
+	return nil
+}
+
+// CleanData returns the Session's Data with its CleaningOptions applied. Data is not modified
+func (s Session) CleanData() []ThermoworksData {
+	return s.Cleaning.Pipeline().Apply(s.Data)
+}
+
+// TimeBounds returns the earliest and latest Events or Stages to set the bounds on the Chart. Stages that are not
+// finished use their Start. Both are zero if there are no Events or Stages
 func (s Session) TimeBounds() (time.Time, time.Time) {
-	earliestTime := s.Date.AddDate(1, 0, 0)
-	latestTime := time.Time{}
+	var earliestTime, latestTime time.Time
 
-	for _, e := range s.Events {
-		if e.Time.Before(earliestTime) {
-			earliestTime = e.Time
-		} else if e.Time.After(latestTime) {
-			latestTime = e.Time
+	include := func(t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		if earliestTime.IsZero() || t.Before(earliestTime) {
+			earliestTime = t
+		}
+		if t.After(latestTime) {
+			latestTime = t
 		}
 	}
 
+	for _, e := range s.Events {
+		include(e.Time)
+	}
+
 	for _, e := range s.Stages {
-		if e.End.Before(earliestTime) {
-			earliestTime = e.End
-		} else if e.End.After(latestTime) {
-			latestTime = e.End
+		if e.End.IsZero() {
+			include(e.Start)
+		} else {
+			include(e.End)
 		}
 	}
 
@@ -0,0 +1,308 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/twchart"
+)
+
+const (
+	// Firebase Authentication endpoints for signing in with an email and password and refreshing tokens
+	defaultFirebaseAuthURL  = "https://identitytoolkit.googleapis.com/v1/accounts:signInWithPassword"
+	defaultFirebaseTokenURL = "https://securetoken.googleapis.com/v1/token"
+
+	// tokens are refreshed a little early so they don't expire during a request
+	tokenExpiryMargin = time.Minute
+)
+
+// ErrUnauthorized is returned when the credentials are rejected
+var ErrUnauthorized = errors.New("unauthorized")
+
+// StatusError is returned for unexpected HTTP responses
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// FirebaseHTTPConfig configures the FirebaseHTTPSource
+type FirebaseHTTPConfig struct {
+	Email    string
+	Password string
+	// APIKey is the public web API key of the Firebase project
+	APIKey string
+	// Device is the serial number of the device to get readings from
+	Device string
+
+	// ReadingsURL is the endpoint for device readings. It receives device and since query parameters and responds
+	// with JSON like:
+	//   {"readings": [{"time": "2025-05-24T08:00:00Z", "channels": [{"channel": 1, "value": 225.5}]}]}
+	ReadingsURL string
+	// AuthURL and TokenURL are used to sign in and refresh tokens. They default to the Firebase endpoints
+	AuthURL  string
+	TokenURL string
+
+	HTTPClient *http.Client
+}
+
+// FirebaseHTTPSource is a ReadingSource that signs in with Firebase Authentication and gets a device's readings from
+// an HTTP endpoint using the account's token. The token is refreshed when it expires or is rejected. It is not a
+// Thermoworks Cloud client: that API is not documented, so the readings endpoint is one you provide with ReadingsURL
+type FirebaseHTTPSource struct {
+	cfg FirebaseHTTPConfig
+	now func() time.Time
+
+	mu           sync.Mutex
+	idToken      string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+var _ ReadingSource = &FirebaseHTTPSource{}
+
+// NewFirebaseHTTPSource validates the config and creates a FirebaseHTTPSource
+func NewFirebaseHTTPSource(cfg FirebaseHTTPConfig) (*FirebaseHTTPSource, error) {
+	if cfg.Email == "" || cfg.Password == "" {
+		return nil, errors.New("missing Email or Password")
+	}
+	if cfg.Device == "" {
+		return nil, errors.New("missing Device")
+	}
+	if cfg.ReadingsURL == "" {
+		return nil, errors.New("missing ReadingsURL")
+	}
+
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = defaultFirebaseAuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = defaultFirebaseTokenURL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &FirebaseHTTPSource{cfg: cfg, now: time.Now}, nil
+}
+
+type readingsResponse struct {
+	Readings []struct {
+		Time     time.Time
+		Channels []struct {
+			Channel int
+			Value   float64
+		}
+	}
+}
+
+// Readings gets the device's readings after since. If the token is rejected, it is refreshed and the request is
+// retried once
+func (c *FirebaseHTTPSource) Readings(ctx context.Context, since time.Time) ([]twchart.Reading, error) {
+	resp, err := c.getReadings(ctx, since)
+	if errors.Is(err, ErrUnauthorized) {
+		c.mu.Lock()
+		c.expiresAt = time.Time{}
+		c.mu.Unlock()
+
+		resp, err = c.getReadings(ctx, since)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []twchart.Reading
+	for _, r := range resp.Readings {
+		for _, ch := range r.Channels {
+			var probe twchart.ProbePosition
+			err := probe.UnmarshalText([]byte(strconv.Itoa(ch.Channel)))
+			if err != nil {
+				return nil, fmt.Errorf("error parsing channel: %w", err)
+			}
+
+			readings = append(readings, twchart.Reading{Time: r.Time, Probe: probe, Value: ch.Value})
+		}
+	}
+
+	return readings, nil
+}
+
+func (c *FirebaseHTTPSource) getReadings(ctx context.Context, since time.Time) (readingsResponse, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return readingsResponse{}, fmt.Errorf("error authenticating: %w", err)
+	}
+
+	u, err := url.Parse(c.cfg.ReadingsURL)
+	if err != nil {
+		return readingsResponse{}, fmt.Errorf("invalid ReadingsURL: %w", err)
+	}
+	query := u.Query()
+	query.Set("device", c.cfg.Device)
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339))
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return readingsResponse{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp readingsResponse
+	err = c.do(req, &resp)
+	return resp, err
+}
+
+// token returns the current ID token. It signs in or refreshes the token if needed
+func (c *FirebaseHTTPSource) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.idToken != "" && c.now().Before(c.expiresAt) {
+		return c.idToken, nil
+	}
+
+	// If the refresh token is rejected, sign in again
+	if c.refreshToken != "" {
+		err := c.refresh(ctx)
+		if err == nil {
+			return c.idToken, nil
+		}
+		if !errors.Is(err, ErrUnauthorized) {
+			return "", err
+		}
+	}
+
+	err := c.signIn(ctx)
+	if err != nil {
+		return "", err
+	}
+	return c.idToken, nil
+}
+
+func (c *FirebaseHTTPSource) signIn(ctx context.Context) error {
+	body, err := json.Marshal(map[string]any{
+		"email":             c.cfg.Email,
+		"password":          c.cfg.Password,
+		"returnSecureToken": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.withKey(c.cfg.AuthURL), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		IDToken      string
+		RefreshToken string
+		ExpiresIn    string
+	}
+	err = c.do(req, &resp)
+	if err != nil {
+		return fmt.Errorf("error signing in: %w", err)
+	}
+
+	return c.setToken(resp.IDToken, resp.RefreshToken, resp.ExpiresIn)
+}
+
+func (c *FirebaseHTTPSource) refresh(ctx context.Context) error {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {c.refreshToken},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.withKey(c.cfg.TokenURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		IDToken      string `json:"id_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    string `json:"expires_in"`
+	}
+	err = c.do(req, &resp)
+	// Firebase rejects expired or revoked refresh tokens with a 400
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+		err = ErrUnauthorized
+	}
+	if err != nil {
+		return fmt.Errorf("error refreshing token: %w", err)
+	}
+
+	return c.setToken(resp.IDToken, resp.RefreshToken, resp.ExpiresIn)
+}
+
+func (c *FirebaseHTTPSource) setToken(idToken, refreshToken, expiresIn string) error {
+	seconds, err := strconv.Atoi(expiresIn)
+	if err != nil {
+		return fmt.Errorf("invalid token expiration %q: %w", expiresIn, err)
+	}
+
+	c.idToken = idToken
+	c.refreshToken = refreshToken
+	c.expiresAt = c.now().Add(time.Duration(seconds)*time.Second - tokenExpiryMargin)
+	return nil
+}
+
+func (c *FirebaseHTTPSource) withKey(endpoint string) string {
+	if c.cfg.APIKey == "" {
+		return endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	query := u.Query()
+	query.Set("key", c.cfg.APIKey)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// do sends the request and decodes the JSON response. 401 and 403 responses return ErrUnauthorized
+func (c *FirebaseHTTPSource) do(req *http.Request, target any) error {
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		// the query is left out since it can have the API key
+		endpoint := url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}
+		return &StatusError{URL: endpoint.String(), StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(target)
+	if err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
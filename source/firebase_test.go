@@ -0,0 +1,310 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/twchart"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var readingsStart = time.Date(2025, time.May, 24, 8, 0, 0, 0, time.UTC)
+
+// fakeFirebase is an in-process readings endpoint with Firebase-style authentication
+type fakeFirebase struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	signIns      int
+	refreshes    int
+	validToken   string
+	refreshToken string
+	// rejectRefresh makes the token endpoint reject refresh tokens like Firebase does when they are revoked
+	rejectRefresh bool
+	// failures is the number of readings requests that fail before succeeding
+	failures int
+	readings int
+}
+
+func newFakeFirebase(t *testing.T) *fakeFirebase {
+	fake := &fakeFirebase{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/accounts:signInWithPassword", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Email    string
+			Password string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		if r.URL.Query().Get("key") != "api-key" || req.Email != "cook@example.com" || req.Password != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.signIns++
+		fake.validToken = fmt.Sprintf("id-token-%d", fake.signIns)
+		fake.refreshToken = fmt.Sprintf("refresh-token-%d", fake.signIns)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"idToken":      fake.validToken,
+			"refreshToken": fake.refreshToken,
+			"expiresIn":    "3600",
+		})
+	})
+	mux.HandleFunc("POST /v1/token", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		if fake.rejectRefresh || r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != fake.refreshToken {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"message": "TOKEN_EXPIRED"}}`))
+			return
+		}
+
+		fake.refreshes++
+		fake.validToken = fmt.Sprintf("refreshed-token-%d", fake.refreshes)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id_token":      fake.validToken,
+			"refresh_token": fake.refreshToken,
+			"expires_in":    "3600",
+		})
+	})
+	mux.HandleFunc("GET /readings", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+fake.validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if fake.failures > 0 {
+			fake.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("device") != "serial-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		since := readingsStart.Add(-time.Second)
+		if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+			since, _ = time.Parse(time.RFC3339, sinceParam)
+		}
+
+		// every request has one new reading after since, and repeats the reading at since
+		fake.readings++
+		type channel struct {
+			Channel int     `json:"channel"`
+			Value   float64 `json:"value"`
+		}
+		type reading struct {
+			Time     time.Time `json:"time"`
+			Channels []channel `json:"channels"`
+		}
+		var resp struct {
+			Readings []reading `json:"readings"`
+		}
+		for _, t := range []time.Time{since, since.Add(time.Second)} {
+			if t.Before(readingsStart) {
+				continue
+			}
+			value := 100 + t.Sub(readingsStart).Seconds()
+			resp.Readings = append(resp.Readings, reading{
+				Time:     t,
+				Channels: []channel{{1, 250}, {2, value}},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (f *fakeFirebase) client(t *testing.T) *FirebaseHTTPSource {
+	client, err := NewFirebaseHTTPSource(FirebaseHTTPConfig{
+		Email:       "cook@example.com",
+		Password:    "secret",
+		APIKey:      "api-key",
+		Device:      "serial-1",
+		ReadingsURL: f.URL + "/readings",
+		AuthURL:     f.URL + "/v1/accounts:signInWithPassword",
+		TokenURL:    f.URL + "/v1/token",
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestFirebaseHTTPSource(t *testing.T) {
+	t.Run("SignInAndGetReadings", func(t *testing.T) {
+		fake := newFakeFirebase(t)
+		client := fake.client(t)
+
+		readings, err := client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, []twchart.Reading{
+			{Time: readingsStart, Probe: twchart.ProbePosition1, Value: 250},
+			{Time: readingsStart, Probe: twchart.ProbePosition2, Value: 100},
+		}, readings)
+
+		_, err = client.Readings(t.Context(), readingsStart)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fake.signIns)
+		assert.Equal(t, 0, fake.refreshes)
+	})
+
+	t.Run("RefreshExpiredToken", func(t *testing.T) {
+		fake := newFakeFirebase(t)
+		client := fake.client(t)
+
+		_, err := client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+
+		// the token is refreshed before it expires
+		client.now = func() time.Time { return time.Now().Add(59*time.Minute + time.Second) }
+		_, err = client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, fake.signIns)
+		assert.Equal(t, 1, fake.refreshes)
+	})
+
+	t.Run("RefreshRejectedToken", func(t *testing.T) {
+		fake := newFakeFirebase(t)
+		client := fake.client(t)
+
+		_, err := client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+
+		// the server revokes the token before it expires
+		fake.mu.Lock()
+		fake.validToken = "revoked"
+		fake.mu.Unlock()
+
+		_, err = client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, fake.signIns)
+		assert.Equal(t, 1, fake.refreshes)
+		assert.Equal(t, 2, fake.readings)
+	})
+
+	t.Run("SignInAgainWhenRefreshFails", func(t *testing.T) {
+		fake := newFakeFirebase(t)
+		client := fake.client(t)
+
+		_, err := client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+
+		fake.mu.Lock()
+		fake.validToken = "revoked"
+		fake.rejectRefresh = true
+		fake.mu.Unlock()
+
+		_, err = client.Readings(t.Context(), time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, fake.signIns)
+		assert.Equal(t, 0, fake.refreshes)
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		fake := newFakeFirebase(t)
+		client := fake.client(t)
+		client.cfg.Password = "wrong"
+
+		_, err := client.Readings(t.Context(), time.Time{})
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		assert.NotContains(t, err.Error(), "api-key")
+	})
+
+	t.Run("MissingConfig", func(t *testing.T) {
+		_, err := NewFirebaseHTTPSource(FirebaseHTTPConfig{Email: "cook@example.com", Password: "secret", Device: "serial-1"})
+		assert.EqualError(t, err, "missing ReadingsURL")
+	})
+}
+
+func TestPoller(t *testing.T) {
+	fake := newFakeFirebase(t)
+	fake.failures = 3
+
+	var received []twchart.Reading
+	sinkFailures := 1
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var waits []time.Duration
+	poller := &Poller{
+		Source: fake.client(t),
+		Sink: SinkFunc(func(_ context.Context, readings []twchart.Reading) error {
+			if sinkFailures > 0 {
+				sinkFailures--
+				return fmt.Errorf("sink unavailable")
+			}
+			received = append(received, readings...)
+			if len(received) >= 6 {
+				cancel()
+			}
+			return nil
+		}),
+		Interval:   time.Second,
+		MaxBackoff: 5 * time.Second,
+		after: func(d time.Duration) <-chan time.Time {
+			waits = append(waits, d)
+			c := make(chan time.Time, 1)
+			c <- time.Time{}
+			return c
+		},
+	}
+
+	err := poller.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// three source failures and one sink failure back off until the max, then successes use the interval
+	assert.Equal(t, []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, time.Second, time.Second, time.Second}, waits)
+
+	// repeated readings are not sent to the sink again
+	assert.Len(t, received, 6)
+	for i := 1; i < len(received); i += 2 {
+		assert.Equal(t, readingsStart.Add(time.Duration(i/2)*time.Second), received[i].Time)
+	}
+	assert.Equal(t, readingsStart.Add(2*time.Second), poller.Since)
+}
+
+func TestSessionSink(t *testing.T) {
+	var got []twchart.Reading
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sessions/abc/readings" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":"Resource not found."}`))
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"Readings": 1, "Rows": 1}`))
+	}))
+	defer server.Close()
+
+	readings := []twchart.Reading{{Time: readingsStart, Probe: twchart.ProbePosition1, Value: 250}}
+	err := SessionSink{ServerURL: server.URL, SessionID: "abc"}.AddReadings(t.Context(), readings)
+	require.NoError(t, err)
+	assert.Equal(t, readings, got)
+
+	err = SessionSink{ServerURL: server.URL, SessionID: "missing"}.AddReadings(t.Context(), readings)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
@@ -0,0 +1,188 @@
+// Package source gets live probe readings from external services so they can be added to a Session while a cook is
+// in progress
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/calvinmclean/twchart"
+)
+
+const (
+	defaultInterval   = 30 * time.Second
+	defaultMaxBackoff = 10 * time.Minute
+)
+
+// ReadingSource provides probe readings from a device
+type ReadingSource interface {
+	// Readings returns the readings recorded after since, in order
+	Readings(ctx context.Context, since time.Time) ([]twchart.Reading, error)
+}
+
+// Sink receives new readings from a Poller
+type Sink interface {
+	AddReadings(ctx context.Context, readings []twchart.Reading) error
+}
+
+// SinkFunc allows a function to be used as a Sink
+type SinkFunc func(context.Context, []twchart.Reading) error
+
+func (f SinkFunc) AddReadings(ctx context.Context, readings []twchart.Reading) error {
+	return f(ctx, readings)
+}
+
+// Poller gets new readings from a ReadingSource on an interval and sends them to a Sink. When the Source or Sink
+// fail, the time between polls is doubled up to MaxBackoff
+type Poller struct {
+	Source ReadingSource
+	Sink   Sink
+
+	// Interval is the time between polls. Defaults to 30s
+	Interval time.Duration
+	// MaxBackoff is the longest time between polls after errors. Defaults to 10m
+	MaxBackoff time.Duration
+	// Since is the time to start getting readings from. It is updated as readings are received. If it is zero, all
+	// of the readings available from the Source are used
+	Since time.Time
+
+	Logger *slog.Logger
+
+	// after is used to wait between polls so tests don't have to
+	after func(time.Duration) <-chan time.Time
+}
+
+// Run polls until the context is cancelled
+func (p *Poller) Run(ctx context.Context) error {
+	if p.Source == nil || p.Sink == nil {
+		return errors.New("missing Source or Sink")
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	after := p.after
+	if after == nil {
+		after = time.After
+	}
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	wait := time.Duration(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-after(wait):
+		}
+
+		n, err := p.poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			wait = min(max(2*wait, interval), maxBackoff)
+			logger.Warn("error polling readings", "error", err, "retry_in", wait)
+			continue
+		}
+
+		logger.Debug("polled readings", "count", n, "since", p.Since)
+		wait = interval
+	}
+}
+
+// poll gets new readings and sends them to the Sink. Since is only moved forward once the Sink accepts them so
+// nothing is lost when it fails
+func (p *Poller) poll(ctx context.Context) (int, error) {
+	readings, err := p.Source.Readings(ctx, p.Since)
+	if err != nil {
+		return 0, err
+	}
+
+	// ignore anything that was already received in case the Source includes readings at the since time
+	newReadings := make([]twchart.Reading, 0, len(readings))
+	latest := p.Since
+	for _, r := range readings {
+		if !r.Time.After(p.Since) {
+			continue
+		}
+		newReadings = append(newReadings, r)
+		if r.Time.After(latest) {
+			latest = r.Time
+		}
+	}
+
+	if len(newReadings) == 0 {
+		return 0, nil
+	}
+
+	err = p.Sink.AddReadings(ctx, newReadings)
+	if err != nil {
+		return 0, err
+	}
+
+	p.Since = latest
+	return len(newReadings), nil
+}
+
+// SessionSink sends readings to a twchart server's /sessions/{id}/readings endpoint
+type SessionSink struct {
+	// ServerURL is the base URL of the server, like http://localhost:8080
+	ServerURL string
+	SessionID string
+
+	HTTPClient *http.Client
+}
+
+var _ Sink = SessionSink{}
+
+func (s SessionSink) AddReadings(ctx context.Context, readings []twchart.Reading) error {
+	body, err := json.Marshal(readings)
+	if err != nil {
+		return fmt.Errorf("error encoding readings: %w", err)
+	}
+
+	endpoint, err := url.JoinPath(s.ServerURL, "sessions", s.SessionID, "readings")
+	if err != nil {
+		return fmt.Errorf("invalid ServerURL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{URL: endpoint, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	return nil
+}
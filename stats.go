@@ -0,0 +1,83 @@
+package twchart
+
+import (
+	"math"
+	"time"
+)
+
+// ProbeStats summarizes a probe's readings over a period of time
+type ProbeStats struct {
+	Probe   Probe
+	Samples int
+
+	Min  float64
+	Max  float64
+	Mean float64
+
+	Start float64
+	End   float64
+	// Delta is the difference between the End and Start temperatures
+	Delta float64
+}
+
+// StageStats has the ProbeStats for each of the Session's probes during a Stage
+type StageStats struct {
+	Stage  Stage
+	Probes []ProbeStats
+}
+
+// StageStats calculates the stats for each Stage using the cleaned data. Probes without any readings during a
+// Stage are not included
+func (s Session) StageStats() []StageStats {
+	data := s.CleanData()
+
+	result := make([]StageStats, 0, len(s.Stages))
+	for _, stage := range s.Stages {
+		stats := StageStats{Stage: stage, Probes: []ProbeStats{}}
+		for _, probe := range s.Probes {
+			probeStats, ok := CalculateProbeStats(data, probe, stage.Start, stage.End)
+			if ok {
+				stats.Probes = append(stats.Probes, probeStats)
+			}
+		}
+		result = append(result, stats)
+	}
+
+	return result
+}
+
+// CalculateProbeStats calculates stats for the probe's readings between start and end. If end is zero, all
+// readings after start are used. The second return value is false if there are no readings
+func CalculateProbeStats(data []ThermoworksData, probe Probe, start, end time.Time) (ProbeStats, bool) {
+	stats := ProbeStats{
+		Probe: probe,
+		Min:   math.Inf(1),
+		Max:   math.Inf(-1),
+	}
+
+	var sum float64
+	for _, d := range data {
+		if d.Time.Before(start) || (!end.IsZero() && d.Time.After(end)) || !d.HasProbeData(probe.Position) {
+			continue
+		}
+
+		value := d.GetProbeData(probe.Position)
+		if stats.Samples == 0 {
+			stats.Start = value
+		}
+		stats.End = value
+		stats.Min = min(stats.Min, value)
+		stats.Max = max(stats.Max, value)
+		sum += value
+		stats.Samples++
+	}
+
+	if stats.Samples == 0 {
+		return ProbeStats{}, false
+	}
+
+	stats.Mean = sum / float64(stats.Samples)
+	stats.Delta = stats.End - stats.Start
+
+	return stats, true
+}
@@ -0,0 +1,56 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageStats(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.Local)
+	s := Session{
+		Probes: []Probe{
+			{Name: "Ambient", Position: ProbePosition1},
+			{Name: "Meat", Position: ProbePosition2},
+		},
+		Stages: []Stage{
+			{Name: "Smoke", Start: start, End: start.Add(2 * time.Minute)},
+			{Name: "Rest", Start: start.Add(3 * time.Minute)},
+		},
+		Data: []ThermoworksData{
+			{Time: start, ProbeData: []float64{225, 40}},
+			{Time: start.Add(time.Minute), ProbeData: []float64{235, missingReading}},
+			{Time: start.Add(2 * time.Minute), ProbeData: []float64{230, 60}},
+			{Time: start.Add(3 * time.Minute), ProbeData: []float64{missingReading, 62}},
+			{Time: start.Add(4 * time.Minute), ProbeData: []float64{missingReading, 58}},
+		},
+	}
+
+	stats := s.StageStats()
+	assert.Len(t, stats, 2)
+
+	assert.Equal(t, "Smoke", stats[0].Stage.Name)
+	assert.Equal(t, []ProbeStats{
+		{
+			Probe:   s.Probes[0],
+			Samples: 3,
+			Min:     225, Max: 235, Mean: 230,
+			Start: 225, End: 230, Delta: 5,
+		},
+		{
+			Probe:   s.Probes[1],
+			Samples: 2,
+			Min:     40, Max: 60, Mean: 50,
+			Start: 40, End: 60, Delta: 20,
+		},
+	}, stats[0].Probes)
+
+	// the unfinished stage uses all remaining data and skips probes without readings
+	assert.Equal(t, []ProbeStats{{
+		Probe:   s.Probes[1],
+		Samples: 2,
+		Min:     58, Max: 62, Mean: 60,
+		Start: 62, End: 58, Delta: -4,
+	}}, stats[1].Probes)
+}
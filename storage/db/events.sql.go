@@ -10,7 +10,7 @@ import (
 	"time"
 )
 
-const createEvent = `-- name: CreateEvent :one
+const CreateEvent = `-- name: CreateEvent :one
 INSERT INTO events (session_id, note, time)
 VALUES (?, ?, ?)
 RETURNING id, session_id, note, time
@@ -23,7 +23,7 @@ type CreateEventParams struct {
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
-	row := q.db.QueryRowContext(ctx, createEvent, arg.SessionID, arg.Note, arg.Time)
+	row := q.db.QueryRowContext(ctx, CreateEvent, arg.SessionID, arg.Note, arg.Time)
 	var i Event
 	err := row.Scan(
 		&i.ID,
@@ -34,23 +34,23 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 	return i, err
 }
 
-const deleteEventsBySession = `-- name: DeleteEventsBySession :exec
+const DeleteEventsBySession = `-- name: DeleteEventsBySession :exec
 DELETE FROM events WHERE session_id = ?
 `
 
 func (q *Queries) DeleteEventsBySession(ctx context.Context, sessionID string) error {
-	_, err := q.db.ExecContext(ctx, deleteEventsBySession, sessionID)
+	_, err := q.db.ExecContext(ctx, DeleteEventsBySession, sessionID)
 	return err
 }
 
-const getEventsBySession = `-- name: GetEventsBySession :many
+const GetEventsBySession = `-- name: GetEventsBySession :many
 SELECT id, session_id, note, time FROM events
 WHERE session_id = ?
 ORDER BY time
 `
 
 func (q *Queries) GetEventsBySession(ctx context.Context, sessionID string) ([]Event, error) {
-	rows, err := q.db.QueryContext(ctx, getEventsBySession, sessionID)
+	rows, err := q.db.QueryContext(ctx, GetEventsBySession, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +76,32 @@ func (q *Queries) GetEventsBySession(ctx context.Context, sessionID string) ([]E
 	}
 	return items, nil
 }
+
+const UpdateEventTime = `-- name: UpdateEventTime :execrows
+UPDATE events SET time = ?1
+WHERE id = (
+    SELECT id FROM events
+    WHERE session_id = ?2 AND note = ?3 AND julianday(time) = julianday(?4)
+    LIMIT 1
+)
+`
+
+type UpdateEventTimeParams struct {
+	ToTime    time.Time
+	SessionID string
+	Note      string
+	FromTime  interface{}
+}
+
+func (q *Queries) UpdateEventTime(ctx context.Context, arg UpdateEventTimeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, UpdateEventTime,
+		arg.ToTime,
+		arg.SessionID,
+		arg.Note,
+		arg.FromTime,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
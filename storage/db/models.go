@@ -21,6 +21,9 @@ type Probe struct {
 	SessionID string
 	Name      string
 	Position  int64
+	Target    sql.NullFloat64
+	Kind      sql.NullString
+	Unit      sql.NullString
 }
 
 type Session struct {
@@ -32,6 +35,8 @@ type Session struct {
 	CreatedAt  sql.NullTime
 	UpdatedAt  sql.NullTime
 	Type       string
+	Cleaning   sql.NullString
+	Alerts     sql.NullString
 }
 
 type Stage struct {
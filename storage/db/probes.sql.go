@@ -7,48 +7,62 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
-const createProbe = `-- name: CreateProbe :one
-INSERT INTO probes (session_id, name, position)
-VALUES (?, ?, ?)
-RETURNING id, session_id, name, position
+const CreateProbe = `-- name: CreateProbe :one
+INSERT INTO probes (session_id, name, position, target, kind, unit)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, session_id, name, position, target, kind, unit
 `
 
 type CreateProbeParams struct {
 	SessionID string
 	Name      string
 	Position  int64
+	Target    sql.NullFloat64
+	Kind      sql.NullString
+	Unit      sql.NullString
 }
 
 func (q *Queries) CreateProbe(ctx context.Context, arg CreateProbeParams) (Probe, error) {
-	row := q.db.QueryRowContext(ctx, createProbe, arg.SessionID, arg.Name, arg.Position)
+	row := q.db.QueryRowContext(ctx, CreateProbe,
+		arg.SessionID,
+		arg.Name,
+		arg.Position,
+		arg.Target,
+		arg.Kind,
+		arg.Unit,
+	)
 	var i Probe
 	err := row.Scan(
 		&i.ID,
 		&i.SessionID,
 		&i.Name,
 		&i.Position,
+		&i.Target,
+		&i.Kind,
+		&i.Unit,
 	)
 	return i, err
 }
 
-const deleteProbesBySession = `-- name: DeleteProbesBySession :exec
+const DeleteProbesBySession = `-- name: DeleteProbesBySession :exec
 DELETE FROM probes WHERE session_id = ?
 `
 
 func (q *Queries) DeleteProbesBySession(ctx context.Context, sessionID string) error {
-	_, err := q.db.ExecContext(ctx, deleteProbesBySession, sessionID)
+	_, err := q.db.ExecContext(ctx, DeleteProbesBySession, sessionID)
 	return err
 }
 
-const getProbesBySession = `-- name: GetProbesBySession :many
-SELECT id, session_id, name, position FROM probes
+const GetProbesBySession = `-- name: GetProbesBySession :many
+SELECT id, session_id, name, position, target, kind, unit FROM probes
 WHERE session_id = ?
 `
 
 func (q *Queries) GetProbesBySession(ctx context.Context, sessionID string) ([]Probe, error) {
-	rows, err := q.db.QueryContext(ctx, getProbesBySession, sessionID)
+	rows, err := q.db.QueryContext(ctx, GetProbesBySession, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +75,9 @@ func (q *Queries) GetProbesBySession(ctx context.Context, sessionID string) ([]P
 			&i.SessionID,
 			&i.Name,
 			&i.Position,
+			&i.Target,
+			&i.Kind,
+			&i.Unit,
 		); err != nil {
 			return nil, err
 		}
@@ -11,35 +11,35 @@ import (
 	"time"
 )
 
-const countSessions = `-- name: CountSessions :one
+const CountSessions = `-- name: CountSessions :one
 SELECT COUNT(*) FROM sessions
 `
 
 func (q *Queries) CountSessions(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countSessions)
+	row := q.db.QueryRowContext(ctx, CountSessions)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
-const countSessionsByType = `-- name: CountSessionsByType :one
+const CountSessionsByType = `-- name: CountSessionsByType :one
 SELECT COUNT(*) FROM sessions WHERE type = ?
 `
 
 func (q *Queries) CountSessionsByType(ctx context.Context, type_ string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countSessionsByType, type_)
+	row := q.db.QueryRowContext(ctx, CountSessionsByType, type_)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
-const createSession = `-- name: CreateSession :one
+const CreateSession = `-- name: CreateSession :one
 INSERT INTO sessions (
-    id, name, type, date, start_time, uploaded_at
+    id, name, type, date, start_time, uploaded_at, cleaning, alerts
 ) VALUES (
-    ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, name, date, start_time, uploaded_at, created_at, updated_at, type
+RETURNING id, name, date, start_time, uploaded_at, created_at, updated_at, type, cleaning, alerts
 `
 
 type CreateSessionParams struct {
@@ -49,16 +49,20 @@ type CreateSessionParams struct {
 	Date       time.Time
 	StartTime  sql.NullTime
 	UploadedAt time.Time
+	Cleaning   sql.NullString
+	Alerts     sql.NullString
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
-	row := q.db.QueryRowContext(ctx, createSession,
+	row := q.db.QueryRowContext(ctx, CreateSession,
 		arg.ID,
 		arg.Name,
 		arg.Type,
 		arg.Date,
 		arg.StartTime,
 		arg.UploadedAt,
+		arg.Cleaning,
+		arg.Alerts,
 	)
 	var i Session
 	err := row.Scan(
@@ -70,39 +74,41 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.Cleaning,
+		&i.Alerts,
 	)
 	return i, err
 }
 
-const deleteSession = `-- name: DeleteSession :exec
+const DeleteSession = `-- name: DeleteSession :exec
 DELETE FROM sessions WHERE id = ?
 `
 
 func (q *Queries) DeleteSession(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	_, err := q.db.ExecContext(ctx, DeleteSession, id)
 	return err
 }
 
-const getLatestSessionID = `-- name: GetLatestSessionID :one
+const GetLatestSessionID = `-- name: GetLatestSessionID :one
 SELECT id FROM sessions
 ORDER BY uploaded_at DESC
 LIMIT 1
 `
 
 func (q *Queries) GetLatestSessionID(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getLatestSessionID)
+	row := q.db.QueryRowContext(ctx, GetLatestSessionID)
 	var id string
 	err := row.Scan(&id)
 	return id, err
 }
 
-const getSession = `-- name: GetSession :one
-SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type FROM sessions
+const GetSession = `-- name: GetSession :one
+SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type, cleaning, alerts FROM sessions
 WHERE id = ?
 `
 
 func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
-	row := q.db.QueryRowContext(ctx, getSession, id)
+	row := q.db.QueryRowContext(ctx, GetSession, id)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -113,12 +119,14 @@ func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.Cleaning,
+		&i.Alerts,
 	)
 	return i, err
 }
 
-const listSessions = `-- name: ListSessions :many
-SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type FROM sessions
+const ListSessions = `-- name: ListSessions :many
+SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type, cleaning, alerts FROM sessions
 ORDER BY uploaded_at DESC
 LIMIT ?
 OFFSET ?
@@ -130,7 +138,7 @@ type ListSessionsParams struct {
 }
 
 func (q *Queries) ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error) {
-	rows, err := q.db.QueryContext(ctx, listSessions, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, ListSessions, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +155,8 @@ func (q *Queries) ListSessions(ctx context.Context, arg ListSessionsParams) ([]S
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Type,
+			&i.Cleaning,
+			&i.Alerts,
 		); err != nil {
 			return nil, err
 		}
@@ -161,8 +171,53 @@ func (q *Queries) ListSessions(ctx context.Context, arg ListSessionsParams) ([]S
 	return items, nil
 }
 
-const listSessionsByType = `-- name: ListSessionsByType :many
-SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type FROM sessions
+const ListSessionsByDate = `-- name: ListSessionsByDate :many
+SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type, cleaning, alerts FROM sessions
+WHERE substr(date, 1, 10) BETWEEN ?1 AND ?2
+ORDER BY date
+`
+
+type ListSessionsByDateParams struct {
+	FromDate string
+	ToDate   string
+}
+
+func (q *Queries) ListSessionsByDate(ctx context.Context, arg ListSessionsByDateParams) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, ListSessionsByDate, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.StartTime,
+			&i.UploadedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.Cleaning,
+			&i.Alerts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListSessionsByType = `-- name: ListSessionsByType :many
+SELECT id, name, date, start_time, uploaded_at, created_at, updated_at, type, cleaning, alerts FROM sessions
 WHERE type = ?
 ORDER BY uploaded_at DESC
 LIMIT ?
@@ -176,7 +231,7 @@ type ListSessionsByTypeParams struct {
 }
 
 func (q *Queries) ListSessionsByType(ctx context.Context, arg ListSessionsByTypeParams) ([]Session, error) {
-	rows, err := q.db.QueryContext(ctx, listSessionsByType, arg.Type, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, ListSessionsByType, arg.Type, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +248,8 @@ func (q *Queries) ListSessionsByType(ctx context.Context, arg ListSessionsByType
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Type,
+			&i.Cleaning,
+			&i.Alerts,
 		); err != nil {
 			return nil, err
 		}
@@ -207,11 +264,11 @@ func (q *Queries) ListSessionsByType(ctx context.Context, arg ListSessionsByType
 	return items, nil
 }
 
-const updateSession = `-- name: UpdateSession :one
+const UpdateSession = `-- name: UpdateSession :one
 UPDATE sessions
-SET name = ?, type = ?, date = ?, start_time = ?, updated_at = CURRENT_TIMESTAMP
+SET name = ?, type = ?, date = ?, start_time = ?, cleaning = ?, alerts = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, date, start_time, uploaded_at, created_at, updated_at, type
+RETURNING id, name, date, start_time, uploaded_at, created_at, updated_at, type, cleaning, alerts
 `
 
 type UpdateSessionParams struct {
@@ -219,15 +276,19 @@ type UpdateSessionParams struct {
 	Type      string
 	Date      time.Time
 	StartTime sql.NullTime
+	Cleaning  sql.NullString
+	Alerts    sql.NullString
 	ID        string
 }
 
 func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error) {
-	row := q.db.QueryRowContext(ctx, updateSession,
+	row := q.db.QueryRowContext(ctx, UpdateSession,
 		arg.Name,
 		arg.Type,
 		arg.Date,
 		arg.StartTime,
+		arg.Cleaning,
+		arg.Alerts,
 		arg.ID,
 	)
 	var i Session
@@ -240,6 +301,8 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Type,
+		&i.Cleaning,
+		&i.Alerts,
 	)
 	return i, err
 }
@@ -11,7 +11,7 @@ import (
 	"time"
 )
 
-const createStage = `-- name: CreateStage :one
+const CreateStage = `-- name: CreateStage :one
 INSERT INTO stages (session_id, name, start, end, duration)
 VALUES (?, ?, ?, ?, ?)
 RETURNING id, session_id, name, start, "end", duration
@@ -26,7 +26,7 @@ type CreateStageParams struct {
 }
 
 func (q *Queries) CreateStage(ctx context.Context, arg CreateStageParams) (Stage, error) {
-	row := q.db.QueryRowContext(ctx, createStage,
+	row := q.db.QueryRowContext(ctx, CreateStage,
 		arg.SessionID,
 		arg.Name,
 		arg.Start,
@@ -45,23 +45,23 @@ func (q *Queries) CreateStage(ctx context.Context, arg CreateStageParams) (Stage
 	return i, err
 }
 
-const deleteStagesBySession = `-- name: DeleteStagesBySession :exec
+const DeleteStagesBySession = `-- name: DeleteStagesBySession :exec
 DELETE FROM stages WHERE session_id = ?
 `
 
 func (q *Queries) DeleteStagesBySession(ctx context.Context, sessionID string) error {
-	_, err := q.db.ExecContext(ctx, deleteStagesBySession, sessionID)
+	_, err := q.db.ExecContext(ctx, DeleteStagesBySession, sessionID)
 	return err
 }
 
-const getStagesBySession = `-- name: GetStagesBySession :many
+const GetStagesBySession = `-- name: GetStagesBySession :many
 SELECT id, session_id, name, start, "end", duration FROM stages
 WHERE session_id = ?
 ORDER BY start
 `
 
 func (q *Queries) GetStagesBySession(ctx context.Context, sessionID string) ([]Stage, error) {
-	rows, err := q.db.QueryContext(ctx, getStagesBySession, sessionID)
+	rows, err := q.db.QueryContext(ctx, GetStagesBySession, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +90,7 @@ func (q *Queries) GetStagesBySession(ctx context.Context, sessionID string) ([]S
 	return items, nil
 }
 
-const updateStage = `-- name: UpdateStage :one
+const UpdateStage = `-- name: UpdateStage :one
 UPDATE stages
 SET end = ?, duration = ?
 WHERE id = ?
@@ -104,7 +104,7 @@ type UpdateStageParams struct {
 }
 
 func (q *Queries) UpdateStage(ctx context.Context, arg UpdateStageParams) (Stage, error) {
-	row := q.db.QueryRowContext(ctx, updateStage, arg.End, arg.Duration, arg.ID)
+	row := q.db.QueryRowContext(ctx, UpdateStage, arg.End, arg.Duration, arg.ID)
 	var i Stage
 	err := row.Scan(
 		&i.ID,
@@ -11,7 +11,7 @@ import (
 	"time"
 )
 
-const createThermoworksData = `-- name: CreateThermoworksData :one
+const CreateThermoworksData = `-- name: CreateThermoworksData :one
 INSERT INTO thermoworks_data (
     session_id, timestamp, probe1_temp, probe2_temp, probe3_temp, probe4_temp, probe5_temp, probe6_temp
 ) VALUES (
@@ -32,7 +32,7 @@ type CreateThermoworksDataParams struct {
 }
 
 func (q *Queries) CreateThermoworksData(ctx context.Context, arg CreateThermoworksDataParams) (ThermoworksDatum, error) {
-	row := q.db.QueryRowContext(ctx, createThermoworksData,
+	row := q.db.QueryRowContext(ctx, CreateThermoworksData,
 		arg.SessionID,
 		arg.Timestamp,
 		arg.Probe1Temp,
@@ -57,23 +57,83 @@ func (q *Queries) CreateThermoworksData(ctx context.Context, arg CreateThermowor
 	return i, err
 }
 
-const deleteThermoworksDataBySession = `-- name: DeleteThermoworksDataBySession :exec
+const DeleteThermoworksDataBySession = `-- name: DeleteThermoworksDataBySession :exec
 DELETE FROM thermoworks_data WHERE session_id = ?
 `
 
 func (q *Queries) DeleteThermoworksDataBySession(ctx context.Context, sessionID string) error {
-	_, err := q.db.ExecContext(ctx, deleteThermoworksDataBySession, sessionID)
+	_, err := q.db.ExecContext(ctx, DeleteThermoworksDataBySession, sessionID)
 	return err
 }
 
-const getThermoworksDataBySession = `-- name: GetThermoworksDataBySession :many
+const GetLatestThermoworksDataTime = `-- name: GetLatestThermoworksDataTime :one
+SELECT timestamp FROM thermoworks_data
+WHERE session_id = ?
+ORDER BY julianday(timestamp) DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestThermoworksDataTime(ctx context.Context, sessionID string) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, GetLatestThermoworksDataTime, sessionID)
+	var timestamp time.Time
+	err := row.Scan(&timestamp)
+	return timestamp, err
+}
+
+const GetThermoworksDataBySession = `-- name: GetThermoworksDataBySession :many
 SELECT id, session_id, timestamp, probe1_temp, probe2_temp, probe3_temp, probe4_temp, probe5_temp, probe6_temp FROM thermoworks_data
 WHERE session_id = ?
-ORDER BY timestamp
+ORDER BY julianday(timestamp)
 `
 
 func (q *Queries) GetThermoworksDataBySession(ctx context.Context, sessionID string) ([]ThermoworksDatum, error) {
-	rows, err := q.db.QueryContext(ctx, getThermoworksDataBySession, sessionID)
+	rows, err := q.db.QueryContext(ctx, GetThermoworksDataBySession, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ThermoworksDatum
+	for rows.Next() {
+		var i ThermoworksDatum
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Timestamp,
+			&i.Probe1Temp,
+			&i.Probe2Temp,
+			&i.Probe3Temp,
+			&i.Probe4Temp,
+			&i.Probe5Temp,
+			&i.Probe6Temp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetThermoworksDataBySessionBetween = `-- name: GetThermoworksDataBySessionBetween :many
+SELECT id, session_id, timestamp, probe1_temp, probe2_temp, probe3_temp, probe4_temp, probe5_temp, probe6_temp FROM thermoworks_data
+WHERE session_id = ?1
+    AND julianday(timestamp) BETWEEN julianday(?2) AND julianday(?3)
+ORDER BY julianday(timestamp)
+`
+
+type GetThermoworksDataBySessionBetweenParams struct {
+	SessionID string
+	FromTime  interface{}
+	ToTime    interface{}
+}
+
+func (q *Queries) GetThermoworksDataBySessionBetween(ctx context.Context, arg GetThermoworksDataBySessionBetweenParams) ([]ThermoworksDatum, error) {
+	rows, err := q.db.QueryContext(ctx, GetThermoworksDataBySessionBetween, arg.SessionID, arg.FromTime, arg.ToTime)
 	if err != nil {
 		return nil, err
 	}
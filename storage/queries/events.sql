@@ -9,4 +9,12 @@ VALUES (?, ?, ?)
 RETURNING *;
 
 -- name: DeleteEventsBySession :exec
-DELETE FROM events WHERE session_id = ?;
\ No newline at end of file
+DELETE FROM events WHERE session_id = ?;
+
+-- name: UpdateEventTime :execrows
+UPDATE events SET time = sqlc.arg(to_time)
+WHERE id = (
+    SELECT id FROM events
+    WHERE session_id = sqlc.arg(session_id) AND note = sqlc.arg(note) AND julianday(time) = julianday(sqlc.arg(from_time))
+    LIMIT 1
+);
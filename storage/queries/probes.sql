@@ -3,8 +3,8 @@ SELECT * FROM probes
 WHERE session_id = ?;
 
 -- name: CreateProbe :one
-INSERT INTO probes (session_id, name, position)
-VALUES (?, ?, ?)
+INSERT INTO probes (session_id, name, position, target, kind, unit)
+VALUES (?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: DeleteProbesBySession :exec
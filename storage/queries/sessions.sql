@@ -15,6 +15,11 @@ ORDER BY uploaded_at DESC
 LIMIT ?
 OFFSET ?;
 
+-- name: ListSessionsByDate :many
+SELECT * FROM sessions
+WHERE substr(date, 1, 10) BETWEEN sqlc.arg(from_date) AND sqlc.arg(to_date)
+ORDER BY date;
+
 -- name: CountSessions :one
 SELECT COUNT(*) FROM sessions;
 
@@ -23,15 +28,15 @@ SELECT COUNT(*) FROM sessions WHERE type = ?;
 
 -- name: CreateSession :one
 INSERT INTO sessions (
-    id, name, type, date, start_time, uploaded_at
+    id, name, type, date, start_time, uploaded_at, cleaning, alerts
 ) VALUES (
-    ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
 -- name: UpdateSession :one
 UPDATE sessions
-SET name = ?, type = ?, date = ?, start_time = ?, updated_at = CURRENT_TIMESTAMP
+SET name = ?, type = ?, date = ?, start_time = ?, cleaning = ?, alerts = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 RETURNING *;
 
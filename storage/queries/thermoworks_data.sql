@@ -1,7 +1,7 @@
 -- name: GetThermoworksDataBySession :many
 SELECT * FROM thermoworks_data
 WHERE session_id = ?
-ORDER BY timestamp;
+ORDER BY julianday(timestamp);
 
 -- name: CreateThermoworksData :one
 INSERT INTO thermoworks_data (
@@ -12,4 +12,16 @@ INSERT INTO thermoworks_data (
 RETURNING *;
 
 -- name: DeleteThermoworksDataBySession :exec
-DELETE FROM thermoworks_data WHERE session_id = ?;
\ No newline at end of file
+DELETE FROM thermoworks_data WHERE session_id = ?;
+
+-- name: GetThermoworksDataBySessionBetween :many
+SELECT * FROM thermoworks_data
+WHERE session_id = sqlc.arg(session_id)
+    AND julianday(timestamp) BETWEEN julianday(sqlc.arg(from_time)) AND julianday(sqlc.arg(to_time))
+ORDER BY julianday(timestamp);
+
+-- name: GetLatestThermoworksDataTime :one
+SELECT timestamp FROM thermoworks_data
+WHERE session_id = ?
+ORDER BY julianday(timestamp) DESC
+LIMIT 1;
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"iter"
+
+	"github.com/calvinmclean/twchart/storage/db"
+)
+
+// StreamThermoworksDataBySessionBetween reads the same rows as GetThermoworksDataBySessionBetween, but yields each
+// row as it is read so a large Session's data doesn't have to be loaded at once
+func (c Client) StreamThermoworksDataBySessionBetween(ctx context.Context, arg db.GetThermoworksDataBySessionBetweenParams) iter.Seq2[db.ThermoworksDatum, error] {
+	return func(yield func(db.ThermoworksDatum, error) bool) {
+		rows, err := c.db.QueryContext(ctx, db.GetThermoworksDataBySessionBetween, arg.SessionID, arg.FromTime, arg.ToTime)
+		if err != nil {
+			yield(db.ThermoworksDatum{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var i db.ThermoworksDatum
+			err := rows.Scan(
+				&i.ID,
+				&i.SessionID,
+				&i.Timestamp,
+				&i.Probe1Temp,
+				&i.Probe2Temp,
+				&i.Probe3Temp,
+				&i.Probe4Temp,
+				&i.Probe5Temp,
+				&i.Probe6Temp,
+			)
+			if err != nil {
+				yield(db.ThermoworksDatum{}, err)
+				return
+			}
+			if !yield(i, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(db.ThermoworksDatum{}, err)
+		}
+	}
+}
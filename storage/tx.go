@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/twchart/storage/db"
+)
+
+// thermoworksDataBatchSize is the number of rows in each INSERT. Each row has 8 parameters, so a batch stays under
+// SQLite's limit of 999 parameters in older versions
+const thermoworksDataBatchSize = 100
+
+const insertThermoworksData = `INSERT INTO thermoworks_data (
+    session_id, timestamp, probe1_temp, probe2_temp, probe3_temp, probe4_temp, probe5_temp, probe6_temp
+) VALUES `
+
+const upsertThermoworksData = `
+ON CONFLICT (session_id, julianday(timestamp)) DO UPDATE SET
+    probe1_temp = COALESCE(excluded.probe1_temp, probe1_temp),
+    probe2_temp = COALESCE(excluded.probe2_temp, probe2_temp),
+    probe3_temp = COALESCE(excluded.probe3_temp, probe3_temp),
+    probe4_temp = COALESCE(excluded.probe4_temp, probe4_temp),
+    probe5_temp = COALESCE(excluded.probe5_temp, probe5_temp),
+    probe6_temp = COALESCE(excluded.probe6_temp, probe6_temp)`
+
+// shiftThermoworksData moves a Session's timestamps by a number of days. The driver stores each time with its zone
+// offset, so the local clock time before the offset is moved and the offset is added back to keep the zone
+const shiftThermoworksData = `UPDATE thermoworks_data
+SET timestamp = strftime('%Y-%m-%d %H:%M:%f', julianday(substr(timestamp, 1, length(timestamp) - 6)) + ?) || substr(timestamp, -6)
+WHERE session_id = ?`
+
+// shiftParkingDays is how far timestamps are moved before moving them to their final time. SQLite checks the unique
+// timestamp index after each row is updated, so shifting in place fails when a row moves onto the next one's time
+const shiftParkingDays = 100_000
+
+// Tx is a transaction with the generated queries and the batched inserts and updates that sqlc can't generate
+type Tx struct {
+	*db.Queries
+	tx *sql.Tx
+}
+
+// InTx runs fn in a transaction. It is committed if fn succeeds and rolled back if it returns an error, so nothing
+// is partially written
+func (c Client) InTx(ctx context.Context, fn func(Tx) error) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	err = fn(Tx{c.Queries.WithTx(tx), tx})
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("error rolling back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateThermoworksDataBatch inserts the rows using multi-row INSERTs of thermoworksDataBatchSize rows. It fails if a
+// row has the same timestamp as an existing one
+func (t Tx) CreateThermoworksDataBatch(ctx context.Context, rows []db.CreateThermoworksDataParams) error {
+	return t.insertThermoworksDataBatch(ctx, rows, "")
+}
+
+// UpsertThermoworksDataBatch is like CreateThermoworksDataBatch, but rows with the same timestamp as an existing one
+// are merged into it, even if they are in different zones. Readings that the new row doesn't have are kept
+func (t Tx) UpsertThermoworksDataBatch(ctx context.Context, rows []db.CreateThermoworksDataParams) error {
+	return t.insertThermoworksDataBatch(ctx, rows, upsertThermoworksData)
+}
+
+// ShiftThermoworksData moves all of the Session's data by the offset. Times are stored with millisecond precision
+// after they are moved
+func (t Tx) ShiftThermoworksData(ctx context.Context, sessionID string, offset time.Duration) error {
+	if offset == 0 {
+		return nil
+	}
+
+	days := offset.Hours() / 24
+	for _, shift := range []float64{days + shiftParkingDays, -shiftParkingDays} {
+		_, err := t.tx.ExecContext(ctx, shiftThermoworksData, shift, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertThermoworksDataBatch inserts the rows in batches with the conflict clause. The statement for a full batch is
+// prepared once and reused
+func (t Tx) insertThermoworksDataBatch(ctx context.Context, rows []db.CreateThermoworksDataParams, onConflict string) error {
+	var stmt *sql.Stmt
+	defer func() {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}()
+
+	for len(rows) > 0 {
+		n := min(len(rows), thermoworksDataBatchSize)
+
+		args := make([]any, 0, n*8)
+		for _, row := range rows[:n] {
+			args = append(args,
+				row.SessionID,
+				row.Timestamp,
+				row.Probe1Temp,
+				row.Probe2Temp,
+				row.Probe3Temp,
+				row.Probe4Temp,
+				row.Probe5Temp,
+				row.Probe6Temp,
+			)
+		}
+
+		var err error
+		if n == thermoworksDataBatchSize {
+			if stmt == nil {
+				stmt, err = t.tx.PrepareContext(ctx, insertThermoworksDataQuery(n, onConflict))
+				if err != nil {
+					return err
+				}
+			}
+			_, err = stmt.ExecContext(ctx, args...)
+		} else {
+			_, err = t.tx.ExecContext(ctx, insertThermoworksDataQuery(n, onConflict), args...)
+		}
+		if err != nil {
+			return err
+		}
+
+		rows = rows[n:]
+	}
+
+	return nil
+}
+
+// insertThermoworksDataQuery is an INSERT for the number of rows with the conflict clause
+func insertThermoworksDataQuery(rows int, onConflict string) string {
+	var b strings.Builder
+	b.WriteString(insertThermoworksData)
+	for i := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(?, ?, ?, ?, ?, ?, ?, ?)")
+	}
+	b.WriteString(onConflict)
+	return b.String()
+}
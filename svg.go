@@ -0,0 +1,73 @@
+package twchart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// svgCanvas writes shapes as SVG elements
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func newSVGCanvas(width, height int, background color.NRGBA) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n", width, height, width, height)
+	c.fillRect(vec{0, 0}, vec{float64(width), float64(height)}, background)
+	return c
+}
+
+func (c *svgCanvas) fillRect(min, max vec, col color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" %s/>`+"\n",
+		min.x, min.y, max.x-min.x, max.y-min.y, svgPaint("fill", col))
+}
+
+func (c *svgCanvas) polyline(points []vec, col color.NRGBA, width float64, dashed bool) {
+	if len(points) == 0 {
+		return
+	}
+
+	coords := make([]string, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", p.x, p.y))
+	}
+
+	dash := ""
+	if dashed {
+		dash = ` stroke-dasharray="4,4"`
+	}
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" %s stroke-width="%g" stroke-linejoin="round"%s/>`+"\n",
+		strings.Join(coords, " "), svgPaint("stroke", col), width, dash)
+}
+
+func (c *svgCanvas) text(p vec, s string, col color.NRGBA, size float64, anchor textAnchor) {
+	textAnchor := "start"
+	switch anchor {
+	case anchorMiddle:
+		textAnchor = "middle"
+	case anchorEnd:
+		textAnchor = "end"
+	}
+
+	fmt.Fprintf(&c.buf, `<text x="%.1f" y="%.1f" font-size="%g" text-anchor="%s" %s>`, p.x, p.y, size, textAnchor, svgPaint("fill", col))
+	_ = xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) WriteTo(w io.Writer) (int64, error) {
+	c.buf.WriteString("</svg>\n")
+	return c.buf.WriteTo(w)
+}
+
+// svgPaint sets the fill or stroke attribute and its opacity, since not all SVG renderers support rgba()
+func svgPaint(attr string, col color.NRGBA) string {
+	paint := fmt.Sprintf(`%s="rgb(%d,%d,%d)"`, attr, col.R, col.G, col.B)
+	if col.A != 255 {
+		paint += fmt.Sprintf(` %s-opacity="%.2f"`, attr, float64(col.A)/255)
+	}
+	return paint
+}
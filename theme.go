@@ -0,0 +1,160 @@
+package twchart
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// stageAlpha is the opacity of stage areas so the data behind them is still visible
+const stageAlpha = 0.4
+
+var (
+	lightStageColors = []string{
+		"rgba(144, 238, 144, 0.4)",
+		"rgba(255, 255, 102, 0.4)",
+		"rgba(173, 216, 230, 0.4)",
+		"rgba(255, 173, 177, 0.4)",
+	}
+	darkStageColors = []string{
+		"rgba(46, 139, 87, 0.4)",
+		"rgba(184, 134, 11, 0.4)",
+		"rgba(70, 130, 180, 0.4)",
+		"rgba(178, 34, 34, 0.4)",
+	}
+
+	lightProbeColors = []string{"#5470c6", "#91cc75", "#fac858", "#ee6666", "#73c0de", "#3ba272"}
+	darkProbeColors  = []string{"#4992ff", "#7cffb2", "#fddd60", "#ff6e76", "#58d9f9", "#05c091"}
+
+	// defaultStageColorsByName pins the colors of common stages for each SessionType so they match across Sessions
+	defaultStageColorsByName = map[SessionType]map[string]string{
+		SessionTypeBread: {
+			"bake": "rgba(255, 165, 0, 0.4)",
+		},
+		SessionTypeCoffee: {
+			"drying":      "rgba(255, 255, 102, 0.4)",
+			"maillard":    "rgba(210, 180, 140, 0.4)",
+			"development": "rgba(160, 82, 45, 0.4)",
+		},
+		SessionTypeBBQ: {
+			"rest": "rgba(173, 216, 230, 0.4)",
+		},
+	}
+)
+
+// Theme configures the colors used to chart a Session
+type Theme struct {
+	// Dark uses a dark background
+	Dark bool
+
+	// ProbeColors are used for each probe's series in order, and repeat if there are more probes
+	ProbeColors []string
+	// StageColors are used for the stages in order. If there are more stages, distinct colors are generated
+	StageColors []string
+	// StageColorsByName pins the color of stages with a name, ignoring case, so they are the same in every Session
+	StageColorsByName map[string]string
+	// StallColor is used to shade BBQ stalls
+	StallColor string
+}
+
+// Themes configure the Theme for each SessionType. The SessionTypeNone Theme applies to any type without its own
+type Themes map[SessionType]Theme
+
+// DefaultTheme is the light or dark Theme used when nothing is configured
+func DefaultTheme(dark bool) Theme {
+	if dark {
+		return Theme{Dark: true, ProbeColors: darkProbeColors, StageColors: darkStageColors, StallColor: stallColor}
+	}
+	return Theme{ProbeColors: lightProbeColors, StageColors: lightStageColors, StallColor: stallColor}
+}
+
+// Get returns the Theme for the SessionType. Anything that isn't configured uses the DefaultTheme, and configured
+// StageColorsByName are added to the type's default pinned colors
+func (t Themes) Get(sessionType SessionType, dark bool) Theme {
+	result := DefaultTheme(dark)
+	result.StageColorsByName = map[string]string{}
+	for name, color := range defaultStageColorsByName[sessionType] {
+		result.StageColorsByName[name] = color
+	}
+
+	configured, ok := t[sessionType]
+	if !ok {
+		configured = t[SessionTypeNone]
+	}
+
+	if len(configured.ProbeColors) > 0 {
+		result.ProbeColors = configured.ProbeColors
+	}
+	if len(configured.StageColors) > 0 {
+		result.StageColors = configured.StageColors
+	}
+	if configured.StallColor != "" {
+		result.StallColor = configured.StallColor
+	}
+	for name, color := range configured.StageColorsByName {
+		result.StageColorsByName[strings.ToLower(name)] = color
+	}
+
+	return result
+}
+
+// StageColor returns the color for the i-th stage. A color pinned to the stage's name is used first, then the
+// StageColors, then generated colors
+func (t Theme) StageColor(i int, name string) string {
+	for pinned, color := range t.StageColorsByName {
+		if strings.EqualFold(pinned, name) {
+			return color
+		}
+	}
+
+	if i < len(t.StageColors) {
+		return t.StageColors[i]
+	}
+	return generateColor(i-len(t.StageColors), t.Dark)
+}
+
+// ProbeColor returns the color for the i-th probe. It is empty if there are no ProbeColors so the chart's default is
+// used
+func (t Theme) ProbeColor(i int) string {
+	if len(t.ProbeColors) == 0 {
+		return ""
+	}
+	return t.ProbeColors[i%len(t.ProbeColors)]
+}
+
+// generateColor creates distinct colors by rotating the hue by the golden angle, which keeps neighboring colors far
+// apart no matter how many are needed
+func generateColor(i int, dark bool) string {
+	hue := math.Mod(float64(i)*137.508+30, 360)
+	lightness := 0.75
+	if dark {
+		lightness = 0.35
+	}
+
+	r, g, b := hslToRGB(hue, 0.7, lightness)
+	return fmt.Sprintf("rgba(%d, %d, %d, %.1f)", r, g, b, stageAlpha)
+}
+
+func hslToRGB(hue, saturation, lightness float64) (int, int, int) {
+	c := (1 - math.Abs(2*lightness-1)) * saturation
+	x := c * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := lightness - c/2
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = c, x, 0
+	case hue < 120:
+		r, g, b = x, c, 0
+	case hue < 180:
+		r, g, b = 0, c, x
+	case hue < 240:
+		r, g, b = 0, x, c
+	case hue < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return int(math.Round((r + m) * 255)), int(math.Round((g + m) * 255)), int(math.Round((b + m) * 255))
+}
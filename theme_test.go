@@ -0,0 +1,87 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThemeStageColor(t *testing.T) {
+	theme := Themes(nil).Get(SessionTypeBread, false)
+
+	t.Run("Palette", func(t *testing.T) {
+		assert.Equal(t, lightStageColors[0], theme.StageColor(0, "Preferment"))
+		assert.Equal(t, lightStageColors[3], theme.StageColor(3, "Proof"))
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		colors := map[string]struct{}{}
+		for i := range 20 {
+			colors[theme.StageColor(i, "Stage")] = struct{}{}
+		}
+		assert.Len(t, colors, 20, "all stage colors are distinct")
+		assert.Equal(t, "rgba(236, 191, 147, 0.4)", theme.StageColor(4, "Stage"))
+	})
+
+	t.Run("PinnedByName", func(t *testing.T) {
+		assert.Equal(t, "rgba(255, 165, 0, 0.4)", theme.StageColor(0, "Bake"))
+		assert.Equal(t, "rgba(255, 165, 0, 0.4)", theme.StageColor(7, "bake"))
+
+		// other types don't pin Bake
+		assert.Equal(t, lightStageColors[0], Themes(nil).Get(SessionTypeOther, false).StageColor(0, "Bake"))
+	})
+}
+
+func TestThemesGet(t *testing.T) {
+	themes := Themes{
+		SessionTypeNone: {StallColor: "gray"},
+		SessionTypeBread: {
+			StageColors:       []string{"red", "blue"},
+			StageColorsByName: map[string]string{"Proof": "purple"},
+		},
+	}
+
+	t.Run("Configured", func(t *testing.T) {
+		theme := themes.Get(SessionTypeBread, true)
+		assert.True(t, theme.Dark)
+		assert.Equal(t, darkProbeColors, theme.ProbeColors)
+		assert.Equal(t, stallColor, theme.StallColor)
+		assert.Equal(t, "red", theme.StageColor(0, "Mix"))
+		assert.Equal(t, "purple", theme.StageColor(1, "proof"))
+		assert.Equal(t, "rgba(255, 165, 0, 0.4)", theme.StageColor(2, "Bake"))
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		theme := themes.Get(SessionTypeCoffee, false)
+		assert.False(t, theme.Dark)
+		assert.Equal(t, lightStageColors, theme.StageColors)
+		assert.Equal(t, "gray", theme.StallColor)
+	})
+
+	t.Run("ProbeColorsRepeat", func(t *testing.T) {
+		theme := themes.Get(SessionTypeCoffee, false)
+		assert.Equal(t, theme.ProbeColors[0], theme.ProbeColor(len(theme.ProbeColors)))
+		assert.Empty(t, Theme{}.ProbeColor(0))
+	})
+}
+
+func TestChartManyStages(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 8, 0, 0, 0, time.Local)
+	s := Session{
+		Type:   SessionTypeBread,
+		Probes: []Probe{{Name: "Dough", Position: ProbePosition1}},
+		Data:   []ThermoworksData{{Time: start, ProbeData: []float64{75}}},
+	}
+	for i := range 6 {
+		Stage{Name: "Stage", Start: start.Add(time.Duration(i) * time.Hour)}.AddToSession(&s)
+	}
+
+	for _, dark := range []bool{false, true} {
+		theme := Themes(nil).Get(s.Type, dark)
+		chart, err := s.Chart(ChartOptions{Theme: &theme})
+		require.NoError(t, err)
+		assert.Len(t, chart.MultiSeries, 2)
+	}
+}
@@ -2,13 +2,14 @@ package twchart
 
 import (
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
+	"math"
 	"strconv"
 	"time"
-
-	"github.com/go-echarts/go-echarts/v2/opts"
 )
 
 const (
@@ -46,24 +47,80 @@ type ThermoworksData struct {
 	ProbeData []float64
 }
 
+// missingReading is used in ProbeData when a probe does not have a reading, like an empty CSV cell or a NULL in the
+// DB. Any other value, including zero and negative values, is a reading
+var missingReading = math.NaN()
+
 func (td ThermoworksData) GetProbeData(pos ProbePosition) float64 {
 	return td.ProbeData[pos-1]
 }
 
-func (td ThermoworksData) appendProbeData(lineData []opts.LineData, pos ProbePosition) []opts.LineData {
-	if pos == ProbePositionNone {
-		return lineData
+// HasProbeData returns true if there is a valid reading for the probe
+func (td ThermoworksData) HasProbeData(pos ProbePosition) bool {
+	return pos != ProbePositionNone && td.hasReading(int(pos-1))
+}
+
+// hasReading is the same as HasProbeData, but uses the index into ProbeData
+func (td ThermoworksData) hasReading(i int) bool {
+	return i < len(td.ProbeData) && !math.IsNaN(td.ProbeData[i])
+}
+
+// thermoworksDataJSON is how ThermoworksData is encoded in JSON. Missing readings are null since JSON doesn't have NaN
+type thermoworksDataJSON struct {
+	Time      time.Time
+	ProbeData []*float64
+}
+
+func (td ThermoworksData) MarshalJSON() ([]byte, error) {
+	out := thermoworksDataJSON{Time: td.Time}
+	if td.ProbeData != nil {
+		out.ProbeData = make([]*float64, len(td.ProbeData))
 	}
-	probeData := td.GetProbeData(pos)
-	if probeData <= 0 {
-		return append(lineData, opts.LineData{
-			Value: []any{td.Time.Format(time.RFC3339), nil},
-		})
+	for i := range td.ProbeData {
+		if td.hasReading(i) {
+			out.ProbeData[i] = &td.ProbeData[i]
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (td *ThermoworksData) UnmarshalJSON(input []byte) error {
+	var in thermoworksDataJSON
+	err := json.Unmarshal(input, &in)
+	if err != nil {
+		return err
 	}
 
-	return append(lineData, opts.LineData{
-		Value: []any{td.Time.Format(time.RFC3339), probeData},
-	})
+	td.Time = in.Time
+	td.ProbeData = nil
+	if in.ProbeData != nil {
+		td.ProbeData = make([]float64, len(in.ProbeData))
+	}
+	for i, v := range in.ProbeData {
+		td.ProbeData[i] = missingReading
+		if v != nil {
+			td.ProbeData[i] = *v
+		}
+	}
+	return nil
+}
+
+// ErrDuplicateTimestamp is reported for rows that repeat the previous row's timestamp. These rows are dropped
+// rather than treated as errors
+var ErrDuplicateTimestamp = errors.New("duplicate timestamp")
+
+// RowError describes a CSV row that could not be loaded
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
 }
 
 func iterCSV(reader *csv.Reader) (iter.Seq2[ThermoworksData, error], error) {
@@ -87,19 +144,26 @@ func iterCSV(reader *csv.Reader) (iter.Seq2[ThermoworksData, error], error) {
 				return
 			}
 			if err != nil {
-				if !yield(ThermoworksData{}, err) {
+				line, _ := reader.FieldPos(0)
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					line = parseErr.Line
+				}
+				if !yield(ThermoworksData{}, &RowError{line, err}) {
 					return
 				}
 				continue
 			}
 
+			line, _ := reader.FieldPos(0)
+
 			if len(record) < 1 {
 				continue
 			}
 
 			dt, err := time.ParseInLocation(time.DateTime, record[0], time.Local)
 			if err != nil {
-				if !yield(ThermoworksData{}, err) {
+				if !yield(ThermoworksData{}, &RowError{line, err}) {
 					return
 				}
 				continue
@@ -108,26 +172,34 @@ func iterCSV(reader *csv.Reader) (iter.Seq2[ThermoworksData, error], error) {
 
 			// When using the Fan on Thermoworks Bellows, there will be multiple rows with the same timestamp
 			if prev.Equal(dt) {
+				if !yield(ThermoworksData{}, &RowError{line, ErrDuplicateTimestamp}) {
+					return
+				}
 				continue
 			}
-			prev = dt
 
 			probes := make([]float64, len(headers)-1)
+			var valueErr error
 			for i := 1; i < len(headers); i++ {
 				if record[i] == "" {
-					probes[i-1] = -1 // or math.NaN() if you prefer
+					probes[i-1] = missingReading
 					continue
 				}
 
 				val, err := strconv.ParseFloat(record[i], 64)
 				if err != nil {
-					if !yield(ThermoworksData{}, err) {
-						return
-					}
-					continue
+					valueErr = fmt.Errorf("invalid value for %q: %w", headers[i], err)
+					break
 				}
 				probes[i-1] = val
 			}
+			if valueErr != nil {
+				if !yield(ThermoworksData{}, &RowError{line, valueErr}) {
+					return
+				}
+				continue
+			}
+			prev = dt
 
 			data := ThermoworksData{
 				Time: dt, ProbeData: probes,
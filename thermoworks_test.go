@@ -0,0 +1,91 @@
+package twchart
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertDataEqual compares the data as JSON since missing readings are NaN, which is never equal to itself
+func assertDataEqual(t *testing.T, expected, actual []ThermoworksData) {
+	t.Helper()
+
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
+
+func TestThermoworksDataJSON(t *testing.T) {
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.UTC)
+	data := ThermoworksData{Time: start, ProbeData: []float64{225, missingReading, 0, -5}}
+
+	out, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Time": "2025-05-24T20:00:00Z", "ProbeData": [225, null, 0, -5]}`, string(out))
+
+	var decoded ThermoworksData
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.True(t, decoded.HasProbeData(ProbePosition1))
+	assert.False(t, decoded.HasProbeData(ProbePosition2))
+	assert.True(t, decoded.HasProbeData(ProbePosition3))
+	assert.Equal(t, -5.0, decoded.GetProbeData(ProbePosition4))
+}
+
+func TestSessionUnmarshalJSON(t *testing.T) {
+	// 0 and below are missing for temperature probes in data stored before missing readings were null
+	var s Session
+	err := json.Unmarshal([]byte(`{
+		"Probes": [
+			{"Name": "Pit", "Position": 1},
+			{"Name": "Fan", "Position": 2, "Measurement": {"Kind": "fan"}}
+		],
+		"Data": [
+			{"Time": "2025-05-24T20:00:00Z", "ProbeData": [225, 0]},
+			{"Time": "2025-05-24T20:00:01Z", "ProbeData": [-1, 40]},
+			{"Time": "2025-05-24T20:00:02Z", "ProbeData": [0, null]}
+		]
+	}`), &s)
+	require.NoError(t, err)
+
+	start := time.Date(2025, time.May, 24, 20, 0, 0, 0, time.UTC)
+	assertDataEqual(t, []ThermoworksData{
+		{Time: start, ProbeData: []float64{225, 0}},
+		{Time: start.Add(time.Second), ProbeData: []float64{missingReading, 40}},
+		{Time: start.Add(2 * time.Second), ProbeData: []float64{missingReading, missingReading}},
+	}, s.Data)
+}
+
+func TestLoadDataFanAtZero(t *testing.T) {
+	s := Session{Probes: []Probe{
+		{Name: "Pit", Position: ProbePosition1},
+		{Name: "Fan", Position: ProbePosition2, Measurement: Measurement{Kind: MeasurementFan}},
+	}}
+
+	report, err := s.LoadData(strings.NewReader(`DateTime,Probe 1,Probe 2
+2025-05-24 20:00:00,225,0
+2025-05-24 20:01:00,226,
+2025-05-24 20:02:00,227,0
+2025-05-24 20:03:00,228,35
+`), ImportOptions{})
+	require.NoError(t, err)
+
+	// the empty cell is the only missing reading
+	assert.Equal(t, 3, report.Probes[1].Samples)
+	assert.Equal(t, []Point{
+		{Time: s.Data[0].Time, Value: 0},
+		{Time: s.Data[1].Time, Missing: true},
+		{Time: s.Data[2].Time, Value: 0},
+		{Time: s.Data[3].Time, Value: 35},
+	}, ProbeSeries(s.Data, ProbePosition2))
+
+	stats, ok := CalculateProbeStats(s.Data, s.Probes[1], time.Time{}, time.Time{})
+	require.True(t, ok)
+	assert.Equal(t, 3, stats.Samples)
+	assert.Equal(t, 0.0, stats.Min)
+}
@@ -0,0 +1,247 @@
+package twchart
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// TimelineOptions configures a timeline chart
+type TimelineOptions struct {
+	// Themes set the Stage colors for each Session's type
+	Themes Themes
+	// Dark uses the dark variant of the Themes
+	Dark bool
+	// LastReadings has the time of the last reading for Sessions, by ID, that don't have their Data loaded so their
+	// unfinished Stages still end at the last reading
+	LastReadings map[string]time.Time
+}
+
+// timelineRow is a row of the timeline with a bar for each Stage and a tick for each Event
+type timelineRow struct {
+	Name   string
+	Bars   []timelineBar
+	Events []Event
+}
+
+type timelineBar struct {
+	Label string
+	Start time.Time
+	End   time.Time
+	Color string
+}
+
+// stageBars creates a bar for each of the Session's Stages. Unfinished Stages end at the Session's last Event or
+// reading
+func (s Session) stageBars(theme Theme, lastReading time.Time) []timelineBar {
+	bars := make([]timelineBar, 0, len(s.Stages))
+	for i, stage := range s.Stages {
+		end := stage.End
+		if end.IsZero() {
+			end = s.lastTime(stage.Start, lastReading)
+		}
+
+		bars = append(bars, timelineBar{
+			Label: fmt.Sprintf("%s (%s)", stage.Name, end.Sub(stage.Start)),
+			Start: stage.Start,
+			End:   end,
+			Color: theme.StageColor(i, stage.Name),
+		})
+	}
+	return bars
+}
+
+// lastTime is the time of the Session's last Event or reading, or the given time if it is later. lastReading is used
+// when the Session's Data isn't loaded
+func (s Session) lastTime(t, lastReading time.Time) time.Time {
+	for _, e := range s.Events {
+		if e.Time.After(t) {
+			t = e.Time
+		}
+	}
+	if len(s.Data) > 0 {
+		lastReading = s.Data[len(s.Data)-1].Time
+	}
+	if lastReading.After(t) {
+		t = lastReading
+	}
+	return t
+}
+
+// Timeline charts the Session's Stages as a Gantt chart with a row for each Stage. Events are shown as ticks on the
+// row of the Stage they happened during
+func (s Session) Timeline(timelineOpts TimelineOptions) *charts.Custom {
+	theme := timelineOpts.Themes.Get(s.Type, timelineOpts.Dark)
+	bars := s.stageBars(theme, timelineOpts.LastReadings[s.ID.String()])
+	if len(bars) == 0 {
+		return timelineChart([]timelineRow{{Name: s.Name, Events: s.Events}}, theme)
+	}
+
+	rows := make([]timelineRow, len(bars))
+	for i, bar := range bars {
+		rows[i] = timelineRow{Name: s.Stages[i].Name, Bars: []timelineBar{bar}}
+	}
+
+	// Events before the first Stage are on its row and Events after the last Stage are on its row
+	for _, e := range s.Events {
+		row := 0
+		for i, stage := range s.Stages {
+			if !e.Time.Before(stage.Start) {
+				row = i
+			}
+		}
+		rows[row].Events = append(rows[row].Events, e)
+	}
+
+	return timelineChart(rows, theme)
+}
+
+// SessionsTimeline charts the Stages of multiple Sessions with a row for each Session, so Sessions that overlap can
+// be seen together. The Sessions are sorted by their start time
+func SessionsTimeline(sessions []Session, timelineOpts TimelineOptions) *charts.Custom {
+	sessions = slices.Clone(sessions)
+	slices.SortStableFunc(sessions, func(a, b Session) int {
+		return a.timelineStart().Compare(b.timelineStart())
+	})
+
+	rows := make([]timelineRow, 0, len(sessions))
+	for _, s := range sessions {
+		theme := timelineOpts.Themes.Get(s.Type, timelineOpts.Dark)
+		rows = append(rows, timelineRow{Name: s.Name, Bars: s.stageBars(theme, timelineOpts.LastReadings[s.ID.String()]), Events: s.Events})
+	}
+
+	return timelineChart(rows, timelineOpts.Themes.Get(SessionTypeNone, timelineOpts.Dark))
+}
+
+// timelineStart is the time of the Session's first Stage or Event, or its Date if it has neither
+func (s Session) timelineStart() time.Time {
+	start := s.Date
+	if len(s.Stages) > 0 {
+		start = s.Stages[0].Start
+	}
+	if len(s.Events) > 0 && (len(s.Stages) == 0 || s.Events[0].Time.Before(start)) {
+		start = s.Events[0].Time
+	}
+	return start
+}
+
+// timelineStagesRenderItem draws each Stage as a bar from its start to its end, labeled with its name and duration.
+// Custom series can't read the names of their data, so the labels are added to the function as arrays of code points,
+// which can't break out of the function like strings could
+const timelineStagesRenderItem = `function (params, api) {
+	const labels = %s.map(codes => String.fromCodePoint(...codes));
+	const start = api.coord([api.value(0), api.value(1)]);
+	const end = api.coord([api.value(2), api.value(1)]);
+	const height = api.size([0, 1])[1] * 0.6;
+	const rect = echarts.graphic.clipRectByRect(
+		{x: start[0], y: start[1] - height / 2, width: Math.max(end[0] - start[0], 1), height: height},
+		{x: params.coordSys.x, y: params.coordSys.y, width: params.coordSys.width, height: params.coordSys.height}
+	);
+	return rect && {
+		type: 'rect',
+		shape: rect,
+		style: api.style(),
+		textContent: {style: {text: labels[params.dataIndex], fill: '%s', width: rect.width, overflow: 'truncate'}},
+		textConfig: {position: 'inside'}
+	};
+}`
+
+// timelineEventsRenderItem draws each Event as a vertical tick on its row
+const timelineEventsRenderItem = `function (params, api) {
+	const point = api.coord([api.value(0), api.value(1)]);
+	const height = api.size([0, 1])[1] * 0.8;
+	return {
+		type: 'line',
+		shape: {x1: point[0], y1: point[1] - height / 2, x2: point[0], y2: point[1] + height / 2},
+		style: {stroke: api.visual('color'), lineWidth: 2}
+	};
+}`
+
+// timelineTooltipFormatter shows the Stage's or Event's label
+const timelineTooltipFormatter = `function (params) {
+	return params.marker + params.name;
+}`
+
+func timelineChart(rows []timelineRow, theme Theme) *charts.Custom {
+	echartsTheme := "white"
+	textColor := "#333"
+	if theme.Dark {
+		echartsTheme = "dark"
+		textColor = "#eee"
+	}
+
+	names := make([]string, 0, len(rows))
+	stages := []opts.CustomData{}
+	labels := [][]rune{}
+	events := []opts.CustomData{}
+	for i, row := range rows {
+		names = append(names, row.Name)
+		for _, bar := range row.Bars {
+			stages = append(stages, opts.CustomData{
+				Name:      bar.Label,
+				Value:     []any{bar.Start.UnixMilli(), i, bar.End.UnixMilli()},
+				ItemStyle: &opts.ItemStyle{Color: bar.Color},
+			})
+			labels = append(labels, []rune(bar.Label))
+		}
+		for _, e := range row.Events {
+			events = append(events, opts.CustomData{
+				Name:  fmt.Sprintf("%s: %s", e.Time.Format(time.Kitchen), e.Note),
+				Value: []any{e.Time.UnixMilli(), i},
+			})
+		}
+	}
+
+	// the labels are numbers, so they can't fail to encode
+	labelsJSON, _ := json.Marshal(labels)
+
+	// leave room for each row's bars and the axes
+	height := max(200, 60*len(rows)+100)
+
+	chart := charts.NewCustom()
+	chart.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "100%",
+			Height: fmt.Sprintf("%dpx", height),
+			Theme:  echartsTheme,
+		}),
+		charts.WithXAxisOpts(opts.XAxis{Type: "time"}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Type: "category",
+			Data: names,
+			// the first row is at the top
+			Inverse: opts.Bool(true),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "item",
+			Formatter: opts.FuncOpts(timelineTooltipFormatter),
+		}),
+		charts.WithDataZoomOpts(opts.DataZoom{
+			Type:   "slider",
+			Start:  0,
+			End:    100,
+			Orient: "horizontal",
+		}),
+	)
+
+	chart.AddSeries("Stages", stages,
+		charts.WithCustomChartOpts(opts.CustomChart{
+			RenderItem: opts.FuncOpts(fmt.Sprintf(timelineStagesRenderItem, labelsJSON, textColor)),
+		}),
+		charts.WithEncodeOpts(opts.Encode{X: []int{0, 2}, Y: 1}),
+	)
+	chart.AddSeries("Events", events,
+		charts.WithCustomChartOpts(opts.CustomChart{
+			RenderItem: opts.FuncOpts(timelineEventsRenderItem),
+		}),
+		charts.WithEncodeOpts(opts.Encode{X: 0, Y: 1}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: theme.ProbeColor(1)}),
+	)
+
+	return chart
+}
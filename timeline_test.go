@@ -0,0 +1,87 @@
+package twchart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2025, time.May, 21, 8, 0, 0, 0, time.UTC)
+	s := compareSession("Roast", start, 4*time.Minute)
+	s.Events = []Event{
+		{Note: "Charge", Time: start.Add(-time.Minute)},
+		{Note: "Yellow", Time: start.Add(2 * time.Minute)},
+		{Note: `First "crack"`, Time: start.Add(5 * time.Minute)},
+	}
+
+	t.Run("Session", func(t *testing.T) {
+		chart := s.Timeline(TimelineOptions{})
+		require.Len(t, chart.MultiSeries, 2)
+		assert.Equal(t, []string{"Drying", "Development"}, chart.YAxisList[0].Data)
+
+		stages := chart.MultiSeries[0].Data.([]opts.CustomData)
+		require.Len(t, stages, 2)
+		assert.Equal(t, "Drying (4m0s)", stages[0].Name)
+		// the unfinished Stage ends at the last reading
+		assert.Equal(t, "Development (6m0s)", stages[1].Name)
+		assert.Equal(t, []any{start.Add(4 * time.Minute).UnixMilli(), 1, start.Add(10 * time.Minute).UnixMilli()}, stages[1].Value)
+
+		events := chart.MultiSeries[1].Data.([]opts.CustomData)
+		require.Len(t, events, 3)
+		assert.Equal(t, []any{start.Add(-time.Minute).UnixMilli(), 0}, events[0].Value)
+		assert.Equal(t, []any{start.Add(2 * time.Minute).UnixMilli(), 0}, events[1].Value)
+		assert.Equal(t, []any{start.Add(5 * time.Minute).UnixMilli(), 1}, events[2].Value)
+		assert.Equal(t, `8:05AM: First "crack"`, events[2].Name)
+	})
+
+	t.Run("NoStages", func(t *testing.T) {
+		s := s
+		s.Stages = nil
+
+		chart := s.Timeline(TimelineOptions{})
+		assert.Equal(t, []string{"Roast"}, chart.YAxisList[0].Data)
+		assert.Empty(t, chart.MultiSeries[0].Data)
+		assert.Len(t, chart.MultiSeries[1].Data, 3)
+	})
+
+	t.Run("LastReadings", func(t *testing.T) {
+		// Sessions from the DB don't have their Data, so the last reading is from the options
+		s := s
+		s.ID = babyapi.NewID()
+		s.Data = nil
+
+		chart := s.Timeline(TimelineOptions{LastReadings: map[string]time.Time{s.ID.String(): start.Add(12 * time.Minute)}})
+		stages := chart.MultiSeries[0].Data.([]opts.CustomData)
+		require.Len(t, stages, 2)
+		assert.Equal(t, "Development (8m0s)", stages[1].Name)
+
+		// without it, the Stage ends at the last Event
+		chart = s.Timeline(TimelineOptions{})
+		stages = chart.MultiSeries[0].Data.([]opts.CustomData)
+		assert.Equal(t, "Development (1m0s)", stages[1].Name)
+	})
+
+	t.Run("Sessions", func(t *testing.T) {
+		later := compareSession(`Second "Roast"`, start.Add(time.Hour), 5*time.Minute)
+		later.Events = nil
+
+		chart := SessionsTimeline([]Session{later, s}, TimelineOptions{})
+		assert.Equal(t, []string{"Roast", `Second "Roast"`}, chart.YAxisList[0].Data)
+
+		stages := chart.MultiSeries[0].Data.([]opts.CustomData)
+		require.Len(t, stages, 4)
+		assert.Equal(t, 1, stages[2].Value.([]any)[1])
+		assert.Len(t, chart.MultiSeries[1].Data, 3)
+
+		// labels are added to the JavaScript without quotes so they can't break it
+		for _, series := range chart.MultiSeries {
+			assert.NotContains(t, string(series.RenderItem), `"`)
+		}
+		assert.NotContains(t, string(chart.Tooltip.Formatter), `"`)
+	})
+}